@@ -53,6 +53,7 @@ func (h *Handler) alrRequest(w http.ResponseWriter, r *http.Request) {
 	newJob := models.Job{
 		ACOID:           uuid.Parse(ad.ACOID),
 		RequestURL:      requestURL,
+		RequestMethod:   r.Method,
 		Status:          models.JobStatusPending,
 		TransactionTime: time.Now(),
 	}
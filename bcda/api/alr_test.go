@@ -10,7 +10,7 @@ import (
 	"github.com/CMSgov/bcda-app/bcda/auth"
 	"github.com/CMSgov/bcda-app/bcda/database"
 	"github.com/CMSgov/bcda-app/bcda/models/postgres/postgrestest"
-	"github.com/CMSgov/bcda-app/bcda/service"
+	"github.com/CMSgov/bcda-app/bcda/resourcetypes"
 	"github.com/CMSgov/bcda-app/bcda/web/middleware"
 	mockEnq "github.com/CMSgov/bcda-app/bcdaworker/queueing/mocks"
 	"github.com/pborman/uuid"
@@ -41,7 +41,7 @@ func (s *AlrTestSuite) TestAlrRequest() {
 	enqueuer := mockEnq.NewEnqueuer(s.T())
 	enqueuer.On("AddAlrJob", mock.Anything, mock.Anything).Return(nil)
 
-	resourceMap := map[string]service.DataType{
+	resourceMap := map[string]resourcetypes.DataType{
 		"Patient":     {Adjudicated: true},
 		"Observation": {Adjudicated: true},
 	}
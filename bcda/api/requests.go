@@ -15,6 +15,7 @@ import (
 	"github.com/pkg/errors"
 
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/pborman/uuid"
@@ -25,6 +26,7 @@ import (
 	"github.com/CMSgov/bcda-app/bcda/database"
 	"github.com/CMSgov/bcda-app/bcda/models"
 	"github.com/CMSgov/bcda-app/bcda/models/postgres"
+	"github.com/CMSgov/bcda-app/bcda/resourcetypes"
 	responseutils "github.com/CMSgov/bcda-app/bcda/responseutils"
 	responseutilsv2 "github.com/CMSgov/bcda-app/bcda/responseutils/v2"
 	"github.com/CMSgov/bcda-app/bcda/service"
@@ -50,7 +52,7 @@ type Handler struct {
 	r  models.Repository
 	db *sql.DB
 
-	supportedDataTypes map[string]service.DataType
+	supportedDataTypes map[string]resourcetypes.DataType
 
 	supportedResourceTypes []string
 
@@ -69,11 +71,11 @@ type fhirResponseWriter interface {
 	JobsBundle(context.Context, http.ResponseWriter, []*models.Job, string)
 }
 
-func NewHandler(dataTypes map[string]service.DataType, basePath string, apiVersion string) *Handler {
+func NewHandler(dataTypes map[string]resourcetypes.DataType, basePath string, apiVersion string) *Handler {
 	return newHandler(dataTypes, basePath, apiVersion, database.Connection)
 }
 
-func newHandler(dataTypes map[string]service.DataType, basePath string, apiVersion string, db *sql.DB) *Handler {
+func newHandler(dataTypes map[string]resourcetypes.DataType, basePath string, apiVersion string, db *sql.DB) *Handler {
 	h := &Handler{JobTimeout: time.Hour * time.Duration(utils.GetEnvInt("ARCHIVE_THRESHOLD_HR", 24))}
 
 	h.Enq = queueing.NewEnqueuer()
@@ -125,12 +127,12 @@ func (h *Handler) ALRRequest(w http.ResponseWriter, r *http.Request) {
 	h.alrRequest(w, r)
 }
 
-func (h *Handler) BulkGroupRequest(w http.ResponseWriter, r *http.Request) {
-	const (
-		groupAll    = "all"
-		groupRunout = "runout"
-	)
+const (
+	groupAll    = "all"
+	groupRunout = "runout"
+)
 
+func (h *Handler) BulkGroupRequest(w http.ResponseWriter, r *http.Request) {
 	reqType := service.DefaultRequest
 	groupID := chi.URLParam(r, "groupId")
 	switch groupID {
@@ -266,7 +268,27 @@ func (h *Handler) JobStatus(w http.ResponseWriter, r *http.Request) {
 			return *jobKey.QueJobID
 		})
 		w.Header().Set("X-Progress", job.StatusMessage(completedJobKeyCount))
+
+		progress, err := h.Svc.GetJobProgress(r.Context(), job.ID)
+		if err != nil {
+			// The progress breakdown is informational, so the status is still reported without it
+			logger.Error(errors.Wrap(err, "failed to get job progress"))
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		jsonData, err := json.Marshal(newJobProgressBody(progress))
+		if err != nil {
+			logger.Error(err)
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		w.Header().Set("Content-Type", constants.FHIRJsonContentType)
 		w.WriteHeader(http.StatusAccepted)
+		if _, err = w.Write(jsonData); err != nil {
+			logger.Error(err)
+		}
 		return
 	case models.JobStatusCompleted:
 		// If the job should be expired, but the cleanup job hasn't run for some reason, still respond with 410
@@ -294,8 +316,11 @@ func (h *Handler) JobStatus(w http.ResponseWriter, r *http.Request) {
 		for _, jobKey := range jobKeys {
 			// data files
 			fi := FileItem{
-				Type: jobKey.ResourceType,
-				URL:  fmt.Sprintf("%s://%s/data/%d/%s", scheme, r.Host, jobID, strings.TrimSpace(jobKey.FileName)),
+				Type:        jobKey.ResourceType,
+				URL:         fmt.Sprintf("%s://%s/data/%d/%s", scheme, r.Host, jobID, strings.TrimSpace(jobKey.FileName)),
+				ContentType: DataFileContentType(jobKey.FileName),
+				Count:       jobKey.ResourceCount,
+				Extension:   fileExtensions(jobKey),
 			}
 
 			// Check if "error" is not in the filename
@@ -370,6 +395,97 @@ func (h *Handler) DeleteJob(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusAccepted)
 }
 
+// RetryJob creates a job that exports the beneficiaries that could not be exported by a completed job.
+// The retry job reuses the transaction time, _since and claims window of the completed job.
+func (h *Handler) RetryJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.GetCtxLogger(ctx)
+	jobIDStr := chi.URLParam(r, "jobID")
+
+	jobID, err := strconv.ParseUint(jobIDStr, 10, 64)
+	if err != nil {
+		err = errors.Wrap(err, "cannot convert jobID to uint")
+		logger.Error(err)
+		h.RespWriter.Exception(ctx, w, http.StatusBadRequest, responseutils.RequestErr, err.Error())
+		return
+	}
+
+	retryJob, queJobs, err := h.Svc.NewRetryJob(ctx, uint(jobID))
+	if err != nil {
+		if goerrors.Is(err, service.ErrJobNotRetryable) || goerrors.Is(err, service.ErrNoFailedBeneficiaries) ||
+			goerrors.Is(err, service.ErrRetryInProgress) {
+			logger.Info(errors.Wrap(err, "Job is not retryable"))
+			h.RespWriter.Exception(ctx, w, http.StatusBadRequest, responseutils.RequestErr, err.Error())
+			return
+		}
+		logger.Error(err)
+		h.RespWriter.Exception(ctx, w, http.StatusInternalServerError, responseutils.DbErr, "")
+		return
+	}
+
+	scheme := "http"
+	if servicemux.IsHTTPS(r) {
+		scheme = "https"
+	}
+
+	// Create the retry job in a transaction so that it is only committed once all of its queue jobs have been enqueued
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		err = fmt.Errorf("failed to start transaction: %w", err)
+		logger.Error(err)
+		h.RespWriter.Exception(ctx, w, http.StatusInternalServerError, responseutils.InternalErr, "")
+		return
+	}
+	rtx := postgres.NewRepositoryTx(tx)
+
+	defer func() {
+		if err != nil {
+			if err1 := tx.Rollback(); err1 != nil {
+				logger.Warnf("Failed to rollback transaction %s", err.Error())
+			}
+			// We've already written out the HTTP response so we can return after we've rolled back the transaction
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			logger.Error(err.Error())
+			h.RespWriter.Exception(ctx, w, http.StatusInternalServerError, responseutils.DbErr, "")
+			return
+		}
+
+		w.Header().Set("Content-Location", fmt.Sprintf("%s://%s/api/%s/jobs/%d", scheme, r.Host, h.apiVersion, retryJob.ID))
+		w.WriteHeader(http.StatusAccepted)
+	}()
+
+	// The unique index on retry_of prevents concurrent requests from creating more than one pending retry job
+	retryJob.ID, err = rtx.CreateJob(ctx, *retryJob)
+	if err != nil {
+		logger.Error(err)
+		h.RespWriter.Exception(ctx, w, http.StatusInternalServerError, responseutils.DbErr, "")
+		return
+	}
+
+	ctx, logger = log.SetCtxLogger(ctx, "job_id", retryJob.ID)
+	logger.Infof("retry job created for job %d", jobID)
+
+	id, err := safecast.ToInt(retryJob.ID)
+	if err != nil {
+		logger.Error(err)
+		h.RespWriter.Exception(ctx, w, http.StatusInternalServerError, responseutils.InternalErr, "")
+		return
+	}
+
+	for _, j := range queJobs {
+		j.ID = id
+		jobPriority := h.Svc.GetJobPriority(j.CMSID, j.ResourceType, j.Since != "")
+		if err = h.Enq.AddJob(ctx, *j, int(jobPriority)); err != nil {
+			logger.Error(err)
+			h.RespWriter.Exception(ctx, w, http.StatusInternalServerError, responseutils.InternalErr, "")
+			return
+		}
+	}
+}
+
 type AttributionFileStatus struct {
 	Timestamp time.Time `json:"timestamp"`
 	Type      string    `json:"type"`
@@ -460,6 +576,111 @@ func (h *Handler) getAttributionFileStatus(ctx context.Context, CMSID string, fi
 	return status, nil
 }
 
+// AttributionDelta writes a FHIR Group describing how the ACO's latest attribution file changed its attributed beneficiaries
+func (h *Handler) AttributionDelta(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.GetCtxLogger(ctx)
+
+	ad, err := GetAuthDataFromCtx(r)
+	if err != nil {
+		logger.Error(err)
+		h.RespWriter.Exception(ctx, w, http.StatusUnauthorized, responseutils.TokenErr, "")
+		return
+	}
+
+	cclfFile, delta, err := h.Svc.GetAttributionDelta(ctx, ad.CMSID, models.FileTypeDefault)
+	if err != nil {
+		if goerrors.As(err, &service.CCLFNotFoundError{}) || goerrors.Is(err, service.ErrAttributionDeltaNotFound) {
+			logger.Info(errors.Wrap(err, "Attribution delta not found"))
+			h.RespWriter.NotFound(ctx, w, http.StatusNotFound, responseutils.NotFoundErr, err.Error())
+			return
+		}
+		logger.Error(err)
+		h.RespWriter.Exception(ctx, w, http.StatusInternalServerError, responseutils.DbErr, "")
+		return
+	}
+
+	w.Header().Set(constants.ContentType, constants.FHIRJsonContentType)
+	if err = json.NewEncoder(w).Encode(newAttributionDeltaGroup(cclfFile, delta)); err != nil {
+		logger.Error(errors.Wrap(err, "Failed to encode JSON response"))
+		h.RespWriter.Exception(ctx, w, http.StatusInternalServerError, responseutils.InternalErr, "")
+	}
+}
+
+// Group writes a FHIR Group describing the beneficiaries attributed to the ACO by its latest attribution file for the
+// group identifier. Members are only included when the _count parameter is supplied, starting at the _offset parameter.
+func (h *Handler) Group(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.GetCtxLogger(ctx)
+
+	ad, err := GetAuthDataFromCtx(r)
+	if err != nil {
+		logger.Error(err)
+		h.RespWriter.Exception(ctx, w, http.StatusUnauthorized, responseutils.TokenErr, "")
+		return
+	}
+
+	var fileType models.CCLFFileType
+	groupID := chi.URLParam(r, "groupId")
+	switch groupID {
+	case groupAll:
+		fileType = models.FileTypeDefault
+	case groupRunout:
+		if utils.GetEnvBool("BCDA_ENABLE_RUNOUT", true) {
+			fileType = models.FileTypeRunout
+			break
+		}
+		fallthrough
+	default:
+		h.RespWriter.Exception(ctx, w, http.StatusBadRequest, responseutils.RequestErr, "Invalid group ID")
+		return
+	}
+
+	count, offset, err := parseGroupPaging(r.URL.Query())
+	if err != nil {
+		logger.Error(err)
+		h.RespWriter.Exception(ctx, w, http.StatusBadRequest, responseutils.RequestErr, err.Error())
+		return
+	}
+
+	if cfg, ok := h.Svc.GetACOConfigForID(ad.CMSID); ok {
+		ctx = service.NewACOCfgCtx(ctx, cfg)
+	}
+
+	cclfFile, benes, total, err := h.Svc.GetGroupMembers(ctx, ad.CMSID, fileType, offset, count)
+	if err != nil {
+		if goerrors.As(err, &service.CCLFNotFoundError{}) {
+			logger.Info(errors.Wrap(err, "Group not found"))
+			h.RespWriter.NotFound(ctx, w, http.StatusNotFound, responseutils.NotFoundErr, fmt.Sprintf("No attribution information is available for Group '%s'.", groupID))
+			return
+		}
+		logger.Error(err)
+		h.RespWriter.Exception(ctx, w, http.StatusInternalServerError, responseutils.DbErr, "")
+		return
+	}
+
+	w.Header().Set(constants.ContentType, constants.FHIRJsonContentType)
+	if err = json.NewEncoder(w).Encode(newAttributionGroup(groupID, ad.CMSID, cclfFile, benes, total)); err != nil {
+		logger.Error(errors.Wrap(err, "Failed to encode JSON response"))
+		h.RespWriter.Exception(ctx, w, http.StatusInternalServerError, responseutils.InternalErr, "")
+	}
+}
+
+// parseGroupPaging returns the number of Group members requested and the number of members to skip
+func parseGroupPaging(query url.Values) (count, offset int, err error) {
+	if v := query.Get("_count"); v != "" {
+		if count, err = strconv.Atoi(v); err != nil || count < 0 || count > maxGroupMemberCount {
+			return 0, 0, fmt.Errorf("_count must be an integer between 0 and %d", maxGroupMemberCount)
+		}
+	}
+	if v := query.Get("_offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			return 0, 0, errors.New("_offset must be a non-negative integer")
+		}
+	}
+	return count, offset, nil
+}
+
 func (h *Handler) bulkRequest(w http.ResponseWriter, r *http.Request, reqType service.RequestType) {
 	// Create context to encapsulate the entire workflow. In the future, we can define child context's for timing.
 	ctx, cancel := context.WithCancel(r.Context())
@@ -495,6 +716,12 @@ func (h *Handler) bulkRequest(w http.ResponseWriter, r *http.Request, reqType se
 		return
 	}
 
+	if err = validateTypeFilter(rp.TypeFilter, resourceTypes); err != nil {
+		logger.Error(err)
+		h.RespWriter.Exception(r.Context(), w, http.StatusBadRequest, responseutils.RequestErr, err.Error())
+		return
+	}
+
 	bb, err := client.NewBlueButtonClient(client.NewConfig(h.bbBasePath))
 	if err != nil {
 		logger.Error(err)
@@ -502,6 +729,11 @@ func (h *Handler) bulkRequest(w http.ResponseWriter, r *http.Request, reqType se
 		return
 	}
 
+	if rp.Synchronous {
+		h.syncRequest(ctx, w, r, bb, ad, rp, resourceTypes)
+		return
+	}
+
 	acoID := uuid.Parse(ad.ACOID)
 
 	scheme := "http"
@@ -510,9 +742,10 @@ func (h *Handler) bulkRequest(w http.ResponseWriter, r *http.Request, reqType se
 	}
 
 	newJob := models.Job{
-		ACOID:      acoID,
-		RequestURL: fmt.Sprintf("%s://%s%s", scheme, r.Host, r.URL),
-		Status:     models.JobStatusPending,
+		ACOID:         acoID,
+		RequestURL:    fmt.Sprintf("%s://%s%s", scheme, r.Host, r.URL),
+		RequestMethod: r.Method,
+		Status:        models.JobStatusPending,
 	}
 
 	// Need to create job in transaction instead of the very end of the process because we need
@@ -607,18 +840,16 @@ func (h *Handler) bulkRequest(w http.ResponseWriter, r *http.Request, reqType se
 		Since:           rp.Since,
 		TransactionTime: newJob.TransactionTime,
 		CreationTime:    time.Now(),
+		TypeFilter:      rp.TypeFilter,
+		Elements:        rp.Elements,
+		MBIs:            rp.MBIs,
+		OutputFormat:    rp.OutputFormat,
 	}
 	queJobs, err = h.Svc.GetQueJobs(ctx, conditions)
 	if err != nil {
 		logger.Error(err)
-		group := chi.URLParam(r, "groupId")
-		if ok := goerrors.As(err, &service.CCLFNotFoundError{}); ok {
-			h.RespWriter.Exception(r.Context(), w, http.StatusInternalServerError, responseutils.NotFoundErr, fmt.Sprintf("Unable to perform export operations for this Group. No up-to-date attribution information is available for Group '%s'. Usually this is due to awaiting new attribution information at the beginning of a Performance Year.", group))
-			return
-		} else {
-			h.RespWriter.Exception(r.Context(), w, http.StatusInternalServerError, responseutils.InternalErr, err.Error())
-			return
-		}
+		h.writeBeneficiaryError(w, r, err)
+		return
 	}
 	newJob.JobCount = len(queJobs)
 
@@ -645,18 +876,43 @@ func (h *Handler) bulkRequest(w http.ResponseWriter, r *http.Request, reqType se
 	}
 }
 
+// writeBeneficiaryError writes the response for an error encountered while determining the beneficiaries to export
+func (h *Handler) writeBeneficiaryError(w http.ResponseWriter, r *http.Request, err error) {
+	group := chi.URLParam(r, "groupId")
+	var (
+		unattributedErr service.UnattributedMBIError
+		unavailableErr  service.UnavailableMBIError
+	)
+	if ok := goerrors.As(err, &service.CCLFNotFoundError{}); ok {
+		h.RespWriter.Exception(r.Context(), w, http.StatusInternalServerError, responseutils.NotFoundErr, fmt.Sprintf("Unable to perform export operations for this Group. No up-to-date attribution information is available for Group '%s'. Usually this is due to awaiting new attribution information at the beginning of a Performance Year.", group))
+	} else if ok := goerrors.As(err, &unattributedErr); ok {
+		attributedTo := fmt.Sprintf("Group '%s'", group)
+		if group == "" {
+			attributedTo = "your ACO"
+		}
+		h.RespWriter.Exception(r.Context(), w, http.StatusBadRequest, responseutils.RequestErr, fmt.Sprintf("Invalid parameter: %d of the requested patient(s) are not attributed to %s", unattributedErr.Count, attributedTo))
+	} else if ok := goerrors.As(err, &unavailableErr); ok {
+		h.RespWriter.Exception(r.Context(), w, http.StatusBadRequest, responseutils.RequestErr, "Invalid parameter: none of the requested patient(s) are available for export")
+	} else {
+		h.RespWriter.Exception(r.Context(), w, http.StatusInternalServerError, responseutils.InternalErr, err.Error())
+	}
+}
+
 func (h *Handler) getResourceTypes(parameters middleware.RequestParameters, cmsID string) []string {
 	resourceTypes := parameters.ResourceTypes
 
 	// If caller does not supply resource types, we default to all supported resource types for the specific ACO
 	if len(resourceTypes) == 0 {
 		if acoConfig, found := h.Svc.GetACOConfigForID(cmsID); found {
-			if utils.ContainsString(acoConfig.Data, constants.Adjudicated) {
-				resourceTypes = append(resourceTypes, "Patient", "ExplanationOfBenefit", "Coverage")
-			}
-
-			if utils.ContainsString(acoConfig.Data, constants.PartiallyAdjudicated) && h.apiVersion != "v1" {
-				resourceTypes = append(resourceTypes, "Claim", "ClaimResponse")
+			adjudicated := utils.ContainsString(acoConfig.Data, constants.Adjudicated)
+			partiallyAdjudicated := utils.ContainsString(acoConfig.Data, constants.PartiallyAdjudicated) && h.apiVersion != "v1"
+			for _, resource := range resourcetypes.GetResources() {
+				if !resource.Default {
+					continue
+				}
+				if (resource.DataType.Adjudicated && adjudicated) || (resource.DataType.PartiallyAdjudicated && partiallyAdjudicated) {
+					resourceTypes = append(resourceTypes, resource.Name)
+				}
 			}
 		}
 	}
@@ -680,7 +936,18 @@ func (h *Handler) validateResources(resourceTypes []string, cmsID string) error
 	return nil
 }
 
-func (h *Handler) authorizedResourceAccess(dataType service.DataType, cmsID string) bool {
+// validateTypeFilter ensures that each _typeFilter applies to a resource type exported by the request.
+// When _type is absent, resourceTypes holds the ACO's defaults, so a filter for any other type would be ignored.
+func validateTypeFilter(typeFilter map[string][]url.Values, resourceTypes []string) error {
+	for resourceType := range typeFilter {
+		if !utils.ContainsString(resourceTypes, resourceType) {
+			return fmt.Errorf("Invalid _typeFilter: resource type %s is not exported by this request", resourceType)
+		}
+	}
+	return nil
+}
+
+func (h *Handler) authorizedResourceAccess(dataType resourcetypes.DataType, cmsID string) bool {
 	if cfg, ok := h.Svc.GetACOConfigForID(cmsID); ok {
 		return (dataType.Adjudicated && utils.ContainsString(cfg.Data, constants.Adjudicated)) ||
 			(dataType.PartiallyAdjudicated && utils.ContainsString(cfg.Data, constants.PartiallyAdjudicated))
@@ -704,6 +971,124 @@ type FileItem struct {
 	Type string `json:"type"`
 	// URL of the file
 	URL string `json:"url"`
+	// Content type of the file (e.g. application/fhir+ndjson or application/vnd.apache.parquet)
+	ContentType string `json:"contentType,omitempty"`
+	// Number of resources in the file
+	Count int64 `json:"count,omitempty"`
+	// Additional information about the file (e.g. its size in bytes)
+	Extension []FileItemExtension `json:"extension,omitempty"`
+}
+
+const (
+	UncompressedSizeExtensionURL = "https://bcda.cms.gov/fhir/StructureDefinition/uncompressed-size"
+	CompressedSizeExtensionURL   = "https://bcda.cms.gov/fhir/StructureDefinition/compressed-size"
+	DigestExtensionURL           = "https://bcda.cms.gov/fhir/StructureDefinition/sha256"
+)
+
+type FileItemExtension struct {
+	URL string `json:"url"`
+	// Sizes are FHIR integer64 values, which are represented as JSON strings. A size of zero is still reported.
+	ValueInteger64 *int64 `json:"valueInteger64,omitempty,string"`
+	ValueString    string `json:"valueString,omitempty"`
+}
+
+// fileExtensions returns the size and digest extensions for the file
+func fileExtensions(jobKey *models.JobKey) []FileItemExtension {
+	extensions := fileSizeExtensions(jobKey)
+	if jobKey.Digest != "" {
+		extensions = append(extensions, FileItemExtension{URL: DigestExtensionURL, ValueString: jobKey.Digest})
+	}
+	return extensions
+}
+
+// fileSizeExtensions returns the byte size extensions for the file. Job keys created before
+// file sizes were recorded do not have any size information.
+func fileSizeExtensions(jobKey *models.JobKey) []FileItemExtension {
+	if jobKey.UncompressedSize == 0 && jobKey.CompressedSize == 0 {
+		return nil
+	}
+	return []FileItemExtension{
+		{URL: UncompressedSizeExtensionURL, ValueInteger64: &jobKey.UncompressedSize},
+		{URL: CompressedSizeExtensionURL, ValueInteger64: &jobKey.CompressedSize},
+	}
+}
+
+const ProgressExtensionURL = "https://bcda.cms.gov/fhir/StructureDefinition/job-progress"
+
+// JobProgressBody is a FHIR OperationOutcome describing the progress of an in-progress job.
+// It contains an informational issue for each resource type requested by the job.
+type JobProgressBody struct {
+	// OperationOutcome
+	ResourceType string `json:"resourceType"`
+	// Progress of the job for each resource type
+	Issue []JobProgressIssue `json:"issue"`
+}
+
+type JobProgressIssue struct {
+	// Always information
+	Severity string `json:"severity"`
+	// Always informational
+	Code string `json:"code"`
+	// Human readable summary of the progress for the resource type
+	Diagnostics string `json:"diagnostics"`
+	// Progress for the resource type (i.e., resourceType, completedQueueJobs, totalQueueJobs, beneficiariesProcessed, and errors)
+	Extension []JobProgressExtension `json:"extension"`
+}
+
+type JobProgressExtension struct {
+	URL       string                      `json:"url"`
+	Extension []JobProgressValueExtension `json:"extension"`
+}
+
+type JobProgressValueExtension struct {
+	URL          string `json:"url"`
+	ValueString  string `json:"valueString,omitempty"`
+	ValueInteger *int64 `json:"valueInteger,omitempty"`
+}
+
+// newJobProgressBody returns the OperationOutcome reporting the per-resource progress of a job
+func newJobProgressBody(progress []*models.ResourceProgress) JobProgressBody {
+	integer := func(url string, value int64) JobProgressValueExtension {
+		return JobProgressValueExtension{URL: url, ValueInteger: &value}
+	}
+
+	body := JobProgressBody{ResourceType: "OperationOutcome", Issue: []JobProgressIssue{}}
+	for _, p := range progress {
+		body.Issue = append(body.Issue, JobProgressIssue{
+			Severity: "information",
+			Code:     "informational",
+			Diagnostics: fmt.Sprintf("%s: %d of %d queue jobs completed, %d beneficiaries processed, %d errors",
+				p.ResourceType, p.CompletedQueueJobs, p.TotalQueueJobs, p.BeneficiariesProcessed, p.Errors),
+			Extension: []JobProgressExtension{{
+				URL: ProgressExtensionURL,
+				Extension: []JobProgressValueExtension{
+					{URL: "resourceType", ValueString: p.ResourceType},
+					integer("completedQueueJobs", int64(p.CompletedQueueJobs)),
+					integer("totalQueueJobs", int64(p.TotalQueueJobs)),
+					integer("beneficiariesProcessed", p.BeneficiariesProcessed),
+					integer("errors", p.Errors),
+				},
+			}},
+		})
+	}
+	return body
+}
+
+// DataFileContentType returns the content type of a data file generated by the worker
+func DataFileContentType(fileName string) string {
+	if strings.HasSuffix(strings.TrimSpace(fileName), ".parquet") {
+		return constants.ParquetContentType
+	}
+	return constants.FHIRNDJSONContentType
+}
+
+// Data export job is in progress. The response body will contain a FHIR OperationOutcome resource in JSON format describing the progress of each requested resource type.
+// swagger:response jobStatusResponse
+type JobStatusResponse struct {
+	// The status of the job progress
+	XProgress string `json:"X-Progress"`
+	// in: body
+	Body JobProgressBody
 }
 
 /*
@@ -729,3 +1114,175 @@ type BulkResponseBody struct {
 	Errors []FileItem `json:"error"`
 	JobID  uint
 }
+
+const (
+	AttributionDeltaExtensionURL = "https://bcda.cms.gov/fhir/StructureDefinition/attribution-delta"
+	AttributionFileExtensionURL  = "https://bcda.cms.gov/fhir/StructureDefinition/attribution-file"
+	MBISystem                    = "http://hl7.org/fhir/sid/us-mbi"
+
+	// Maximum number of members returned by a single Group read
+	maxGroupMemberCount = 1000
+)
+
+// AttributionGroup is a FHIR Group containing the beneficiaries attributed to an ACO by its latest attribution file.
+// Suppressed beneficiaries are excluded. Members are only included when requested, one page at a time.
+type AttributionGroup struct {
+	// Group
+	ResourceType string    `json:"resourceType"`
+	ID           string    `json:"id"`
+	Meta         GroupMeta `json:"meta"`
+	// Always person
+	Type string `json:"type"`
+	// Always true
+	Actual bool   `json:"actual"`
+	Name   string `json:"name"`
+	// Number of beneficiaries attributed by the file
+	Quantity int `json:"quantity"`
+	// Name, timestamp, and performance year period of the attribution file
+	Extension []GroupExtension `json:"extension"`
+	Member    []GroupMember    `json:"member,omitempty"`
+}
+
+// AttributionDeltaGroup is a FHIR Group containing the beneficiaries that were added to or removed from an ACO's
+// attribution by an attribution file. Added beneficiaries are active members whose period starts when the file was
+// produced, while removed beneficiaries are inactive members whose period ends when the file was produced.
+// Beneficiaries that remained attributed are only reported through the quantity and counts.
+type AttributionDeltaGroup struct {
+	// Group
+	ResourceType string    `json:"resourceType"`
+	ID           string    `json:"id"`
+	Meta         GroupMeta `json:"meta"`
+	// Always person
+	Type string `json:"type"`
+	// Always true
+	Actual bool   `json:"actual"`
+	Name   string `json:"name"`
+	// Number of beneficiaries attributed by the file
+	Quantity int `json:"quantity"`
+	// Counts of added, removed, and retained beneficiaries
+	Extension []GroupExtension `json:"extension"`
+	Member    []GroupMember    `json:"member,omitempty"`
+}
+
+type GroupMeta struct {
+	LastUpdated string `json:"lastUpdated"`
+}
+
+type GroupExtension struct {
+	URL       string                `json:"url"`
+	Extension []GroupValueExtension `json:"extension"`
+}
+
+type GroupValueExtension struct {
+	URL           string       `json:"url"`
+	ValueString   string       `json:"valueString,omitempty"`
+	ValueDateTime string       `json:"valueDateTime,omitempty"`
+	ValueInteger  *int         `json:"valueInteger,omitempty"`
+	ValuePeriod   *GroupPeriod `json:"valuePeriod,omitempty"`
+}
+
+type GroupMember struct {
+	Entity   GroupMemberEntity `json:"entity"`
+	Period   *GroupPeriod      `json:"period,omitempty"`
+	Inactive bool              `json:"inactive"`
+}
+
+type GroupMemberEntity struct {
+	// Always Patient
+	Type       string                `json:"type"`
+	Identifier GroupMemberIdentifier `json:"identifier"`
+}
+
+type GroupMemberIdentifier struct {
+	System string `json:"system"`
+	Value  string `json:"value"`
+}
+
+type GroupPeriod struct {
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+}
+
+// newGroupMember returns a Group member referencing the beneficiary's Patient by MBI
+func newGroupMember(mbi string) GroupMember {
+	return GroupMember{Entity: GroupMemberEntity{Type: "Patient", Identifier: GroupMemberIdentifier{System: MBISystem, Value: mbi}}}
+}
+
+// newAttributionGroup returns the Group describing the total beneficiaries attributed by the CCLF file. Only the
+// page of beneficiaries that was requested is included as members.
+func newAttributionGroup(groupID string, cmsID string, cclfFile *models.CCLFFile, benes []*models.CCLFBeneficiary, total int) AttributionGroup {
+	timestamp := cclfFile.Timestamp.UTC().Format(time.RFC3339)
+	performanceYear := 2000 + cclfFile.PerformanceYear
+
+	name := fmt.Sprintf("Beneficiaries attributed to %s", cmsID)
+	if groupID == groupRunout {
+		name = fmt.Sprintf("Runout beneficiaries attributed to %s", cmsID)
+	}
+
+	group := AttributionGroup{
+		ResourceType: "Group",
+		ID:           groupID,
+		Meta:         GroupMeta{LastUpdated: timestamp},
+		Type:         "person",
+		Actual:       true,
+		Name:         name,
+		Quantity:     total,
+		Extension: []GroupExtension{{
+			URL: AttributionFileExtensionURL,
+			Extension: []GroupValueExtension{
+				{URL: "name", ValueString: cclfFile.Name},
+				{URL: "timestamp", ValueDateTime: timestamp},
+				{URL: "period", ValuePeriod: &GroupPeriod{
+					Start: fmt.Sprintf("%d-01-01", performanceYear),
+					End:   fmt.Sprintf("%d-12-31", performanceYear),
+				}},
+			},
+		}},
+	}
+
+	for _, bene := range benes {
+		group.Member = append(group.Member, newGroupMember(bene.MBI))
+	}
+
+	return group
+}
+
+// newAttributionDeltaGroup returns the Group describing the attribution delta recorded for the CCLF file
+func newAttributionDeltaGroup(cclfFile *models.CCLFFile, delta *models.CCLFAttributionDelta) AttributionDeltaGroup {
+	timestamp := cclfFile.Timestamp.UTC().Format(time.RFC3339)
+	integer := func(url string, value int) GroupValueExtension {
+		return GroupValueExtension{URL: url, ValueInteger: &value}
+	}
+
+	group := AttributionDeltaGroup{
+		ResourceType: "Group",
+		ID:           fmt.Sprintf("attribution-delta-%d", delta.ID),
+		Meta:         GroupMeta{LastUpdated: delta.CreatedAt.UTC().Format(time.RFC3339)},
+		Type:         "person",
+		Actual:       true,
+		Name:         fmt.Sprintf("Attribution changes in %s", cclfFile.Name),
+		Quantity:     delta.AddedCount + delta.RetainedCount,
+		Extension: []GroupExtension{{
+			URL: AttributionDeltaExtensionURL,
+			Extension: []GroupValueExtension{
+				integer("added", delta.AddedCount),
+				integer("removed", delta.RemovedCount),
+				integer("retained", delta.RetainedCount),
+			},
+		}},
+	}
+
+	for _, mbi := range delta.AddedMBIs {
+		m := newGroupMember(mbi)
+		m.Period = &GroupPeriod{Start: timestamp}
+		group.Member = append(group.Member, m)
+	}
+	for _, mbi := range delta.RemovedMBIs {
+		m := newGroupMember(mbi)
+		m.Period = &GroupPeriod{End: timestamp}
+		m.Inactive = true
+		group.Member = append(group.Member, m)
+	}
+
+	return group
+}
@@ -10,6 +10,8 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -26,6 +28,7 @@ import (
 	"github.com/CMSgov/bcda-app/bcda/database/databasetest"
 	"github.com/CMSgov/bcda-app/bcda/models"
 	"github.com/CMSgov/bcda-app/bcda/models/postgres/postgrestest"
+	"github.com/CMSgov/bcda-app/bcda/resourcetypes"
 	"github.com/CMSgov/bcda-app/bcda/responseutils"
 	"github.com/CMSgov/bcda-app/bcda/service"
 	"github.com/CMSgov/bcda-app/bcda/testUtils"
@@ -64,7 +67,7 @@ type RequestsTestSuite struct {
 
 	acoID uuid.UUID
 
-	resourceType map[string]service.DataType
+	resourceType map[string]resourcetypes.DataType
 }
 
 func TestRequestsTestSuite(t *testing.T) {
@@ -81,7 +84,7 @@ func (s *RequestsTestSuite) SetupSuite() {
 		testfixtures.Directory("testdata/"),
 	)
 
-	s.resourceType = map[string]service.DataType{
+	s.resourceType = map[string]resourcetypes.DataType{
 		"Patient":              {Adjudicated: true},
 		"Coverage":             {Adjudicated: true},
 		"ExplanationOfBenefit": {Adjudicated: true},
@@ -220,7 +223,7 @@ func (s *RequestsTestSuite) TestJobsStatusV1() {
 				)
 			}
 
-			h := newHandler(map[string]service.DataType{
+			h := newHandler(map[string]resourcetypes.DataType{
 				"Patient":              {},
 				"Coverage":             {},
 				"ExplanationOfBenefit": {},
@@ -334,7 +337,7 @@ func (s *RequestsTestSuite) TestJobsStatusV2() {
 
 				}
 			}
-			h := newHandler(map[string]service.DataType{
+			h := newHandler(map[string]resourcetypes.DataType{
 				"Patient":              {},
 				"Coverage":             {},
 				"ExplanationOfBenefit": {},
@@ -540,7 +543,7 @@ func (s *RequestsTestSuite) TestDataTypeAuthorization() {
 		Data:               []string{},
 	}
 
-	dataTypeMap := map[string]service.DataType{
+	dataTypeMap := map[string]resourcetypes.DataType{
 		"Coverage":             {Adjudicated: true},
 		"Patient":              {Adjudicated: true},
 		"ExplanationOfBenefit": {Adjudicated: true},
@@ -703,6 +706,60 @@ func (s *RequestsTestSuite) TestRequests() {
 			assert.Equal(s.T(), http.StatusAccepted, rr.Code)
 		}
 	}
+
+	// POST requests are recorded with the method used to request them
+	rp := middleware.RequestParameters{Version: apiVersionOne, ResourceTypes: []string{"Patient"}}
+	req := s.genPatientRequest(rp)
+	req.Method = http.MethodPost
+	rr := httptest.NewRecorder()
+	h.BulkPatientRequest(rr, req)
+	assert.Equal(s.T(), http.StatusAccepted, rr.Code)
+
+	location := strings.Split(rr.Header().Get("Content-Location"), "/")
+	id, err := strconv.ParseUint(location[len(location)-1], 10, 0)
+	assert.NoError(s.T(), err)
+	jobID, err := safecast.ToUint(id)
+	assert.NoError(s.T(), err)
+	job := postgrestest.GetJobByID(s.T(), s.db, jobID)
+	assert.Equal(s.T(), http.MethodPost, job.RequestMethod)
+	assert.Equal(s.T(), "POST "+job.RequestURL, job.Request())
+}
+
+func (s *RequestsTestSuite) TestRequestsUnavailablePatients() {
+	apiVersion := "v1"
+	h := newHandler(s.resourceType, "/"+apiVersion+"/fhir", apiVersion, s.db)
+
+	mockSvc := service.MockService{}
+	mockSvc.On("GetQueJobs", mock.Anything, mock.Anything).Return(nil, service.UnavailableMBIError{Count: 2})
+	mockSvc.On("GetACOConfigForID", mock.Anything, mock.Anything).Return(&service.ACOConfig{Data: []string{"adjudicated"}}, true)
+	h.Svc = &mockSvc
+
+	rp := middleware.RequestParameters{Version: apiVersionOne, ResourceTypes: []string{"Patient"}, MBIs: []string{"1A00A00AA01", "1A00A00AA02"}}
+	rr := httptest.NewRecorder()
+	h.BulkPatientRequest(rr, s.genPatientRequest(rp))
+
+	assert.Equal(s.T(), http.StatusBadRequest, rr.Code)
+	assert.Contains(s.T(), rr.Body.String(), "Invalid parameter: none of the requested patient(s) are available for export")
+	assert.NotContains(s.T(), rr.Body.String(), "1A00A00AA01")
+}
+
+func (s *RequestsTestSuite) TestRequestsUnattributedPatients() {
+	apiVersion := "v1"
+	h := newHandler(s.resourceType, "/"+apiVersion+"/fhir", apiVersion, s.db)
+
+	mockSvc := service.MockService{}
+	mockSvc.On("GetQueJobs", mock.Anything, mock.Anything).Return(nil, service.UnattributedMBIError{Count: 1})
+	mockSvc.On("GetACOConfigForID", mock.Anything, mock.Anything).Return(&service.ACOConfig{Data: []string{"adjudicated"}}, true)
+	h.Svc = &mockSvc
+
+	rp := middleware.RequestParameters{Version: apiVersionOne, ResourceTypes: []string{"Patient"}, MBIs: []string{"1A00A00AA01", "1A00A00AA02"}}
+	rr := httptest.NewRecorder()
+	h.BulkPatientRequest(rr, s.genPatientRequest(rp))
+
+	// The MBIs are not echoed in the response
+	assert.Equal(s.T(), http.StatusBadRequest, rr.Code)
+	assert.Contains(s.T(), rr.Body.String(), "Invalid parameter: 1 of the requested patient(s) are not attributed to your ACO")
+	assert.NotContains(s.T(), rr.Body.String(), "1A00A00AA0")
 }
 
 func (s *RequestsTestSuite) TestJobStatusErrorHandling() {
@@ -788,6 +845,8 @@ func (s *RequestsTestSuite) TestJobStatusErrorHandling() {
 					}},
 					errResp,
 				)
+				mockSrv.On("GetJobProgress", testUtils.CtxMatcher, uint(1)).Return(
+					[]*models.ResourceProgress{{ResourceType: "Patient", CompletedQueueJobs: 1, TotalQueueJobs: 2}}, nil)
 
 				h.Svc = &mockSrv
 
@@ -812,7 +871,7 @@ func (s *RequestsTestSuite) TestJobStatusErrorHandling() {
 			case http.StatusOK:
 				s.Equal(constants.JsonContentType, w.Header().Get("Content-Type"))
 			case http.StatusAccepted:
-				s.Equal("", w.Header().Get("Content-Type"))
+				s.Equal(constants.FHIRJsonContentType, w.Header().Get("Content-Type"))
 			}
 
 		})
@@ -824,8 +883,10 @@ func (s *RequestsTestSuite) TestJobStatusProgress() {
 		testName         string
 		status           models.JobStatus
 		expectedProgress string
+		progressErr      error
 	}{
 		{testName: "In-Progress job displays partial progress", status: models.JobStatusInProgress, expectedProgress: "50%"},
+		{testName: "In-Progress job displays progress without breakdown", status: models.JobStatusInProgress, expectedProgress: "50%", progressErr: sql.ErrConnDone},
 		{testName: "Completed job doesn't display progress", status: models.JobStatusCompleted, expectedProgress: ""},
 		{testName: "Archived job doesn't display progress", status: models.JobStatusArchived, expectedProgress: ""},
 	}
@@ -851,15 +912,47 @@ func (s *RequestsTestSuite) TestJobStatusProgress() {
 			mockSrv := service.MockService{}
 			h.Svc = &mockSrv
 			mockSrv.On("GetJobAndKeys", testUtils.CtxMatcher, job.ID).Return(&job, []*models.JobKey{&jobKey}, nil)
+			progress := []*models.ResourceProgress{
+				{ResourceType: "Coverage", CompletedQueueJobs: 0, TotalQueueJobs: 1},
+				{ResourceType: "Patient", CompletedQueueJobs: 1, TotalQueueJobs: 1, BeneficiariesProcessed: 50, Errors: 2},
+			}
+			mockSrv.On("GetJobProgress", testUtils.CtxMatcher, job.ID).Return(progress, tt.progressErr)
 			w := httptest.NewRecorder()
 
 			h.JobStatus(w, req)
 			progressHeader := w.Header().Get("X-Progress")
 			if tt.expectedProgress == "" {
 				s.Empty(progressHeader)
-			} else {
-				s.Contains(progressHeader, tt.expectedProgress)
+				return
 			}
+			s.Contains(progressHeader, tt.expectedProgress)
+			s.Equal(http.StatusAccepted, w.Code)
+
+			if tt.progressErr != nil {
+				s.Empty(w.Body.String())
+				return
+			}
+
+			var body JobProgressBody
+			s.NoError(json.Unmarshal(w.Body.Bytes(), &body))
+			s.Equal("OperationOutcome", body.ResourceType)
+			s.Len(body.Issue, 2)
+			s.Equal("Coverage: 0 of 1 queue jobs completed, 0 beneficiaries processed, 0 errors", body.Issue[0].Diagnostics)
+
+			patient := body.Issue[1]
+			s.Equal("information", patient.Severity)
+			s.Equal("Patient: 1 of 1 queue jobs completed, 50 beneficiaries processed, 2 errors", patient.Diagnostics)
+			s.Len(patient.Extension, 1)
+			s.Equal(ProgressExtensionURL, patient.Extension[0].URL)
+			values := make(map[string]JobProgressValueExtension)
+			for _, ext := range patient.Extension[0].Extension {
+				values[ext.URL] = ext
+			}
+			s.Equal("Patient", values["resourceType"].ValueString)
+			s.Equal(int64(1), *values["completedQueueJobs"].ValueInteger)
+			s.Equal(int64(1), *values["totalQueueJobs"].ValueInteger)
+			s.Equal(int64(50), *values["beneficiariesProcessed"].ValueInteger)
+			s.Equal(int64(2), *values["errors"].ValueInteger)
 		})
 	}
 }
@@ -923,6 +1016,331 @@ func (s *RequestsTestSuite) TestDeleteJob() {
 
 }
 
+func (s *RequestsTestSuite) TestRetryJob() {
+	job := &models.Job{ACOID: s.acoID, Status: models.JobStatusCompleted}
+	postgrestest.CreateJobs(s.T(), s.db, job)
+	defer postgrestest.DeleteJobByID(s.T(), s.db, job.ID)
+	jobID := strconv.FormatUint(uint64(job.ID), 10)
+
+	tests := []struct {
+		name           string
+		jobId          string
+		svcErr         error
+		enqErr         error
+		responseHeader int
+	}{
+		{name: "Successful Retry", jobId: jobID, responseHeader: http.StatusAccepted},
+		{name: "Invalid Job ID (Overflow)", jobId: "112341234123412341234123412341234123", responseHeader: http.StatusBadRequest},
+		{name: "Job Not Completed", jobId: jobID, svcErr: service.ErrJobNotRetryable, responseHeader: http.StatusBadRequest},
+		{name: "No Failed Beneficiaries", jobId: jobID, svcErr: service.ErrNoFailedBeneficiaries, responseHeader: http.StatusBadRequest},
+		{name: "Retry In Progress", jobId: jobID, svcErr: service.ErrRetryInProgress, responseHeader: http.StatusBadRequest},
+		{name: "Internal Server Error Creating Job", jobId: jobID, svcErr: errors.New("New Error (doesn't matter)"), responseHeader: http.StatusInternalServerError},
+		{name: "Internal Server Error Enqueuing Job", jobId: jobID, enqErr: errors.New("New Error (doesn't matter)"), responseHeader: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			handler := newHandler(s.resourceType, v2BasePath, apiVersionTwo, s.db)
+
+			queJobs := []*models.JobEnqueueArgs{
+				{CMSID: "A9999", ResourceType: "Patient", BeneficiaryIDs: []string{"1"}},
+				{CMSID: "A9999", ResourceType: "ExplanationOfBenefit", BeneficiaryIDs: []string{"2", "3"}, Since: "gt2020-02-13T08:00:00.000-05:00"},
+			}
+
+			mockSrv := service.MockService{}
+			if tt.svcErr != nil {
+				mockSrv.On("NewRetryJob", testUtils.CtxMatcher, job.ID).Return(nil, nil, tt.svcErr)
+			} else {
+				retryJob := &models.Job{ACOID: s.acoID, Status: models.JobStatusPending, JobCount: len(queJobs), RetryOf: job.ID}
+				mockSrv.On("NewRetryJob", testUtils.CtxMatcher, job.ID).Return(retryJob, queJobs, nil)
+			}
+			mockSrv.On("GetJobPriority", "A9999", "Patient", false).Return(int16(2))
+			mockSrv.On("GetJobPriority", "A9999", "ExplanationOfBenefit", true).Return(int16(3))
+			handler.Svc = &mockSrv
+
+			enqueuer := &mockEnq.Enqueuer{}
+			enqueuer.On("AddJob", testUtils.CtxMatcher, mock.Anything, mock.Anything).Return(tt.enqErr)
+			handler.Enq = enqueuer
+
+			r := httptest.NewRequest("POST", fmt.Sprintf("/api/v2/jobs/%s/$retry", tt.jobId), nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("jobID", tt.jobId)
+
+			ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+			newLogEntry := MakeTestStructuredLoggerEntry(logrus.Fields{"cms_id": "A9999", "request_id": uuid.NewRandom().String()})
+			r = r.WithContext(context.WithValue(ctx, log.CtxLoggerKey, newLogEntry))
+
+			w := httptest.NewRecorder()
+
+			handler.RetryJob(w, r)
+
+			assert.Equal(t, tt.responseHeader, w.Code)
+			if tt.enqErr != nil {
+				// The retry job is rolled back when its queue jobs cannot be enqueued
+				for _, j := range postgrestest.GetJobsByACOID(t, s.db, s.acoID) {
+					assert.NotEqual(t, job.ID, j.RetryOf)
+				}
+			}
+			if tt.responseHeader != http.StatusAccepted {
+				return
+			}
+
+			location := strings.Split(w.Header().Get("Content-Location"), "/")
+			id, err := strconv.ParseUint(location[len(location)-1], 10, 0)
+			assert.NoError(t, err)
+			retryJobID, err := safecast.ToUint(id)
+			assert.NoError(t, err)
+			defer postgrestest.DeleteJobByID(t, s.db, retryJobID)
+
+			assert.Equal(t, fmt.Sprintf("http://example.com/api/v2/jobs/%d", retryJobID), w.Header().Get("Content-Location"))
+			assert.Equal(t, job.ID, postgrestest.GetJobByID(t, s.db, retryJobID).RetryOf)
+			for i, priority := range []int{2, 3} {
+				assert.EqualValues(t, retryJobID, queJobs[i].ID)
+				enqueuer.AssertCalled(t, "AddJob", testUtils.CtxMatcher, *queJobs[i], priority)
+			}
+		})
+	}
+}
+
+func (s *RequestsTestSuite) TestAttributionDelta() {
+	cclfFile := &models.CCLFFile{ID: 1, Name: "T.BCD.A9999.ZCY24.D240101.T0000000", Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	delta := &models.CCLFAttributionDelta{ID: 2, FileID: 1, PreviousFileID: 3, AddedMBIs: []string{"1A00A00AA01"}, RemovedMBIs: []string{"1A00A00AA02"},
+		AddedCount: 1, RemovedCount: 1, RetainedCount: 4, CreatedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name        string
+		svcErr      error
+		invalidAuth bool
+		respCode    int
+	}{
+		{"Successful", nil, false, http.StatusOK},
+		{"No CCLF file", service.CCLFNotFoundError{FileNumber: 8, CMSID: "A9999"}, false, http.StatusNotFound},
+		{"No attribution delta", service.ErrAttributionDeltaNotFound, false, http.StatusNotFound},
+		{"Internal Server Error", errors.New("database error"), false, http.StatusInternalServerError},
+		{"Invalid Auth", nil, true, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			mockSvc := &service.MockService{}
+			if tt.svcErr != nil {
+				mockSvc.On("GetAttributionDelta", testUtils.CtxMatcher, mock.Anything, models.FileTypeDefault).Return(nil, nil, tt.svcErr)
+			} else {
+				mockSvc.On("GetAttributionDelta", testUtils.CtxMatcher, mock.Anything, models.FileTypeDefault).Return(cclfFile, delta, nil)
+			}
+
+			h := newHandler(s.resourceType, v2BasePath, apiVersionTwo, s.db)
+			h.Svc = mockSvc
+
+			rr := httptest.NewRecorder()
+			req := s.genASRequest()
+			if tt.invalidAuth {
+				req = s.genASRequestInvalidAuth()
+			}
+
+			h.AttributionDelta(rr, req)
+
+			assert.Equal(t, tt.respCode, rr.Code)
+			if tt.respCode != http.StatusOK {
+				return
+			}
+			assert.Equal(t, constants.FHIRJsonContentType, rr.Header().Get(constants.ContentType))
+
+			var group AttributionDeltaGroup
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &group))
+			assert.Equal(t, newAttributionDeltaGroup(cclfFile, delta), group)
+		})
+	}
+}
+
+func TestNewAttributionDeltaGroup(t *testing.T) {
+	cclfFile := &models.CCLFFile{ID: 1, Name: "T.BCD.A9999.ZCY24.D240101.T0000000", Timestamp: time.Date(2024, 1, 1, 5, 0, 0, 0, time.FixedZone("EST", -5*60*60))}
+	delta := &models.CCLFAttributionDelta{ID: 2, FileID: 1, PreviousFileID: 3, AddedMBIs: []string{"1A00A00AA01"}, RemovedMBIs: []string{"1A00A00AA02"},
+		AddedCount: 1, RemovedCount: 1, RetainedCount: 4, CreatedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+	added, removed, retained := 1, 1, 4
+
+	expected := AttributionDeltaGroup{
+		ResourceType: "Group",
+		ID:           "attribution-delta-2",
+		Meta:         GroupMeta{LastUpdated: "2024-01-02T00:00:00Z"},
+		Type:         "person",
+		Actual:       true,
+		Name:         "Attribution changes in T.BCD.A9999.ZCY24.D240101.T0000000",
+		Quantity:     5,
+		Extension: []GroupExtension{{
+			URL: AttributionDeltaExtensionURL,
+			Extension: []GroupValueExtension{
+				{URL: "added", ValueInteger: &added},
+				{URL: "removed", ValueInteger: &removed},
+				{URL: "retained", ValueInteger: &retained},
+			},
+		}},
+		Member: []GroupMember{
+			{
+				Entity: GroupMemberEntity{Type: "Patient", Identifier: GroupMemberIdentifier{System: MBISystem, Value: "1A00A00AA01"}},
+				Period: &GroupPeriod{Start: "2024-01-01T10:00:00Z"},
+			},
+			{
+				Entity:   GroupMemberEntity{Type: "Patient", Identifier: GroupMemberIdentifier{System: MBISystem, Value: "1A00A00AA02"}},
+				Period:   &GroupPeriod{End: "2024-01-01T10:00:00Z"},
+				Inactive: true,
+			},
+		},
+	}
+	assert.Equal(t, expected, newAttributionDeltaGroup(cclfFile, delta))
+
+	// Beneficiaries that remained attributed are not members
+	delta = &models.CCLFAttributionDelta{ID: 2, RetainedCount: 4}
+	group := newAttributionDeltaGroup(cclfFile, delta)
+	assert.Nil(t, group.Member)
+	assert.Equal(t, 4, group.Quantity)
+}
+
+func (s *RequestsTestSuite) TestGroup() {
+	err := conf.SetEnv(s.T(), "BCDA_ENABLE_RUNOUT", "true")
+	assert.Empty(s.T(), err)
+
+	cclfFile := &models.CCLFFile{ID: 1, Name: "T.BCD.A9999.ZCY24.D240101.T0000000", Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), PerformanceYear: 24}
+	benes := []*models.CCLFBeneficiary{{ID: 1, MBI: "1A00A00AA01"}, {ID: 2, MBI: "1A00A00AA02"}, {ID: 3, MBI: "1A00A00AA03"}}
+
+	tests := []struct {
+		name        string
+		groupID     string
+		query       string
+		fileType    models.CCLFFileType
+		offset      int
+		count       int
+		svcErr      error
+		invalidAuth bool
+		respCode    int
+		members     []string
+	}{
+		{"All", "all", "", models.FileTypeDefault, 0, 0, nil, false, http.StatusOK, nil},
+		{"Runout", "runout", "", models.FileTypeRunout, 0, 0, nil, false, http.StatusOK, nil},
+		{"Paged members", "all", "?_count=2&_offset=1", models.FileTypeDefault, 1, 2, nil, false, http.StatusOK, []string{"1A00A00AA02", "1A00A00AA03"}},
+		{"Invalid group ID", "someGroup", "", models.FileTypeDefault, 0, 0, nil, false, http.StatusBadRequest, nil},
+		{"Invalid _count", "all", "?_count=1001", models.FileTypeDefault, 0, 0, nil, false, http.StatusBadRequest, nil},
+		{"Invalid _offset", "all", "?_count=2&_offset=-1", models.FileTypeDefault, 0, 0, nil, false, http.StatusBadRequest, nil},
+		{"No CCLF file", "all", "", models.FileTypeDefault, 0, 0, service.CCLFNotFoundError{FileNumber: 8, CMSID: "A9999"}, false, http.StatusNotFound, nil},
+		{"Internal Server Error", "all", "", models.FileTypeDefault, 0, 0, errors.New("database error"), false, http.StatusInternalServerError, nil},
+		{"Invalid Auth", "all", "", models.FileTypeDefault, 0, 0, nil, true, http.StatusUnauthorized, nil},
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			mockSvc := &service.MockService{}
+			mockSvc.On("GetACOConfigForID", mock.Anything).Return(&service.ACOConfig{}, true)
+			if tt.svcErr != nil {
+				mockSvc.On("GetGroupMembers", mock.Anything, mock.Anything, tt.fileType, tt.offset, tt.count).Return(nil, nil, 0, tt.svcErr)
+			} else if tt.count == 0 {
+				mockSvc.On("GetGroupMembers", mock.Anything, mock.Anything, tt.fileType, tt.offset, tt.count).Return(cclfFile, nil, len(benes), nil)
+			} else {
+				mockSvc.On("GetGroupMembers", mock.Anything, mock.Anything, tt.fileType, tt.offset, tt.count).Return(cclfFile, benes[tt.offset:], len(benes), nil)
+			}
+
+			h := newHandler(s.resourceType, v2BasePath, apiVersionTwo, s.db)
+			h.Svc = mockSvc
+
+			req := s.genASRequest()
+			if tt.invalidAuth {
+				req = s.genASRequestInvalidAuth()
+			}
+			req.URL.RawQuery = strings.TrimPrefix(tt.query, "?")
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("groupId", tt.groupID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			h.Group(rr, req)
+
+			assert.Equal(t, tt.respCode, rr.Code)
+			if tt.respCode != http.StatusOK {
+				return
+			}
+			assert.Equal(t, constants.FHIRJsonContentType, rr.Header().Get(constants.ContentType))
+
+			var group AttributionGroup
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &group))
+			assert.Equal(t, "Group", group.ResourceType)
+			assert.Equal(t, tt.groupID, group.ID)
+			assert.Equal(t, len(benes), group.Quantity)
+
+			var members []string
+			for _, m := range group.Member {
+				members = append(members, m.Entity.Identifier.Value)
+			}
+			assert.Equal(t, tt.members, members)
+		})
+	}
+}
+
+func TestNewAttributionGroup(t *testing.T) {
+	cclfFile := &models.CCLFFile{ID: 1, Name: "T.BCD.A9999.ZCY24.D240101.T0000000", Timestamp: time.Date(2024, 1, 1, 5, 0, 0, 0, time.FixedZone("EST", -5*60*60)), PerformanceYear: 24}
+	benes := []*models.CCLFBeneficiary{{ID: 1, MBI: "1A00A00AA01"}, {ID: 2, MBI: "1A00A00AA02"}, {ID: 3, MBI: "1A00A00AA03"}}
+
+	expected := AttributionGroup{
+		ResourceType: "Group",
+		ID:           "all",
+		Meta:         GroupMeta{LastUpdated: "2024-01-01T10:00:00Z"},
+		Type:         "person",
+		Actual:       true,
+		Name:         "Beneficiaries attributed to A9999",
+		Quantity:     3,
+		Extension: []GroupExtension{{
+			URL: AttributionFileExtensionURL,
+			Extension: []GroupValueExtension{
+				{URL: "name", ValueString: "T.BCD.A9999.ZCY24.D240101.T0000000"},
+				{URL: "timestamp", ValueDateTime: "2024-01-01T10:00:00Z"},
+				{URL: "period", ValuePeriod: &GroupPeriod{Start: "2024-01-01", End: "2024-12-31"}},
+			},
+		}},
+		Member: []GroupMember{
+			{Entity: GroupMemberEntity{Type: "Patient", Identifier: GroupMemberIdentifier{System: MBISystem, Value: "1A00A00AA02"}}},
+		},
+	}
+	assert.Equal(t, expected, newAttributionGroup("all", "A9999", cclfFile, benes[1:2], 3))
+
+	// The quantity describes every attributed beneficiary when no members are requested
+	runout := newAttributionGroup("runout", "A9999", cclfFile, nil, 3)
+	assert.Equal(t, "Runout beneficiaries attributed to A9999", runout.Name)
+	assert.Equal(t, 3, runout.Quantity)
+	assert.Nil(t, runout.Member)
+}
+
+func TestParseGroupPaging(t *testing.T) {
+	tests := []struct {
+		query          string
+		count, offset  int
+		expectedErrMsg string
+	}{
+		{"", 0, 0, ""},
+		{"_count=10", 10, 0, ""},
+		{"_count=10&_offset=20", 10, 20, ""},
+		{"_count=1000", 1000, 0, ""},
+		{"_count=1001", 0, 0, "_count must be an integer between 0 and 1000"},
+		{"_count=-1", 0, 0, "_count must be an integer between 0 and 1000"},
+		{"_count=ten", 0, 0, "_count must be an integer between 0 and 1000"},
+		{"_offset=-1", 0, 0, "_offset must be a non-negative integer"},
+		{"_offset=one", 0, 0, "_offset must be a non-negative integer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			assert.NoError(t, err)
+
+			count, offset, err := parseGroupPaging(query)
+			if tt.expectedErrMsg != "" {
+				assert.EqualError(t, err, tt.expectedErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.count, count)
+			assert.Equal(t, tt.offset, offset)
+		})
+	}
+}
+
 func (s *RequestsTestSuite) TestJobFailedStatus() {
 
 	tests := []struct {
@@ -1089,7 +1507,7 @@ func MakeTestStructuredLoggerEntry(logFields logrus.Fields) *log.StructuredLogge
 func (s *RequestsTestSuite) TestValidateResources() {
 	apiVersion := "v1"
 	fhirPath := "/" + apiVersion + "/fhir"
-	h := newHandler(map[string]service.DataType{
+	h := newHandler(map[string]resourcetypes.DataType{
 		"Patient":              {},
 		"Coverage":             {},
 		"ExplanationOfBenefit": {},
@@ -1098,6 +1516,21 @@ func (s *RequestsTestSuite) TestValidateResources() {
 	assert.Contains(s.T(), err.Error(), "invalid resource type")
 }
 
+func TestValidateTypeFilter(t *testing.T) {
+	typeFilter := map[string][]url.Values{"Claim": {{"service-date": {"ge2021-01-01"}}}}
+	assert.NoError(t, validateTypeFilter(typeFilter, []string{"Claim", "ClaimResponse"}))
+
+	// A filter for a resource type that the ACO does not export by default would be ignored
+	err := validateTypeFilter(typeFilter, []string{"Patient", "ExplanationOfBenefit", "Coverage"})
+	assert.EqualError(t, err, "Invalid _typeFilter: resource type Claim is not exported by this request")
+}
+
+func (s *RequestsTestSuite) TestDataFileContentType() {
+	assert.Equal(s.T(), "application/fhir+ndjson", DataFileContentType("d0b9ed7c-c1e0-4bc6-a7ae-4c4d36a43d8b.ndjson"))
+	assert.Equal(s.T(), "application/fhir+ndjson", DataFileContentType(models.BlankFileName))
+	assert.Equal(s.T(), "application/vnd.apache.parquet", DataFileContentType("d0b9ed7c-c1e0-4bc6-a7ae-4c4d36a43d8b.parquet"))
+}
+
 type CCLFNotFoundOperationOutcomeError struct {
 	FileNumber int
 	CMSID      string
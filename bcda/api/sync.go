@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/CMSgov/bcda-app/bcda/auth"
+	"github.com/CMSgov/bcda-app/bcda/client"
+	"github.com/CMSgov/bcda-app/bcda/constants"
+	"github.com/CMSgov/bcda-app/bcda/models"
+	fhirmodels "github.com/CMSgov/bcda-app/bcda/models/fhir"
+	"github.com/CMSgov/bcda-app/bcda/resourcetypes"
+	"github.com/CMSgov/bcda-app/bcda/responseutils"
+	"github.com/CMSgov/bcda-app/bcda/service"
+	"github.com/CMSgov/bcda-app/bcda/web/middleware"
+	"github.com/CMSgov/bcda-app/log"
+)
+
+// searchsetBundle is the FHIR Bundle returned by a synchronous export
+type searchsetBundle struct {
+	ResourceType string `json:"resourceType"`
+	Type         string `json:"type"`
+	Meta         struct {
+		LastUpdated time.Time `json:"lastUpdated"`
+	} `json:"meta"`
+	Total int                      `json:"total"`
+	Entry []fhirmodels.BundleEntry `json:"entry"`
+}
+
+// syncRequest retrieves the data for a small number of beneficiaries (supplied via the patient parameter)
+// directly from the BlueButton API and returns it as a searchset Bundle instead of creating a job.
+// The beneficiaries and the data retrieved for them are determined exactly as they are for an export job.
+func (h *Handler) syncRequest(ctx context.Context, w http.ResponseWriter, r *http.Request, bb client.StreamingAPIClient,
+	ad auth.AuthData, rp middleware.RequestParameters, resourceTypes []string) {
+	logger := log.GetCtxLogger(ctx)
+
+	// request a fake patient in order to acquire the bundle's lastUpdated metadata
+	b, err := bb.GetPatient(models.JobEnqueueArgs{ACOID: ad.ACOID, CMSID: ad.CMSID, TransactionTime: time.Now()}, "0")
+	if err != nil {
+		logger.Error(err)
+		h.RespWriter.Exception(r.Context(), w, http.StatusInternalServerError, responseutils.FormatErr, "Failure to retrieve transactionTime metadata from FHIR Data Server.")
+		return
+	}
+
+	conditions := service.RequestConditions{
+		ReqType:   service.DefaultRequest,
+		Resources: resourceTypes,
+
+		CMSID: ad.CMSID,
+		ACOID: uuid.Parse(ad.ACOID),
+
+		Since:           rp.Since,
+		TransactionTime: b.Meta.LastUpdated,
+		CreationTime:    time.Now(),
+		TypeFilter:      rp.TypeFilter,
+		Elements:        rp.Elements,
+		MBIs:            rp.MBIs,
+	}
+
+	// The job arguments describe the data (resource type, claims window, etc.) to retrieve for the beneficiaries
+	benes, jobArgs, err := h.Svc.GetBeneficiaryJobs(ctx, conditions)
+	if err != nil {
+		logger.Error(err)
+		h.writeBeneficiaryError(w, r, err)
+		return
+	}
+
+	entries, err := getSyncEntries(bb, benes, jobArgs)
+	if err != nil {
+		logger.Error(err)
+		h.RespWriter.Exception(r.Context(), w, http.StatusInternalServerError, responseutils.InternalErr, "Failure to retrieve data from FHIR Data Server.")
+		return
+	}
+
+	bundle := searchsetBundle{
+		ResourceType: "Bundle",
+		Type:         "searchset",
+		Total:        len(entries),
+		Entry:        entries,
+	}
+	bundle.Meta.LastUpdated = b.Meta.LastUpdated
+
+	w.Header().Set(constants.ContentType, constants.FHIRJsonContentType)
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(bundle); err != nil {
+		logger.Error(errors.Wrap(err, "Failed to encode synchronous export bundle"))
+	}
+}
+
+// getSyncEntries retrieves the resources described by the job arguments from the BlueButton API
+func getSyncEntries(bb client.StreamingAPIClient, benes []*models.CCLFBeneficiary, jobArgs []*models.JobEnqueueArgs) ([]fhirmodels.BundleEntry, error) {
+	benesByID := make(map[string]*models.CCLFBeneficiary, len(benes))
+	for _, bene := range benes {
+		benesByID[fmt.Sprint(bene.ID)] = bene
+	}
+
+	// Avoid looking up the same beneficiary for every resource type
+	blueButtonIDs := make(map[string]string, len(benes))
+
+	entries := []fhirmodels.BundleEntry{}
+	for _, args := range jobArgs {
+		resource, ok := resourcetypes.GetResource(args.ResourceType)
+		if !ok {
+			return nil, fmt.Errorf("unsupported resource type requested: %s", args.ResourceType)
+		}
+
+		for _, beneID := range args.BeneficiaryIDs {
+			bene, ok := benesByID[beneID]
+			if !ok {
+				return nil, fmt.Errorf("unexpected cclfBeneficiaryId %s", beneID)
+			}
+
+			cclfBeneficiary := *bene
+			// Resources retrieved by MBI do not need the BlueButton ID
+			if !resource.RequiresMBI {
+				bbID, ok := blueButtonIDs[cclfBeneficiary.MBI]
+				if !ok {
+					var err error
+					if bbID, err = client.GetBlueButtonID(bb, cclfBeneficiary.MBI, *args); err != nil {
+						return nil, errors.Wrap(err, fmt.Sprintf("failed to get blueButtonId for cclfBeneficiaryId %s", beneID))
+					}
+					blueButtonIDs[cclfBeneficiary.MBI] = bbID
+				}
+				cclfBeneficiary.BlueButtonID = bbID
+			}
+
+			b, err := client.CollectPages(func(handle client.PageHandler) error {
+				return resource.FetchFiltered(bb, *args, cclfBeneficiary, handle)
+			})
+			if err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("failed to retrieve %s for cclfBeneficiaryId %s", args.ResourceType, beneID))
+			}
+
+			for _, entry := range b.Entries {
+				data := entry["resource"]
+				if data == nil {
+					continue
+				}
+				if len(args.Elements) > 0 {
+					if r, ok := data.(map[string]interface{}); ok {
+						data = resourcetypes.SubsetResource(r, args.ResourceType, args.Elements, args.BBBasePath)
+					}
+				}
+				entries = append(entries, fhirmodels.BundleEntry{"resource": data})
+			}
+		}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CMSgov/bcda-app/bcda/client"
+	"github.com/CMSgov/bcda-app/bcda/constants"
+	"github.com/CMSgov/bcda-app/bcda/models"
+	fhirmodels "github.com/CMSgov/bcda-app/bcda/models/fhir"
+)
+
+func TestGetSyncEntries(t *testing.T) {
+	mbi := "1SA0A00AA00"
+	patientJSON := `{"entry":[{"resource":{"id":"-1000","identifier":[{"system":"http://hl7.org/fhir/sid/us-mbi","value":"1SA0A00AA00"},{"system":"https://bluebutton.cms.gov/resources/variables/bene_id","value":"-1000"}]}}]}`
+	benes := []*models.CCLFBeneficiary{{ID: 1, MBI: mbi}}
+
+	bbc := &client.MockBlueButtonClient{}
+	bbc.On("GetPatientByMbi", mbi).Return(patientJSON, nil).Once()
+	patientArgs := models.JobEnqueueArgs{ResourceType: "Patient", BeneficiaryIDs: []string{"1"}, DataType: constants.Adjudicated}
+	bbc.On("GetPatient", patientArgs, "-1000").Return(&fhirmodels.Bundle{Entries: []fhirmodels.BundleEntry{
+		{"resource": map[string]interface{}{"resourceType": "Patient", "id": "-1000", "gender": "female"}},
+	}}, nil)
+	coverageArgs := models.JobEnqueueArgs{ResourceType: "Coverage", BeneficiaryIDs: []string{"1"}, DataType: constants.Adjudicated, Elements: []string{"id"}}
+	bbc.On("GetCoverage", coverageArgs, "-1000").Return(&fhirmodels.Bundle{Entries: []fhirmodels.BundleEntry{
+		{"resource": map[string]interface{}{"resourceType": "Coverage", "id": "part-a", "status": "active", "period": "2020"}},
+		{"search": map[string]interface{}{"mode": "match"}},
+	}}, nil)
+	claimArgs := models.JobEnqueueArgs{ResourceType: "Claim", BeneficiaryIDs: []string{"1"}, DataType: constants.PartiallyAdjudicated}
+	bbc.On("GetClaim", claimArgs, mbi, client.ClaimsWindow{}).Return(&fhirmodels.Bundle{Entries: []fhirmodels.BundleEntry{
+		{"resource": map[string]interface{}{"resourceType": "Claim", "id": "c1"}},
+	}}, nil)
+
+	entries, err := getSyncEntries(bbc, benes, []*models.JobEnqueueArgs{&patientArgs, &coverageArgs, &claimArgs})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "female", entries[0]["resource"].(map[string]interface{})["gender"])
+	coverage := entries[1]["resource"].(map[string]interface{})
+	assert.Equal(t, "active", coverage["status"])
+	assert.NotContains(t, coverage, "period")
+	assert.Equal(t, "c1", entries[2]["resource"].(map[string]interface{})["id"])
+	// The BlueButton ID is only looked up once per beneficiary
+	bbc.AssertExpectations(t)
+
+	_, err = getSyncEntries(bbc, benes, []*models.JobEnqueueArgs{{ResourceType: "Patient", BeneficiaryIDs: []string{"2"}}})
+	assert.EqualError(t, err, "unexpected cclfBeneficiaryId 2")
+}
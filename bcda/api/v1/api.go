@@ -3,12 +3,15 @@ package v1
 import (
 	"bytes"
 	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,8 +21,8 @@ import (
 	"github.com/CMSgov/bcda-app/bcda/auth"
 	"github.com/CMSgov/bcda-app/bcda/constants"
 	"github.com/CMSgov/bcda-app/bcda/health"
+	"github.com/CMSgov/bcda-app/bcda/resourcetypes"
 	"github.com/CMSgov/bcda-app/bcda/responseutils"
-	"github.com/CMSgov/bcda-app/bcda/service"
 	"github.com/CMSgov/bcda-app/bcda/servicemux"
 	"github.com/CMSgov/bcda-app/conf"
 	"github.com/CMSgov/bcda-app/log"
@@ -28,15 +31,10 @@ import (
 var h *api.Handler
 
 func init() {
-	resources, ok := service.GetDataTypes([]string{
-		"Patient",
-		"Coverage",
-		"ExplanationOfBenefit",
-		"Observation",
-	}...)
+	dataTypes, ok := resourcetypes.GetDataTypes(resourcetypes.GetResourceNames("v1")...)
 
 	if ok {
-		h = api.NewHandler(resources, "/v1/fhir", "v1")
+		h = api.NewHandler(dataTypes, "/v1/fhir", "v1")
 	} else {
 		panic("Failed to configure resource DataTypes")
 	}
@@ -75,6 +73,8 @@ swagger:route GET /api/v1/Patient/$export bulkData bulkPatientRequest
 
 Initiates a job to collect data from the Blue Button API for your ACO. Supported resource types are Patient, Coverage, and ExplanationOfBenefit.
 
+To retrieve data for a small number of beneficiaries without creating a job, omit the Prefer header and supply their MBIs via the `patient` parameter.  The data is returned in the response as a FHIR searchset Bundle.
+
 Produces:
 - application/fhir+json
 
@@ -84,6 +84,7 @@ Security:
 
 Responses:
 
+	200: SyncExportResponse
 	202: BulkRequestResponse
 	400: badRequestResponse
 	401: invalidCredentials
@@ -94,6 +95,38 @@ func BulkPatientRequest(w http.ResponseWriter, r *http.Request) {
 	h.BulkPatientRequest(w, r)
 }
 
+/*
+swagger:route POST /api/v1/Patient/$export bulkData bulkPatientRequestPost
+
+# Start FHIR STU3 data export for all supported resource types using a FHIR Parameters resource
+
+Initiates a job to collect data from the Blue Button API for your ACO. The export parameters (`_type`, `_since`, `_typeFilter`, `_elements`, and `_outputFormat`) are supplied as a FHIR Parameters resource in the request body.  They may also be supplied in the query string, but a parameter cannot be supplied in both.
+
+To retrieve data for a small number of beneficiaries without creating a job, omit the Prefer header and supply their MBIs via the `patient` parameter.  The data is returned in the response as a FHIR searchset Bundle.
+
+Consumes:
+- application/fhir+json
+
+Produces:
+- application/fhir+json
+
+Security:
+
+	bearer_token:
+
+Responses:
+
+	200: SyncExportResponse
+	202: BulkRequestResponse
+	400: badRequestResponse
+	401: invalidCredentials
+	429: tooManyRequestsResponse
+	500: errorResponse
+*/
+func BulkPatientRequestPost(w http.ResponseWriter, r *http.Request) {
+	h.BulkPatientRequest(w, r)
+}
+
 /*
 		swagger:route GET /api/v1/Group/{groupId}/$export bulkData bulkGroupRequest
 
@@ -122,6 +155,35 @@ func BulkGroupRequest(w http.ResponseWriter, r *http.Request) {
 	h.BulkGroupRequest(w, r)
 }
 
+/*
+swagger:route POST /api/v1/Group/{groupId}/$export bulkData bulkGroupRequestPost
+
+# Start FHIR STU3 data export (for the specified group identifier) using a FHIR Parameters resource
+
+Initiates a job to collect data from the Blue Button API for your ACO. Behaves the same as the GET request, except that the export parameters are supplied as a FHIR Parameters resource in the request body.  They may also be supplied in the query string, but a parameter cannot be supplied in both.
+
+Consumes:
+- application/fhir+json
+
+Produces:
+- application/fhir+json
+
+Security:
+
+	bearer_token:
+
+Responses:
+
+	202: BulkRequestResponse
+	400: badRequestResponse
+	401: invalidCredentials
+	429: tooManyRequestsResponse
+	500: errorResponse
+*/
+func BulkGroupRequestPost(w http.ResponseWriter, r *http.Request) {
+	h.BulkGroupRequest(w, r)
+}
+
 /*
 swagger:route GET /api/v1/jobs/{jobId} job jobStatus
 
@@ -192,19 +254,6 @@ func JobsStatus(w http.ResponseWriter, r *http.Request) {
 	h.JobsStatus(w, r)
 }
 
-type gzipResponseWriter struct {
-	io.Writer
-	http.ResponseWriter
-}
-
-func (w gzipResponseWriter) Write(b []byte) (int, error) {
-	int, err := w.Writer.Write(b)
-	if err != nil {
-		log.API.Errorf("Error encountered in writing bytes with gzip writer: %s", err.Error())
-	}
-	return int, err
-}
-
 /*
 swagger:route DELETE /api/v1/jobs/{jobId} job deleteJob
 
@@ -234,6 +283,34 @@ func DeleteJob(w http.ResponseWriter, r *http.Request) {
 	h.DeleteJob(w, r)
 }
 
+/*
+swagger:route POST /api/v1/jobs/{jobId}/$retry job retryJob
+
+# Retry the failed beneficiaries of a job
+
+Initiates a job to collect data for the beneficiaries listed in the error files of a completed job. The job uses the same resource types, `_since`, and claims window as the completed job, so its data can be merged with the completed job's data.
+
+Produces:
+- application/fhir+json
+
+Schemes: http, https
+
+Security:
+
+	bearer_token:
+
+Responses:
+
+	202: BulkRequestResponse
+	400: badRequestResponse
+	401: invalidCredentials
+	404: notFoundResponse
+	500: errorResponse
+*/
+func RetryJob(w http.ResponseWriter, r *http.Request) {
+	h.RetryJob(w, r)
+}
+
 /*
 swagger:route GET /api/v1/attribution_status attributionStatus attributionStatus
 
@@ -259,12 +336,41 @@ func AttributionStatus(w http.ResponseWriter, r *http.Request) {
 	h.AttributionStatus(w, r)
 }
 
+/*
+swagger:route GET /api/v1/attribution_delta attributionDelta attributionDelta
+
+# Get attribution changes
+
+Returns a FHIR Group containing the beneficiaries that were added to or removed from the ACO's attribution by the latest attribution file. Added beneficiaries are active members whose period starts at the file's timestamp, and removed beneficiaries are inactive members whose period ends at the file's timestamp. The number of added, removed, and retained beneficiaries is reported in an extension.
+
+Produces:
+- application/fhir+json
+
+Schemes: http, https
+
+Security:
+
+	bearer_token:
+
+Responses:
+
+	200: AttributionDeltaResponse
+	401: invalidCredentials
+	404: notFoundResponse
+	500: errorResponse
+*/
+func AttributionDelta(w http.ResponseWriter, r *http.Request) {
+	h.AttributionDelta(w, r)
+}
+
 /*
 swagger:route GET /data/{jobId}/{filename} job serveData
 
 # Get data file
 
-Returns the NDJSON file of data generated by an export job.  Will be in the format <UUID>.ndjson.  Get the full value from the job status response
+Returns the NDJSON (or Parquet) file of data generated by an export job.  Will be in the format <UUID>.ndjson (or <UUID>.parquet).  Get the full value from the job status response
+
+Interrupted downloads can be resumed with the Range and If-Range headers.  Ranges always refer to the file as it is stored, which is gzip encoded, so range responses are served with Content-Encoding gzip.
 
 Produces:
 - application/fhir+json
@@ -278,9 +384,11 @@ Security:
 Responses:
 
 	200: FileNDJSON
+	206: FileNDJSON
 	400: badRequestResponse
 	401: invalidCredentials
 	404: notFoundResponse
+	416: rangeNotSatisfiableResponse
 	500: errorResponse
 */
 func ServeData(w http.ResponseWriter, r *http.Request) {
@@ -292,6 +400,7 @@ func ServeData(w http.ResponseWriter, r *http.Request) {
 	encoded, err := isGzipEncoded(filePath)
 	if err != nil {
 		writeServeDataFailure(err, w)
+		return
 	}
 
 	var useGZIP bool
@@ -301,46 +410,128 @@ func ServeData(w http.ResponseWriter, r *http.Request) {
 			break
 		}
 	}
-	w.Header().Set(constants.ContentType, "application/fhir+ndjson")
-	if useGZIP {
-		w.Header().Set("Content-Encoding", "gzip")
-		gz := gzip.NewWriter(w)
-		defer gz.Close()
-
-		gzw := gzipResponseWriter{Writer: gz, ResponseWriter: w}
-		if encoded {
-			http.ServeFile(w, r, filePath)
-		} else {
-			http.ServeFile(gzw, r, filePath)
-		}
 
-	} else {
+	// Byte ranges are only meaningful if every request for the file returns the same bytes,
+	// so range requests are always served from the stored file without being transformed.
+	rangeRequest := r.Header.Get("Range") != ""
+
+	digest := fileDigest(r, jobID, fileName)
+	w.Header().Set(constants.ContentType, api.DataFileContentType(fileName))
+	switch {
+	case encoded && (useGZIP || rangeRequest):
+		// The stored bytes are served as-is, so the digest of the compressed file applies to the response
+		w.Header().Set("Content-Encoding", "gzip")
+		setDigestHeaders(w, digest)
+		serveStoredFile(w, r, filePath)
+	case encoded:
 		log.API.Warnf("API request to serve data is being made without gzip for file %s for jobId %s", fileName, jobID)
-		if encoded {
-			//We'll do the following: 1. Open file, 2. De-compress it, 3. Serve it up.
-			file, err := os.Open(filePath) // #nosec G304
-			if err != nil {
-				writeServeDataFailure(err, w)
-				return
-			}
-			defer file.Close() //#nosec G307
-			gzipReader, err := gzip.NewReader(file)
-			if err != nil {
-				writeServeDataFailure(err, w)
-				return
-			}
-			defer gzipReader.Close()
-			_, err = io.Copy(w, gzipReader) // #nosec G110
-			if err != nil {
-				writeServeDataFailure(err, w)
-				return
-			}
-		} else {
-			http.ServeFile(w, r, filePath)
+		if digest != "" {
+			// The response is the decompressed file, which is semantically (but not byte-for-byte) equivalent
+			w.Header().Set("ETag", fmt.Sprintf(`W/"%s"`, digest))
 		}
+		w.Header().Set("Accept-Ranges", "none")
+		serveDecompressedFile(w, filePath)
+	case useGZIP && !rangeRequest:
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Accept-Ranges", "none")
+		serveCompressedFile(w, filePath)
+	default:
+		if !useGZIP {
+			log.API.Warnf("API request to serve data is being made without gzip for file %s for jobId %s", fileName, jobID)
+		}
+		serveStoredFile(w, r, filePath)
+	}
+}
+
+// serveStoredFile serves the file exactly as it is stored. http.ServeContent handles the
+// Range, If-Range and conditional request headers and sets an accurate Content-Length.
+func serveStoredFile(w http.ResponseWriter, r *http.Request, filePath string) {
+	file, err := os.Open(filePath) // #nosec G304
+	if err != nil {
+		writeServeDataFailure(err, w)
+		return
+	}
+	defer file.Close() //#nosec G307
+
+	info, err := file.Stat()
+	if err != nil {
+		writeServeDataFailure(err, w)
+		return
+	}
+	// http.ServeContent does not set the Content-Length of a full response when Content-Encoding is set,
+	// but it is accurate here since the stored bytes are sent as-is. Range responses override it.
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size(), 10))
+	http.ServeContent(w, r, "", info.ModTime(), file)
+}
+
+// serveDecompressedFile decompresses a gzip encoded file for clients that do not accept gzip
+func serveDecompressedFile(w http.ResponseWriter, filePath string) {
+	file, err := os.Open(filePath) // #nosec G304
+	if err != nil {
+		writeServeDataFailure(err, w)
+		return
+	}
+	defer file.Close() //#nosec G307
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		writeServeDataFailure(err, w)
+		return
+	}
+	defer gzipReader.Close()
+	_, err = io.Copy(w, gzipReader) // #nosec G110
+	if err != nil {
+		writeServeDataFailure(err, w)
+		return
+	}
+}
+
+// serveCompressedFile compresses a file that was not stored gzip encoded
+func serveCompressedFile(w http.ResponseWriter, filePath string) {
+	file, err := os.Open(filePath) // #nosec G304
+	if err != nil {
+		writeServeDataFailure(err, w)
+		return
+	}
+	defer file.Close() //#nosec G307
+
+	gz := gzip.NewWriter(w)
+	defer gz.Close()
+	if _, err = io.Copy(gz, file); err != nil {
+		log.API.Errorf("Error encountered in writing bytes with gzip writer: %s", err.Error())
 	}
 }
 
+// fileDigest returns the hex encoded SHA-256 digest of the compressed data file recorded by the worker.
+// Files generated before digests were recorded do not have one.
+func fileDigest(r *http.Request, jobID, fileName string) string {
+	id, err := strconv.ParseUint(jobID, 10, 64)
+	if err != nil {
+		return ""
+	}
+
+	jobKey, err := h.Svc.GetJobKey(r.Context(), uint(id), fileName)
+	if err != nil {
+		log.API.Warnf("Unable to find digest for file %s for jobId %s: %s", fileName, jobID, err.Error())
+		return ""
+	}
+	return jobKey.Digest
+}
+
+// setDigestHeaders allows callers to verify the integrity of the served file
+func setDigestHeaders(w http.ResponseWriter, digest string) {
+	if digest == "" {
+		return
+	}
+
+	sum, err := hex.DecodeString(digest)
+	if err != nil {
+		log.API.Warnf("Invalid digest %s: %s", digest, err.Error())
+		return
+	}
+	w.Header().Set("ETag", fmt.Sprintf(`"%s"`, digest))
+	w.Header().Set("Digest", "sha-256="+base64.StdEncoding.EncodeToString(sum))
+}
+
 // This function is not necessary, but helps meet the sonarQube quality gates
 func writeServeDataFailure(err error, w http.ResponseWriter) {
 	log.API.Error(err)
@@ -394,7 +585,7 @@ func Metadata(w http.ResponseWriter, r *http.Request) {
 		scheme = "https"
 	}
 	host := fmt.Sprintf("%s://%s", scheme, r.Host)
-	statement := responseutils.CreateCapabilityStatement(dt, constants.Version, host)
+	statement := responseutils.CreateCapabilityStatement(dt, constants.Version, host, resourcetypes.GetResourceNames("v1")...)
 	responseutils.WriteCapabilityStatement(r.Context(), statement, w)
 }
 
@@ -433,8 +624,28 @@ func GetVersion(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// HealthCheck reports the status of the services the API depends on. Blue Button is only reported through the
+// state of its circuit breaker so that frequent health checks do not send requests to Blue Button.
 func HealthCheck(w http.ResponseWriter, r *http.Request) {
-	m := make(map[string]string)
+	m, ok := checkDependencies()
+	// Blue Button availability is informational and does not affect the status code
+	m["bluebutton"], _ = health.BlueButtonCircuitState()
+
+	writeHealthCheck(w, m, ok)
+}
+
+// DeepHealthCheck reports the same services as HealthCheck, but also sends a request to Blue Button to verify
+// that it is reachable. It should only be used when diagnosing an outage.
+func DeepHealthCheck(w http.ResponseWriter, r *http.Request) {
+	m, ok := checkDependencies()
+	bbStatus, bbOK := health.IsBlueButtonOK()
+	m["bluebutton"] = bbStatus
+
+	writeHealthCheck(w, m, ok && bbOK)
+}
+
+func checkDependencies() (m map[string]string, ok bool) {
+	m = make(map[string]string)
 
 	dbStatus, dbOK := health.IsDatabaseOK()
 	ssasStatus, ssasOK := health.IsSsasOK()
@@ -442,7 +653,11 @@ func HealthCheck(w http.ResponseWriter, r *http.Request) {
 	m["database"] = dbStatus
 	m["ssas"] = ssasStatus
 
-	if !dbOK || !ssasOK {
+	return m, dbOK && ssasOK
+}
+
+func writeHealthCheck(w http.ResponseWriter, m map[string]string, ok bool) {
+	if !ok {
 		w.WriteHeader(http.StatusBadGateway)
 	} else {
 		w.WriteHeader(http.StatusOK)
@@ -3,14 +3,18 @@ package v1
 import (
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -162,12 +166,13 @@ func (s *APITestSuite) TestJobStatusCompleted() {
 	postgrestest.CreateJobs(s.T(), s.db, &j)
 
 	var expectedUrls []string
+	digest := strings.Repeat("ab", sha256.Size)
 	for i := 1; i <= 10; i++ {
 		fileName := fmt.Sprintf("%s.ndjson", uuid.NewRandom().String())
 		expectedurl := fmt.Sprintf("%s/%s/%s", constants.ExpectedTestUrl, fmt.Sprint(j.ID), fileName)
 		expectedUrls = append(expectedUrls, expectedurl)
 		postgrestest.CreateJobKeys(s.T(), s.db,
-			models.JobKey{JobID: j.ID, FileName: fileName, ResourceType: "ExplanationOfBenefit"})
+			models.JobKey{JobID: j.ID, FileName: fileName, ResourceType: "ExplanationOfBenefit", ResourceCount: 5, UncompressedSize: 2048, CompressedSize: 512, Digest: digest})
 	}
 
 	req := s.createJobStatusRequest(acoUnderTest, j.ID)
@@ -184,10 +189,19 @@ func (s *APITestSuite) TestJobStatusCompleted() {
 	if err != nil {
 		s.T().Error(err)
 	}
+	assert.Contains(s.T(), s.rr.Body.String(), `"valueInteger64":"2048"`)
 
 	assert.Equal(s.T(), j.RequestURL, rb.RequestURL)
 	assert.Equal(s.T(), true, rb.RequiresAccessToken)
 	assert.Equal(s.T(), "ExplanationOfBenefit", rb.Files[0].Type)
+	assert.Equal(s.T(), "application/fhir+ndjson", rb.Files[0].ContentType)
+	assert.Equal(s.T(), int64(5), rb.Files[0].Count)
+	uncompressedSize, compressedSize := int64(2048), int64(512)
+	assert.Equal(s.T(), []api.FileItemExtension{
+		{URL: api.UncompressedSizeExtensionURL, ValueInteger64: &uncompressedSize},
+		{URL: api.CompressedSizeExtensionURL, ValueInteger64: &compressedSize},
+		{URL: api.DigestExtensionURL, ValueString: digest},
+	}, rb.Files[0].Extension)
 	assert.Equal(s.T(), len(expectedUrls), len(rb.Files))
 	// Order of these values is impossible to know so this is the only way
 	for _, fileItem := range rb.Files {
@@ -421,6 +435,100 @@ func (s *APITestSuite) TestServeData() {
 	}
 }
 
+func (s *APITestSuite) TestServeDataRange() {
+	j := models.Job{
+		ACOID:      acoUnderTest,
+		RequestURL: constants.V1Path + constants.PatientEOBPath,
+		Status:     models.JobStatusCompleted,
+	}
+	postgrestest.CreateJobs(s.T(), s.db, &j)
+	jobID := fmt.Sprint(j.ID)
+
+	dataDir := s.T().TempDir()
+	conf.SetEnv(s.T(), "FHIR_PAYLOAD_DIR", dataDir)
+	assert.NoError(s.T(), os.Mkdir(filepath.Join(dataDir, jobID), 0750))
+
+	files := make(map[string][]byte)
+	for _, fileName := range []string{"test_gzip_encoded.ndjson", "test_no_encoding.ndjson"} {
+		b, err := os.ReadFile(filepath.Join("../../../shared_files/gzip_feature_test", fileName))
+		assert.NoError(s.T(), err)
+		assert.NoError(s.T(), os.WriteFile(filepath.Join(dataDir, jobID, fileName), b, 0600))
+		files[fileName] = b
+	}
+	gzipFile := files["test_gzip_encoded.ndjson"]
+	sum := sha256.Sum256(gzipFile)
+	digest := hex.EncodeToString(sum[:])
+	postgrestest.CreateJobKeys(s.T(), s.db,
+		models.JobKey{JobID: j.ID, FileName: "test_gzip_encoded.ndjson", ResourceType: "ExplanationOfBenefit", Digest: digest})
+
+	info, err := os.Stat(filepath.Join(dataDir, jobID, "test_gzip_encoded.ndjson"))
+	assert.NoError(s.T(), err)
+	lastModified := info.ModTime().UTC().Format(http.TimeFormat)
+	size := len(gzipFile)
+
+	tests := []struct {
+		name        string
+		fileName    string
+		headers     map[string]string
+		expStatus   int
+		expBody     []byte
+		expEncoding string
+	}{
+		{"range without gzip", "test_gzip_encoded.ndjson", map[string]string{"Range": "bytes=0-9"}, http.StatusPartialContent, gzipFile[:10], "gzip"},
+		{"range with gzip", "test_gzip_encoded.ndjson", map[string]string{"Range": "bytes=10-", "Accept-Encoding": "gzip"}, http.StatusPartialContent, gzipFile[10:], "gzip"},
+		{"if-range matching etag", "test_gzip_encoded.ndjson", map[string]string{"Range": "bytes=10-", "If-Range": fmt.Sprintf(`"%s"`, digest)}, http.StatusPartialContent, gzipFile[10:], "gzip"},
+		{"if-range matching date", "test_gzip_encoded.ndjson", map[string]string{"Range": "bytes=10-", "If-Range": lastModified}, http.StatusPartialContent, gzipFile[10:], "gzip"},
+		{"if-range stale etag", "test_gzip_encoded.ndjson", map[string]string{"Range": "bytes=10-", "If-Range": `"stale"`}, http.StatusOK, gzipFile, "gzip"},
+		{"unsatisfiable range", "test_gzip_encoded.ndjson", map[string]string{"Range": fmt.Sprintf("bytes=%d-", size)}, http.StatusRequestedRangeNotSatisfiable, nil, ""},
+		{"range on unencoded file", "test_no_encoding.ndjson", map[string]string{"Range": "bytes=0-9", "Accept-Encoding": "gzip"}, http.StatusPartialContent, files["test_no_encoding.ndjson"][:10], ""},
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", fmt.Sprintf("/data/%s/%s", jobID, tt.fileName), nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("jobID", jobID)
+			rctx.URLParams.Add("fileName", tt.fileName)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(ServeData).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expStatus, rr.Code)
+			assert.Equal(t, tt.expEncoding, rr.Header().Get("Content-Encoding"))
+			if tt.expStatus == http.StatusRequestedRangeNotSatisfiable {
+				assert.Equal(t, fmt.Sprintf("bytes */%d", size), rr.Header().Get("Content-Range"))
+				return
+			}
+			assert.Equal(t, tt.expBody, rr.Body.Bytes())
+			assert.Equal(t, fmt.Sprint(len(tt.expBody)), rr.Header().Get("Content-Length"))
+			if tt.fileName == "test_gzip_encoded.ndjson" {
+				assert.Equal(t, fmt.Sprintf(`"%s"`, digest), rr.Header().Get("ETag"))
+				assert.Equal(t, "sha-256="+base64.StdEncoding.EncodeToString(sum[:]), rr.Header().Get("Digest"))
+			}
+		})
+	}
+
+	s.T().Run("decompressed file is not rangeable", func(t *testing.T) {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/data/%s/test_gzip_encoded.ndjson", jobID), nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("jobID", jobID)
+		rctx.URLParams.Add("fileName", "test_gzip_encoded.ndjson")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ServeData).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "none", rr.Header().Get("Accept-Ranges"))
+		assert.Equal(t, fmt.Sprintf(`W/"%s"`, digest), rr.Header().Get("ETag"))
+		assert.Empty(t, rr.Header().Get("Digest"))
+	})
+}
+
 func (s *APITestSuite) TestMetadata() {
 	req := httptest.NewRequest("GET", "/api/v1/metadata", nil)
 	req.TLS = &tls.ConnectionState{}
@@ -561,6 +669,31 @@ func (s *APITestSuite) TestHealthCheck() {
 	handler := http.HandlerFunc(HealthCheck)
 	handler.ServeHTTP(s.rr, req)
 	assert.Equal(s.T(), http.StatusOK, s.rr.Code)
+
+	var resp map[string]string
+	err = json.Unmarshal(s.rr.Body.Bytes(), &resp)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "ok", resp["database"])
+	// Only the circuit breaker state is reported
+	assert.Equal(s.T(), "ok", resp["bluebutton"])
+}
+
+func (s *APITestSuite) TestDeepHealthCheck() {
+	req, err := http.NewRequest("GET", "/_health/deep", nil)
+	assert.Nil(s.T(), err)
+	handler := http.HandlerFunc(DeepHealthCheck)
+	handler.ServeHTTP(s.rr, req)
+
+	var resp map[string]string
+	err = json.Unmarshal(s.rr.Body.Bytes(), &resp)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "ok", resp["database"])
+	assert.Contains(s.T(), resp, "bluebutton")
+	if resp["bluebutton"] == "ok" {
+		assert.Equal(s.T(), http.StatusOK, s.rr.Code)
+	} else {
+		assert.Equal(s.T(), http.StatusBadGateway, s.rr.Code)
+	}
 }
 func (s *APITestSuite) TestAuthInfo() {
 	req, err := http.NewRequest("GET", "/_auth", nil)
@@ -15,7 +15,8 @@ import (
 
 	api "github.com/CMSgov/bcda-app/bcda/api"
 	"github.com/CMSgov/bcda-app/bcda/constants"
-	"github.com/CMSgov/bcda-app/bcda/service"
+	"github.com/CMSgov/bcda-app/bcda/resourcetypes"
+	responseutilsv2 "github.com/CMSgov/bcda-app/bcda/responseutils/v2"
 	"github.com/CMSgov/bcda-app/bcda/servicemux"
 	"github.com/CMSgov/bcda-app/conf"
 	"github.com/CMSgov/bcda-app/log"
@@ -29,16 +30,10 @@ var (
 func init() {
 	var err error
 
-	resources, ok := service.GetDataTypes([]string{
-		"Patient",
-		"Coverage",
-		"ExplanationOfBenefit",
-		"Claim",
-		"ClaimResponse",
-	}...)
+	dataTypes, ok := resourcetypes.GetDataTypes(resourcetypes.GetResourceNames("v2")...)
 
 	if ok {
-		h = api.NewHandler(resources, "/v2/fhir", "v2")
+		h = api.NewHandler(dataTypes, "/v2/fhir", "v2")
 	} else {
 		panic("Failed to configure resource DataTypes")
 	}
@@ -84,6 +79,8 @@ Start FHIR R4 data export for all supported resource types.
 
 Initiates a job to collect data from the Blue Button API for your ACO. Supported resource types are Patient, Coverage, and ExplanationOfBenefit.
 
+To retrieve data for a small number of beneficiaries without creating a job, omit the Prefer header and supply their MBIs via the `patient` parameter.  The data is returned in the response as a FHIR searchset Bundle.
+
 Produces:
 - application/fhir+json
 
@@ -93,6 +90,7 @@ Security:
 
 Responses:
 
+	200: SyncExportResponse
 	202: BulkRequestResponse
 	400: badRequestResponse
 	401: invalidCredentials
@@ -103,6 +101,38 @@ func BulkPatientRequest(w http.ResponseWriter, r *http.Request) {
 	h.BulkPatientRequest(w, r)
 }
 
+/*
+swagger:route POST /api/v2/Patient/$export bulkDataV2 bulkPatientRequestPostV2
+
+# Start FHIR R4 data export for all supported resource types using a FHIR Parameters resource
+
+Initiates a job to collect data from the Blue Button API for your ACO. The export parameters (`_type`, `_since`, `_typeFilter`, `_elements`, and `_outputFormat`) are supplied as a FHIR Parameters resource in the request body.  They may also be supplied in the query string, but a parameter cannot be supplied in both.
+
+To retrieve data for a small number of beneficiaries without creating a job, omit the Prefer header and supply their MBIs via the `patient` parameter.  The data is returned in the response as a FHIR searchset Bundle.
+
+Consumes:
+- application/fhir+json
+
+Produces:
+- application/fhir+json
+
+Security:
+
+	bearer_token:
+
+Responses:
+
+	200: SyncExportResponse
+	202: BulkRequestResponse
+	400: badRequestResponse
+	401: invalidCredentials
+	429: tooManyRequestsResponse
+	500: errorResponse
+*/
+func BulkPatientRequestPost(w http.ResponseWriter, r *http.Request) {
+	h.BulkPatientRequest(w, r)
+}
+
 /*
 		swagger:route GET /api/v2/Group/{groupId}/$export bulkDataV2 bulkGroupRequestV2
 
@@ -131,6 +161,35 @@ func BulkGroupRequest(w http.ResponseWriter, r *http.Request) {
 	h.BulkGroupRequest(w, r)
 }
 
+/*
+swagger:route POST /api/v2/Group/{groupId}/$export bulkDataV2 bulkGroupRequestPostV2
+
+# Start FHIR R4 data export (for the specified group identifier) using a FHIR Parameters resource
+
+Initiates a job to collect data from the Blue Button API for your ACO. Behaves the same as the GET request, except that the export parameters are supplied as a FHIR Parameters resource in the request body.  They may also be supplied in the query string, but a parameter cannot be supplied in both.
+
+Consumes:
+- application/fhir+json
+
+Produces:
+- application/fhir+json
+
+Security:
+
+	bearer_token:
+
+Responses:
+
+	202: BulkRequestResponse
+	400: badRequestResponse
+	401: invalidCredentials
+	429: tooManyRequestsResponse
+	500: errorResponse
+*/
+func BulkGroupRequestPost(w http.ResponseWriter, r *http.Request) {
+	h.BulkGroupRequest(w, r)
+}
+
 /*
 swagger:route GET /api/v2/jobs/{jobId} jobV2 jobStatusV2
 
@@ -230,6 +289,34 @@ func DeleteJob(w http.ResponseWriter, r *http.Request) {
 	h.DeleteJob(w, r)
 }
 
+/*
+swagger:route POST /api/v2/jobs/{jobId}/$retry jobV2 retryJobV2
+
+# Retry the failed beneficiaries of a job
+
+Initiates a job to collect data for the beneficiaries listed in the error files of a completed job. The job uses the same resource types, `_since`, and claims window as the completed job, so its data can be merged with the completed job's data.
+
+Produces:
+- application/fhir+json
+
+Schemes: http, https
+
+Security:
+
+	bearer_token:
+
+Responses:
+
+	202: BulkRequestResponse
+	400: badRequestResponse
+	401: invalidCredentials
+	404: notFoundResponse
+	500: errorResponse
+*/
+func RetryJob(w http.ResponseWriter, r *http.Request) {
+	h.RetryJob(w, r)
+}
+
 /*
 swagger:route GET /api/v2/attribution_status attributionStatusV2 attributionStatus
 
@@ -255,6 +342,63 @@ func AttributionStatus(w http.ResponseWriter, r *http.Request) {
 	h.AttributionStatus(w, r)
 }
 
+/*
+swagger:route GET /api/v2/attribution_delta attributionDeltaV2 attributionDelta
+
+# Get attribution changes
+
+Returns a FHIR Group containing the beneficiaries that were added to or removed from the ACO's attribution by the latest attribution file. Added beneficiaries are active members whose period starts at the file's timestamp, and removed beneficiaries are inactive members whose period ends at the file's timestamp. The number of added, removed, and retained beneficiaries is reported in an extension.
+
+Produces:
+- application/fhir+json
+
+Schemes: http, https
+
+Security:
+
+	bearer_token:
+
+Responses:
+
+	200: AttributionDeltaResponse
+	401: invalidCredentials
+	404: notFoundResponse
+	500: errorResponse
+*/
+func AttributionDelta(w http.ResponseWriter, r *http.Request) {
+	h.AttributionDelta(w, r)
+}
+
+/*
+swagger:route GET /api/v2/Group/{groupId} groupV2 readGroupV2
+
+# Get attributed beneficiaries
+
+Returns a FHIR Group describing the beneficiaries attributed to the ACO by the latest attribution file for the group identifier. The only Group identifiers supported by the system are `all` and `runout`. Suppressed beneficiaries are excluded.
+
+The quantity is the number of attributed beneficiaries, and the name, timestamp, and performance year period of the attribution file are reported in an extension. Members are only included when `_count` is supplied, in which case up to `_count` members are returned starting at `_offset`. Members are ordered consistently between requests so that pages do not overlap. Each member references a Patient by MBI.
+
+Produces:
+- application/fhir+json
+
+Schemes: http, https
+
+Security:
+
+	bearer_token:
+
+Responses:
+
+	200: GroupResponse
+	400: badRequestResponse
+	401: invalidCredentials
+	404: notFoundResponse
+	500: errorResponse
+*/
+func Group(w http.ResponseWriter, r *http.Request) {
+	h.Group(w, r)
+}
+
 /*
 swagger:route GET /api/v2/metadata metadataV2 metadata
 
@@ -377,6 +521,15 @@ func Metadata(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
+	// Patient is already described along with the patient-export operation
+	var exportTypes []string
+	for _, resourceType := range resourcetypes.GetResourceNames("v2") {
+		if resourceType != "Patient" {
+			exportTypes = append(exportTypes, resourceType)
+		}
+	}
+	statement.Rest[0].Resource = append(statement.Rest[0].Resource, responseutilsv2.ExportResources(exportTypes...)...)
+
 	resource := &fhirresources.ContainedResource{
 		OneofResource: &fhirresources.ContainedResource_CapabilityStatement{CapabilityStatement: statement},
 	}
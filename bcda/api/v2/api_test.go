@@ -21,6 +21,7 @@ import (
 	"github.com/CMSgov/bcda-app/bcda/database"
 	"github.com/CMSgov/bcda-app/bcda/models"
 	"github.com/CMSgov/bcda-app/bcda/models/postgres/postgrestest"
+	"github.com/CMSgov/bcda-app/bcda/resourcetypes"
 	"github.com/CMSgov/bcda-app/bcda/service"
 	"github.com/CMSgov/bcda-app/bcda/web/middleware"
 	"github.com/CMSgov/bcda-app/conf"
@@ -483,7 +484,14 @@ func (s *APITestSuite) TestMetadataResponse() {
 	// Expecting an R4 response so we'll evaluate some fields to reflect that
 	assert.Equal(s.T(), fhircodes.FHIRVersionCode_V_4_0_1, cs.FhirVersion.Value)
 	assert.Equal(s.T(), 1, len(cs.Rest))
-	assert.Equal(s.T(), 2, len(cs.Rest[0].Resource))
+	// Patient and Group describe the export operations, followed by the other resource types that can be exported
+	assert.Equal(s.T(), 7, len(cs.Rest[0].Resource))
+	var exportTypes []fhircodes.ResourceTypeCode_Value
+	for _, r := range cs.Rest[0].Resource[2:] {
+		exportTypes = append(exportTypes, r.Type.Value)
+	}
+	assert.Equal(s.T(), []fhircodes.ResourceTypeCode_Value{fhircodes.ResourceTypeCode_EXPLANATION_OF_BENEFIT, fhircodes.ResourceTypeCode_COVERAGE,
+		fhircodes.ResourceTypeCode_CLAIM, fhircodes.ResourceTypeCode_CLAIM_RESPONSE, fhircodes.ResourceTypeCode_OBSERVATION}, exportTypes)
 	assert.Len(s.T(), cs.Instantiates, 2)
 	assert.Contains(s.T(), cs.Instantiates[0].Value, "/v2/fhir/metadata")
 	resourceData := []struct {
@@ -531,7 +539,7 @@ func (s *APITestSuite) TestResourceTypes() {
 		{"Supported type - default", nil, http.StatusAccepted},
 	}
 
-	resources, _ := service.GetDataTypes([]string{
+	dataTypes, _ := resourcetypes.GetDataTypes([]string{
 		"Patient",
 		"Coverage",
 		"ExplanationOfBenefit",
@@ -539,7 +547,7 @@ func (s *APITestSuite) TestResourceTypes() {
 		"ClaimResponse",
 	}...)
 
-	h := api.NewHandler(resources, "/v2/fhir", "v2")
+	h := api.NewHandler(dataTypes, "/v2/fhir", "v2")
 	mockSvc := &service.MockService{}
 
 	mockSvc.On("GetQueJobs", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]*models.JobEnqueueArgs{}, nil)
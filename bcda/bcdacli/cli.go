@@ -26,6 +26,7 @@ import (
 
 	"github.com/CMSgov/bcda-app/bcda/cclf"
 	cclfUtils "github.com/CMSgov/bcda-app/bcda/cclf/utils"
+	"github.com/CMSgov/bcda-app/bcda/client"
 	"github.com/CMSgov/bcda-app/bcda/constants"
 	"github.com/CMSgov/bcda-app/bcda/database"
 	"github.com/CMSgov/bcda-app/bcda/models"
@@ -35,6 +36,9 @@ import (
 	"github.com/CMSgov/bcda-app/bcda/suppression"
 	"github.com/CMSgov/bcda-app/bcda/utils"
 	"github.com/CMSgov/bcda-app/bcda/web"
+	"github.com/CMSgov/bcda-app/bcdaworker/queueing"
+	workerpg "github.com/CMSgov/bcda-app/bcdaworker/repository/postgres"
+	"github.com/CMSgov/bcda-app/bcdaworker/worker"
 	"github.com/CMSgov/bcda-app/conf"
 	"github.com/CMSgov/bcda-app/log"
 	"github.com/CMSgov/bcda-app/optout"
@@ -73,6 +77,7 @@ func setUpApp() *cli.App {
 	}
 	var acoName, acoCMSID, acoID, accessToken, acoSize, filePath, fileSource, s3Endpoint, assumeRoleArn, environment, groupID, groupName, ips, fileType, alrFile string
 	var httpPort, httpsPort int
+	var jobID uint
 	app.Commands = []cli.Command{
 		{
 			Name:  "start-api",
@@ -352,6 +357,51 @@ func setUpApp() *cli.App {
 				return err
 			},
 		},
+		{
+			Name:     "warm-bfd-patient-id-cache",
+			Category: constants.CliDataImpCategory,
+			Usage:    "Resolve and cache the BFD patient IDs of an ACO's beneficiaries from its latest CCLF8 file",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:        constants.CliCMSIDArg,
+					Usage:       constants.CliCMSIDDesc,
+					Destination: &acoCMSID,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				warmed, failed, err := warmBFDPatientIDCache(acoCMSID)
+				if err != nil {
+					return err
+				}
+				log.API.Infof("Completed BFD patient ID cache warm for %s. Resolved %d beneficiaries. Failed to resolve %d beneficiaries.", acoCMSID, warmed, failed)
+				fmt.Fprintf(app.Writer, "Completed BFD patient ID cache warm for %s. Resolved %d beneficiaries. Failed to resolve %d beneficiaries.", acoCMSID, warmed, failed)
+				return nil
+			},
+		},
+		{
+			Name:  "create-retry-job",
+			Usage: "Create a job that exports the beneficiaries that could not be exported by a completed job",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:        "job-id",
+					Usage:       "ID of the completed job",
+					Destination: &jobID,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				cfg, err := service.LoadConfig()
+				if err != nil {
+					return err
+				}
+				retryJobID, err := createRetryJob(jobID, db, service.NewService(r, cfg, ""), queueing.NewEnqueuer())
+				if err != nil {
+					return err
+				}
+				log.API.Infof("Created retry job %d for the failed beneficiaries of job %d", retryJobID, jobID)
+				fmt.Fprintf(app.Writer, "%d\n", retryJobID)
+				return nil
+			},
+		},
 		{
 			Name:     "generate-cclf-runout-files",
 			Category: constants.CliDataImpCategory,
@@ -682,6 +732,86 @@ const cclfPattern = `((?:T|P).*\.ZC[A-B0-9]*)Y(\d{2}\.D\d{6}\.T\d{7})`
 
 var cclfregex = regexp.MustCompile(cclfPattern)
 
+// warmBFDPatientIDCache resolves the BFD patient ID of every beneficiary in the ACO's latest
+// CCLF8 file so the first export after a CCLF import does not have to look them up.
+func warmBFDPatientIDCache(cmsID string) (warmed, failed int, err error) {
+	if cmsID == "" {
+		return 0, 0, errors.New("ACO CMS ID (--cms-id) must be provided")
+	}
+
+	ctx := log.NewStructuredLoggerEntry(log.API, context.Background())
+	file, err := r.GetLatestCCLFFile(ctx, cmsID, 8, "Completed", time.Time{}, time.Time{}, models.FileTypeDefault)
+	if err != nil {
+		return 0, 0, err
+	}
+	if file == nil {
+		return 0, 0, fmt.Errorf("no CCLF8 file found for CMS ID %s", cmsID)
+	}
+
+	mbis, err := r.GetCCLFBeneficiaryMBIs(ctx, file.ID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	bb, err := client.NewBlueButtonClient(client.NewConfig("/v1/fhir"))
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed to create Blue Button client")
+	}
+
+	warmed, failed = worker.WarmBlueButtonIDCache(ctx, workerpg.NewRepository(db), bb, cmsID, mbis)
+	return warmed, failed, nil
+}
+
+// createRetryJob creates and enqueues a job that exports the beneficiaries that could not be exported by the completed job.
+// The job is only committed once all of its queue jobs have been enqueued.
+func createRetryJob(jobID uint, db *sql.DB, svc service.Service, enq queueing.Enqueuer) (retryJobID uint, err error) {
+	if jobID == 0 {
+		return 0, errors.New("job ID (--job-id) must be provided")
+	}
+
+	ctx := log.NewStructuredLoggerEntry(log.API, context.Background())
+	retryJob, queJobs, err := svc.NewRetryJob(ctx, jobID)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to create retry job for job %d", jobID)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to start transaction")
+	}
+	defer func() {
+		if err != nil {
+			if err1 := tx.Rollback(); err1 != nil {
+				log.API.Warnf("Failed to rollback transaction %s", err1.Error())
+			}
+			return
+		}
+		if err = tx.Commit(); err != nil {
+			retryJobID, err = 0, errors.Wrapf(err, "failed to commit retry job for job %d", jobID)
+		}
+	}()
+
+	retryJob.ID, err = postgres.NewRepositoryTx(tx).CreateJob(ctx, *retryJob)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to create retry job for job %d", jobID)
+	}
+
+	id, err := safecast.ToInt(retryJob.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, j := range queJobs {
+		j.ID = id
+		priority := svc.GetJobPriority(j.CMSID, j.ResourceType, j.Since != "")
+		if err = enq.AddJob(ctx, *j, int(priority)); err != nil {
+			return 0, errors.Wrapf(err, "failed to enqueue %s for retry job %d", j.ResourceType, retryJob.ID)
+		}
+	}
+
+	return retryJob.ID, nil
+}
+
 func renameCCLF(name string) string {
 	return cclfregex.ReplaceAllString(name, "${1}R${2}")
 }
@@ -23,8 +23,10 @@ import (
 	"github.com/CMSgov/bcda-app/bcda/database"
 	"github.com/CMSgov/bcda-app/bcda/models"
 	"github.com/CMSgov/bcda-app/bcda/models/postgres/postgrestest"
+	"github.com/CMSgov/bcda-app/bcda/service"
 	"github.com/CMSgov/bcda-app/bcda/testUtils"
 	"github.com/CMSgov/bcda-app/bcda/utils"
+	mockEnq "github.com/CMSgov/bcda-app/bcdaworker/queueing/mocks"
 	"github.com/CMSgov/bcda-app/conf"
 	logger "github.com/CMSgov/bcda-app/log"
 	"github.com/pkg/errors"
@@ -34,6 +36,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"github.com/urfave/cli"
 )
@@ -400,7 +403,7 @@ func (s *CLITestSuite) TestImportCCLFDirectory() {
 	}
 
 	tests := []test{
-		{path: "../../shared_files/cclf/archives/valid/", err: errors.New("files skipped or failed import. See logs for more details"), expectedLogs: []string{"Successfully imported 6 files.", "Failed to import 0 files.", "Skipped 0 files."}},
+		{path: "../../shared_files/cclf/archives/valid/", err: errors.New("files skipped or failed import. See logs for more details"), expectedLogs: []string{"Successfully imported 7 files.", "Failed to import 0 files.", "Skipped 0 files."}},
 		{path: "../../shared_files/cclf/archives/invalid_bcd/", err: errors.New("failed to import 1 files"), expectedLogs: []string{"missing CCLF0 or CCLF8 file in zip", "", ""}},
 		{path: "../../shared_files/cclf/archives/skip/", err: errors.New("files failed to import or no files were imported. See logs for more details."), expectedLogs: []string{"Successfully imported 0 files.", "Failed to import 0 files.", "Skipped 1 files."}},
 	}
@@ -679,6 +682,79 @@ func (s *CLITestSuite) TestGenerateAlrData() {
 	assert.EqualError(s.T(), err, "no CCLF8 file found for CMS ID UNKNOWN_ACO")
 }
 
+func (s *CLITestSuite) TestWarmBFDPatientIDCache() {
+	err := s.testApp.Run([]string{"bcda", "warm-bfd-patient-id-cache"})
+	assert.EqualError(s.T(), err, "ACO CMS ID (--cms-id) must be provided")
+
+	// No CCLF file
+	err = s.testApp.Run([]string{"bcda", "warm-bfd-patient-id-cache", constants.CMSIDArg, "UNKNOWN_ACO"})
+	assert.EqualError(s.T(), err, "no CCLF8 file found for CMS ID UNKNOWN_ACO")
+}
+
+func (s *CLITestSuite) TestCreateRetryJob() {
+	job := &models.Job{ACOID: s.testACO.UUID, Status: models.JobStatusCompleted}
+	postgrestest.CreateJobs(s.T(), s.db, job)
+	defer postgrestest.DeleteJobByID(s.T(), s.db, job.ID)
+
+	tests := []struct {
+		name   string
+		jobID  uint
+		svcErr error
+		enqErr error
+		errMsg string
+	}{
+		{"Success", job.ID, nil, nil, ""},
+		{"MissingJobID", 0, nil, nil, "job ID (--job-id) must be provided"},
+		{"NotRetryable", job.ID, service.ErrJobNotRetryable, nil, fmt.Sprintf("failed to create retry job for job %d: %s", job.ID, service.ErrJobNotRetryable.Error())},
+		{"RetryInProgress", job.ID, service.ErrRetryInProgress, nil, fmt.Sprintf("failed to create retry job for job %d: %s", job.ID, service.ErrRetryInProgress.Error())},
+		{"EnqueueError", job.ID, nil, errors.New("queue unavailable"), ""},
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			queJobs := []*models.JobEnqueueArgs{
+				{CMSID: "A0001", ResourceType: "Patient", BeneficiaryIDs: []string{"1"}},
+				{CMSID: "A0001", ResourceType: "ExplanationOfBenefit", BeneficiaryIDs: []string{"2", "3"}, Since: "gt2020-02-13T08:00:00.000-05:00"},
+			}
+
+			svc := &service.MockService{}
+			if tt.svcErr != nil {
+				svc.On("NewRetryJob", testUtils.CtxMatcher, tt.jobID).Return(nil, nil, tt.svcErr)
+			} else {
+				retryJob := &models.Job{ACOID: s.testACO.UUID, Status: models.JobStatusPending, JobCount: len(queJobs), RetryOf: tt.jobID}
+				svc.On("NewRetryJob", testUtils.CtxMatcher, tt.jobID).Return(retryJob, queJobs, nil)
+			}
+			svc.On("GetJobPriority", "A0001", "Patient", false).Return(int16(2))
+			svc.On("GetJobPriority", "A0001", "ExplanationOfBenefit", true).Return(int16(3))
+
+			enq := &mockEnq.Enqueuer{}
+			enq.On("AddJob", testUtils.CtxMatcher, mock.Anything, mock.Anything).Return(tt.enqErr)
+
+			retryJobID, err := createRetryJob(tt.jobID, s.db, svc, enq)
+			if tt.enqErr != nil {
+				assert.ErrorContains(t, err, tt.enqErr.Error())
+				// The retry job is rolled back when its queue jobs cannot be enqueued
+				for _, j := range postgrestest.GetJobsByACOID(t, s.db, s.testACO.UUID) {
+					assert.NotEqual(t, job.ID, j.RetryOf)
+				}
+				return
+			}
+			if tt.errMsg != "" {
+				assert.EqualError(t, err, tt.errMsg)
+				return
+			}
+			assert.NoError(t, err)
+			defer postgrestest.DeleteJobByID(t, s.db, retryJobID)
+
+			assert.Equal(t, job.ID, postgrestest.GetJobByID(t, s.db, retryJobID).RetryOf)
+			for i, priority := range []int{2, 3} {
+				assert.EqualValues(t, retryJobID, queJobs[i].ID)
+				enq.AssertCalled(t, "AddJob", testUtils.CtxMatcher, *queJobs[i], priority)
+			}
+		})
+	}
+}
+
 func createTestZipFile(zFile string, cclfFiles ...string) error {
 	zf, err := os.Create(zFile)
 	if err != nil {
@@ -27,6 +27,9 @@ type cclfZipMetadata struct {
 	cclf8Metadata cclfFileMetadata
 	cclf0File     zip.File
 	cclf8File     zip.File
+	// The CCLF9 (beneficiary crosswalk) file is optional and nil when absent from the archive
+	cclf9Metadata *cclfFileMetadata
+	cclf9File     *zip.File
 	zipReader     *zip.Reader
 	zipCloser     func()
 	filePath      string
@@ -66,7 +69,9 @@ type CclfImporter struct {
 	FileProcessor CclfFileProcessor
 }
 
-func (importer CclfImporter) importCCLF0(ctx context.Context, zipMetadata *cclfZipMetadata) (*cclfFileValidator, error) {
+// importCCLF0 reads the CCLF0 summary file and returns validators for the CCLF8 and CCLF9 files it lists,
+// keyed by file type. The CCLF8 file must be listed, while the CCLF9 file is optional.
+func (importer CclfImporter) importCCLF0(ctx context.Context, zipMetadata *cclfZipMetadata) (map[string]cclfFileValidator, error) {
 	fileMetadata := zipMetadata.cclf0Metadata
 	importer.Logger.Infof("Importing CCLF0 file %s...", fileMetadata)
 
@@ -88,15 +93,15 @@ func (importer CclfImporter) importCCLF0(ctx context.Context, zipMetadata *cclfZ
 	defer rc.Close()
 	sc := bufio.NewScanner(rc)
 
-	var validator *cclfFileValidator
+	validators := make(map[string]cclfFileValidator)
 
 	for sc.Scan() {
 		b := sc.Bytes()
 		if len(bytes.TrimSpace(b)) > 0 {
 			filetype := string(bytes.TrimSpace(b[fileNumStart:fileNumEnd]))
 
-			if filetype == "CCLF8" {
-				if validator != nil {
+			if filetype == "CCLF8" || filetype == "CCLF9" {
+				if _, found := validators[filetype]; found {
 					err := fmt.Errorf("duplicate %v file type found from CCLF0 file", filetype)
 					importer.Logger.Error(err)
 					return nil, err
@@ -115,14 +120,14 @@ func (importer CclfImporter) importCCLF0(ctx context.Context, zipMetadata *cclfZ
 					return nil, err
 				}
 
-				validator = &cclfFileValidator{totalRecordCount: count, maxRecordLength: length}
+				validators[filetype] = cclfFileValidator{totalRecordCount: count, maxRecordLength: length}
 			}
 		}
 	}
 
-	if validator != nil {
+	if _, found := validators["CCLF8"]; found {
 		importer.Logger.Infof("Successfully imported CCLF0 file %s.", fileMetadata)
-		return validator, nil
+		return validators, nil
 	}
 
 	err = fmt.Errorf("failed to parse CCLF8 from CCLF0 file %s", fileMetadata.name)
@@ -162,11 +167,29 @@ func (importer CclfImporter) importCCLF8(ctx context.Context, zipMetadata *cclfZ
 
 	rtx := postgres.NewRepositoryPgxTx(tx)
 
+	cclfFile := models.CCLFFile{
+		CCLFNum:         fileMetadata.cclfNum,
+		Name:            fileMetadata.name,
+		ACOCMSID:        fileMetadata.acoID,
+		Timestamp:       fileMetadata.timestamp,
+		PerformanceYear: fileMetadata.perfYear,
+		ImportStatus:    constants.ImportInprog,
+		Type:            fileMetadata.fileType,
+	}
+
 	defer func() {
 		if err != nil {
 			if err1 := tx.Rollback(); err1 != nil {
 				importer.Logger.Warnf("Failed to rollback transaction %s", err.Error())
 			}
+
+			// Keep the quarantined records of a rejected file so that they can be reviewed
+			var rejectErr *rejectRateError
+			if errors.As(err, &rejectErr) {
+				if err1 := recordRejectedFile(ctx, conn, cclfFile, rejectErr.quarantined); err1 != nil {
+					importer.Logger.Errorf("Failed to record rejected CCLF%d file %s: %s", fileMetadata.cclfNum, fileMetadata.name, err1.Error())
+				}
+			}
 			return
 		}
 	}()
@@ -174,6 +197,110 @@ func (importer CclfImporter) importCCLF8(ctx context.Context, zipMetadata *cclfZ
 	close := metrics.NewChild(ctx, fmt.Sprintf("importCCLF%d", fileMetadata.cclfNum))
 	defer close()
 
+	cclfFile.ID, err = rtx.CreateCCLFFile(ctx, cclfFile)
+	if err != nil {
+		err = errors.Wrapf(err, "could not create CCLF%d file record", fileMetadata.cclfNum)
+		importer.Logger.Error(err)
+		return err
+	}
+
+	fileMetadata.fileID = cclfFile.ID
+
+	rc, err := zipMetadata.cclf8File.Open()
+	if err != nil {
+		err = errors.Wrapf(err, "could not read file %s for CCLF%d in archive %s", cclfFile.Name, fileMetadata.cclfNum, zipMetadata.filePath)
+		importer.Logger.Error(err)
+		return err
+	}
+	defer rc.Close()
+	sc := bufio.NewScanner(rc)
+
+	importedCount, recordCount, err := CopyFrom(ctx, tx, sc, cclfFile.ID, utils.GetEnvInt("CCLF_IMPORT_STATUS_RECORDS_INTERVAL", 10000), importer.Logger, validator.maxRecordLength)
+	if err != nil {
+		return errors.Wrap(err, "failed to copy data to beneficiaries table")
+	}
+
+	if recordCount > validator.totalRecordCount {
+		err := fmt.Errorf("unexpected number of records imported for file %s (expected: %d, actual: %d)", fileMetadata.name, validator.totalRecordCount, recordCount)
+		importer.Logger.Error(err)
+		return err
+	}
+
+	delta, err := recordAttributionDelta(ctx, rtx, cclfFile)
+	if err != nil {
+		err = errors.Wrapf(err, "could not record attribution delta for file: %s", fileMetadata.name)
+		importer.Logger.Error(err)
+		return err
+	}
+	importer.Logger.WithFields(logrus.Fields{"added_count": delta.AddedCount, "removed_count": delta.RemovedCount,
+		"retained_count": delta.RetainedCount}).Infof("Recorded attribution delta for CCLF%d file %s", fileMetadata.cclfNum, fileMetadata.name)
+
+	err = rtx.UpdateCCLFFileImportStatus(ctx, fileMetadata.fileID, constants.ImportComplete)
+	if err != nil {
+		err = errors.Wrapf(err, "could not update cclf file record for file: %s.", fileMetadata.name)
+		importer.Logger.Error(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		importer.Logger.Error(err.Error())
+		failMsg := fmt.Sprintf("failed to commit transaction for CCLF%d import file %s", fileMetadata.cclfNum, fileMetadata.name)
+		return errors.Wrap(err, failMsg)
+	}
+
+	successMsg := fmt.Sprintf("Successfully imported %d records from CCLF%d file %s.", importedCount, fileMetadata.cclfNum, fileMetadata.name)
+	importer.Logger.WithFields(logrus.Fields{"imported_count": importedCount}).Info(successMsg)
+
+	return nil
+}
+
+// importCCLF9 writes the HICN/MBI crosswalk records contained in the archive's CCLF9 file to the cclf_beneficiary_xrefs table.
+func (importer CclfImporter) importCCLF9(ctx context.Context, zipMetadata *cclfZipMetadata, validator cclfFileValidator) (err error) {
+	fileMetadata := *zipMetadata.cclf9Metadata
+
+	db := database.Connection
+	repository := postgres.NewRepository(db)
+	exists, err := repository.GetCCLFFileExistsByName(ctx, fileMetadata.name)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to check existence of CCLF%d file", fileMetadata.cclfNum)
+		importer.Logger.Error(err)
+		return err
+	}
+
+	if exists {
+		importer.Logger.Infof("CCLF%d file %s already exists in database, skipping import...", fileMetadata.cclfNum, fileMetadata)
+		return nil
+	}
+
+	importer.Logger.Infof("Importing CCLF%d file %s...", fileMetadata.cclfNum, fileMetadata)
+
+	conn, err := stdlib.AcquireConn(db)
+	if err != nil {
+		err = fmt.Errorf("failed to acquire connection: %w", err)
+		importer.Logger.Error(err)
+		return err
+	}
+	defer utils.CloseAndLog(logrus.WarnLevel, func() error { return stdlib.ReleaseConn(db, conn) })
+
+	tx, err := conn.BeginEx(ctx, nil)
+	if err != nil {
+		err = fmt.Errorf("failed to start transaction: %w", err)
+		importer.Logger.Error(err)
+		return err
+	}
+
+	rtx := postgres.NewRepositoryPgxTx(tx)
+
+	defer func() {
+		if err != nil {
+			if err1 := tx.Rollback(); err1 != nil {
+				importer.Logger.Warnf("Failed to rollback transaction %s", err.Error())
+			}
+		}
+	}()
+
+	close := metrics.NewChild(ctx, fmt.Sprintf("importCCLF%d", fileMetadata.cclfNum))
+	defer close()
+
 	cclfFile := models.CCLFFile{
 		CCLFNum:         fileMetadata.cclfNum,
 		Name:            fileMetadata.name,
@@ -190,9 +317,7 @@ func (importer CclfImporter) importCCLF8(ctx context.Context, zipMetadata *cclfZ
 		return err
 	}
 
-	fileMetadata.fileID = cclfFile.ID
-
-	rc, err := zipMetadata.cclf8File.Open()
+	rc, err := zipMetadata.cclf9File.Open()
 	if err != nil {
 		err = errors.Wrapf(err, "could not read file %s for CCLF%d in archive %s", cclfFile.Name, fileMetadata.cclfNum, zipMetadata.filePath)
 		importer.Logger.Error(err)
@@ -201,21 +326,21 @@ func (importer CclfImporter) importCCLF8(ctx context.Context, zipMetadata *cclfZ
 	defer rc.Close()
 	sc := bufio.NewScanner(rc)
 
-	importedCount, recordCount, err := CopyFrom(ctx, tx, sc, cclfFile.ID, utils.GetEnvInt("CCLF_IMPORT_STATUS_RECORDS_INTERVAL", 10000), importer.Logger, validator.maxRecordLength)
+	importedCount, recordCount, err := CopyFromCrosswalk(ctx, tx, sc, cclfFile.ID, utils.GetEnvInt("CCLF_IMPORT_STATUS_RECORDS_INTERVAL", 10000), importer.Logger, validator.maxRecordLength)
 	if err != nil {
-		return errors.Wrap(err, "failed to copy data to beneficiaries table")
+		return errors.Wrap(err, "failed to copy data to beneficiary crosswalk table")
 	}
 
 	if recordCount > validator.totalRecordCount {
-		err := fmt.Errorf("unexpected number of records imported for file %s (expected: %d, actual: %d)", fileMetadata.name, validator.totalRecordCount, recordCount)
+		err = fmt.Errorf("unexpected number of records imported for file %s (expected: %d, actual: %d)", fileMetadata.name, validator.totalRecordCount, recordCount)
 		importer.Logger.Error(err)
 		return err
 	}
 
-	err = rtx.UpdateCCLFFileImportStatus(ctx, fileMetadata.fileID, constants.ImportComplete)
-	if err != nil {
+	if err = rtx.UpdateCCLFFileImportStatus(ctx, cclfFile.ID, constants.ImportComplete); err != nil {
 		err = errors.Wrapf(err, "could not update cclf file record for file: %s.", fileMetadata.name)
 		importer.Logger.Error(err)
+		return err
 	}
 
 	if err = tx.Commit(); err != nil {
@@ -257,22 +382,43 @@ func (importer CclfImporter) ImportCCLFDirectory(filePath string) (success, fail
 				defer c()
 				defer zipMetadata.zipCloser()
 
-				cclfvalidator, err := importer.importCCLF0(ctx, zipMetadata)
+				validators, err := importer.importCCLF0(ctx, zipMetadata)
 				if err != nil {
 					importer.Logger.Errorf("Failed to import CCLF0 file: %s, Skipping CCLF8 file: %s ", zipMetadata.cclf0Metadata, zipMetadata.cclf8Metadata)
 					failure++
 					skipped += 2
-				} else {
-					success++
+					if zipMetadata.cclf9Metadata != nil {
+						skipped++
+					}
+					return
 				}
+				success++
 
-				if err = importer.importCCLF8(ctx, zipMetadata, *cclfvalidator); err != nil {
+				imported := true
+				if err = importer.importCCLF8(ctx, zipMetadata, validators["CCLF8"]); err != nil {
 					importer.Logger.Errorf("Failed to import CCLF8 file: %s %s", zipMetadata.cclf8Metadata, err)
 					failure++
+					imported = false
 				} else {
-					zipMetadata.imported = true
 					success++
 				}
+
+				// The CCLF9 crosswalk is optional, but when delivered it must be described by the CCLF0 file
+				if zipMetadata.cclf9Metadata != nil {
+					if validator, found := validators["CCLF9"]; !found {
+						importer.Logger.Errorf("Failed to import CCLF9 file: %s, file type not found in CCLF0 file", zipMetadata.cclf9Metadata)
+						failure++
+						imported = false
+					} else if err = importer.importCCLF9(ctx, zipMetadata, validator); err != nil {
+						importer.Logger.Errorf("Failed to import CCLF9 file: %s %s", zipMetadata.cclf9Metadata, err)
+						failure++
+						imported = false
+					} else {
+						success++
+					}
+				}
+
+				zipMetadata.imported = imported
 			}()
 		}
 	}
@@ -101,9 +101,12 @@ func (s *CCLFTestSuite) TestImportCCLF0() {
 	defer zipCloser1()
 
 	// positive
-	validator, err := s.importer.importCCLF0(ctx, metadata)
+	validators, err := s.importer.importCCLF0(ctx, metadata)
 	assert.Nil(err)
-	assert.Equal(&cclfFileValidator{totalRecordCount: 7, maxRecordLength: 549}, validator)
+	assert.Equal(map[string]cclfFileValidator{
+		"CCLF8": {totalRecordCount: 7, maxRecordLength: 549},
+		"CCLF9": {totalRecordCount: 6, maxRecordLength: 54},
+	}, validators)
 
 	// missing cclf8 from cclf0
 	cclfZipfilePath = filepath.Join(s.basePath, "cclf/archives/0/missing_data/T.BCD.A0001.ZCY18.D181120.T1000000")
@@ -229,6 +232,66 @@ func (s *CCLFTestSuite) TestImportCCLF8() {
 	assert.Equal("1A69B98CD35", mbis[5])
 }
 
+func (s *CCLFTestSuite) TestImportCCLF9() {
+	assert := assert.New(s.T())
+
+	postgrestest.DeleteCCLFFilesByCMSID(s.T(), s.db, "A0001")
+	defer postgrestest.DeleteCCLFFilesByCMSID(s.T(), s.db, "A0001")
+
+	const cclf9Name = "T.BCD.A0001.ZC9Y18.D181120.T1000010"
+	metadata, zipCloser := buildZipMetadata(s.T(), s.importer.FileProcessor, "A0001", filepath.Join(s.basePath, constants.CCLF8CompPath), "", "", models.FileTypeDefault)
+	defer zipCloser()
+	metadata.cclf9Metadata = &cclfFileMetadata{cclfNum: 9, name: cclf9Name, acoID: "A0001", timestamp: time.Now(), perfYear: 18, fileType: models.FileTypeDefault}
+	metadata.cclf9File = testUtils.GetFileFromZip(s.T(), metadata.zipReader, cclf9Name)
+
+	// validation error -- records too long
+	validator := cclfFileValidator{maxRecordLength: 43, totalRecordCount: 6}
+	err := s.importer.importCCLF9(context.Background(), metadata, validator)
+	s.ErrorContains(err, "incorrect record length for file (expected: 43, actual: 53)")
+
+	// validation error -- too many records
+	validator = cclfFileValidator{maxRecordLength: 54, totalRecordCount: 2}
+	err = s.importer.importCCLF9(context.Background(), metadata, validator)
+	s.ErrorContains(err, "unexpected number of records imported for file T.BCD.A0001.ZC9Y18.D181120.T1000010 (expected: 2, actual: 6)")
+	assert.Empty(postgrestest.GetCCLFFilesByName(s.T(), s.db, cclf9Name))
+
+	// successful
+	validator = cclfFileValidator{maxRecordLength: 54, totalRecordCount: 6}
+	s.NoError(s.importer.importCCLF9(context.Background(), metadata, validator))
+
+	files := postgrestest.GetCCLFFilesByName(s.T(), s.db, cclf9Name)
+	assert.Len(files, 1)
+	assert.Equal(9, files[0].CCLFNum)
+	assert.Equal(constants.ImportComplete, files[0].ImportStatus)
+
+	rows, err := s.db.Query(`SELECT xref_indicator, current_num, prev_num, prvs_obslt_dt FROM cclf_beneficiary_xrefs
+		WHERE file_id = $1 ORDER BY id`, files[0].ID)
+	assert.NoError(err)
+	defer rows.Close()
+
+	var xrefs []string
+	for rows.Next() {
+		var indicator, currentNum, prevNum string
+		var obsoleteDate time.Time
+		assert.NoError(rows.Scan(&indicator, &currentNum, &prevNum, &obsoleteDate))
+		// current_num and prev_num are fixed width columns, so shorter HICNs are padded
+		xrefs = append(xrefs, fmt.Sprintf("%s %s %s %s", indicator, strings.TrimSpace(currentNum), strings.TrimSpace(prevNum), obsoleteDate.Format("2006-01-02")))
+	}
+	assert.NoError(rows.Err())
+	assert.Equal([]string{
+		"H 203031401M 203031401A 2016-12-31",
+		"H 20303140244 203031402B 2016-12-31",
+		"H 20303140344 203031403B 2016-12-31",
+		"M 1A69B98CD33 1A69B98CD32 2017-06-11",
+		"M 1A69B98CD34 1A69B98CD33 2017-12-31",
+		"M 1A69B98CD35 1A69B98CD34 2010-05-11",
+	}, xrefs)
+
+	// importing the same file again is skipped
+	s.NoError(s.importer.importCCLF9(context.Background(), metadata, validator))
+	assert.Len(postgrestest.GetCCLFFilesByName(s.T(), s.db, cclf9Name), 1)
+}
+
 func (s *CCLFTestSuite) TestImportCCLF8DBErrors() {
 	assert := assert.New(s.T())
 
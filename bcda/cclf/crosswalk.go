@@ -0,0 +1,159 @@
+package cclf
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/CMSgov/bcda-app/bcda/cclf/metrics"
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/pgtype"
+	"github.com/sirupsen/logrus"
+)
+
+// CCLF9 crosswalk indicators describe whether a record links beneficiary HICNs or MBIs
+const (
+	xrefIndicatorHICN = "H"
+	xrefIndicatorMBI  = "M"
+)
+
+// A cclf9Importer writes the HICN/MBI crosswalk records contained in a CCLF9 file.
+// It is not safe for concurrent use by multiple goroutines and should be scoped to a single *pgx.Tx
+type cclf9Importer struct {
+	ctx context.Context
+
+	scanner        *bufio.Scanner
+	reportInterval int
+	cclfFileID     uint // CCLFFile ID that will be associated with all created crosswalk records
+
+	recordCount          int
+	importCount          int
+	logger               logrus.FieldLogger
+	expectedRecordLength int
+}
+
+func (importer *cclf9Importer) Next() bool {
+	// Blank lines do not contain a crosswalk record and are not counted
+	for importer.scanner.Scan() {
+		if len(bytes.TrimSpace(importer.scanner.Bytes())) == 0 {
+			continue
+		}
+		importer.recordCount++
+		return true
+	}
+	return false
+}
+
+func (importer *cclf9Importer) Values() ([]interface{}, error) {
+	close := metrics.NewChild(importer.ctx, "importCCLF9-xrefcreate")
+	defer close()
+
+	const (
+		indicatorStart, indicatorEnd         = 0, 1
+		currentNumStart, currentNumEnd       = 1, 12
+		prevNumStart, prevNumEnd             = 12, 23
+		effectiveDateStart, effectiveDateEnd = 23, 33
+		obsoleteDateStart, obsoleteDateEnd   = 33, 43
+	)
+
+	b := importer.scanner.Bytes()
+	trimmed := bytes.TrimSpace(b)
+
+	// Records must contain both identifiers, but the trailing date fields may be omitted
+	if len(b) < prevNumEnd || len(trimmed) > importer.expectedRecordLength {
+		err := fmt.Errorf("incorrect record length for file (expected: %d, actual: %d)", importer.expectedRecordLength, len(trimmed))
+		importer.logger.Error(err)
+		return nil, err
+	}
+
+	indicator := string(b[indicatorStart:indicatorEnd])
+	if indicator != xrefIndicatorHICN && indicator != xrefIndicatorMBI {
+		err := fmt.Errorf("invalid crosswalk indicator %q for record %d", indicator, importer.recordCount)
+		importer.logger.Error(err)
+		return nil, err
+	}
+
+	effectiveDate, err := parseXrefDate(b, effectiveDateStart, effectiveDateEnd)
+	if err != nil {
+		err = fmt.Errorf("failed to parse previous effective date for record %d: %w", importer.recordCount, err)
+		importer.logger.Error(err)
+		return nil, err
+	}
+	obsoleteDate, err := parseXrefDate(b, obsoleteDateStart, obsoleteDateEnd)
+	if err != nil {
+		err = fmt.Errorf("failed to parse previous obsolete date for record %d: %w", importer.recordCount, err)
+		importer.logger.Error(err)
+		return nil, err
+	}
+
+	// Use Int4 because we store file_id as an integer
+	fileID := &pgtype.Int4{}
+	if err := fileID.Set(importer.cclfFileID); err != nil {
+		return nil, err
+	}
+
+	currentNum := &pgtype.BPChar{}
+	if err := currentNum.Set(string(bytes.TrimSpace(b[currentNumStart:currentNumEnd]))); err != nil {
+		return nil, err
+	}
+
+	prevNum := &pgtype.BPChar{}
+	if err := prevNum.Set(string(bytes.TrimSpace(b[prevNumStart:prevNumEnd]))); err != nil {
+		return nil, err
+	}
+
+	importer.importCount++
+	if importer.importCount%importer.reportInterval == 0 {
+		importer.logger.Infof("CCLF9 records imported: %d\n", importer.importCount)
+	}
+
+	return []interface{}{fileID, indicator, currentNum, prevNum, effectiveDate, obsoleteDate}, nil
+}
+
+// Err allows us to report back to the CopyFrom if and when
+// the underlying context has been stopped.
+func (importer *cclf9Importer) Err() error {
+	return importer.ctx.Err()
+}
+
+// parseXrefDate parses the YYYY-MM-DD date found in b[start:end]. Blank or missing dates are returned as NULL.
+func parseXrefDate(b []byte, start, end int) (*pgtype.Date, error) {
+	date := &pgtype.Date{Status: pgtype.Null}
+	if len(b) <= start {
+		return date, nil
+	}
+
+	value := string(bytes.TrimSpace(b[start:min(end, len(b))]))
+	if value == "" {
+		return date, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	if err := date.Set(t); err != nil {
+		return nil, err
+	}
+	return date, nil
+}
+
+// CopyFromCrosswalk writes all of the crosswalk records captured in the scanner to the cclf_beneficiary_xrefs table.
+// It returns the number of rows written and the number of records read along with any error that occurred.
+func CopyFromCrosswalk(ctx context.Context, tx *pgx.Tx, scanner *bufio.Scanner, fileID uint, reportInterval int, logger logrus.FieldLogger, expectedRecordLength int) (int, int, error) {
+	importer := &cclf9Importer{
+		scanner:    scanner,
+		ctx:        ctx,
+		cclfFileID: fileID,
+
+		reportInterval:       reportInterval,
+		logger:               logger,
+		expectedRecordLength: expectedRecordLength,
+	}
+	tableName := pgx.Identifier([]string{"cclf_beneficiary_xrefs"})
+	columns := []string{"file_id", "xref_indicator", "current_num", "prev_num", "prvs_efct_dt", "prvs_obslt_dt"}
+	importedCount, err := tx.CopyFrom(tableName, columns, importer)
+	return importedCount, importer.recordCount, err
+}
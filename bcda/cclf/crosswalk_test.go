@@ -0,0 +1,91 @@
+package cclf
+
+import (
+	"bufio"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/pgtype"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrosswalkNext(t *testing.T) {
+	records := []string{"M1A69B98CD331A69B98CD32", "", "   ", "M1A69B98CD341A69B98CD33"}
+	scanner := bufio.NewScanner(strings.NewReader(strings.Join(records, "\n")))
+
+	importer := &cclf9Importer{scanner: scanner}
+	for _, expected := range []string{records[0], records[3]} {
+		assert.True(t, importer.Next())
+		assert.Equal(t, expected, string(importer.scanner.Bytes()))
+	}
+
+	assert.False(t, importer.Next())
+	assert.Equal(t, 2, importer.recordCount)
+}
+
+func TestCrosswalkValues(t *testing.T) {
+	tests := []struct {
+		name          string
+		record        string
+		indicator     string
+		currentNum    string
+		prevNum       string
+		effectiveDate pgtype.Date
+		obsoleteDate  pgtype.Date
+		errMsg        string
+	}{
+		{
+			name: "MBI crosswalk", record: "M1A69B98CD331A69B98CD321960-01-012017-06-11            ",
+			indicator: "M", currentNum: "1A69B98CD33", prevNum: "1A69B98CD32",
+			effectiveDate: pgtype.Date{Time: time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC), Status: pgtype.Present},
+			obsoleteDate:  pgtype.Date{Time: time.Date(2017, 6, 11, 0, 0, 0, 0, time.UTC), Status: pgtype.Present},
+		},
+		{
+			name: "HICN crosswalk without dates", record: "H203031401M 203031401A ",
+			indicator: "H", currentNum: "203031401M", prevNum: "203031401A",
+			effectiveDate: pgtype.Date{Status: pgtype.Null},
+			obsoleteDate:  pgtype.Date{Status: pgtype.Null},
+		},
+		{name: "Record too long", record: "M1A69B98CD331A69B98CD321960-01-012017-06-11A001100001AAAA", errMsg: "incorrect record length for file (expected: 54, actual: 57)"},
+		{name: "Record too short", record: "M1A69B98CD33", errMsg: "incorrect record length for file (expected: 54, actual: 12)"},
+		{name: "Unknown indicator", record: "X1A69B98CD331A69B98CD32", errMsg: `invalid crosswalk indicator "X" for record 1`},
+		{name: "Invalid date", record: "M1A69B98CD331A69B98CD321960-13-01", errMsg: "failed to parse previous effective date for record 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			importer := &cclf9Importer{ctx: context.Background(), cclfFileID: 12345,
+				scanner: bufio.NewScanner(strings.NewReader(tt.record)), reportInterval: 1,
+				logger: logrus.StandardLogger(), expectedRecordLength: 54}
+			assert.True(t, importer.Next())
+
+			values, err := importer.Values()
+			if tt.errMsg != "" {
+				assert.ErrorContains(t, err, tt.errMsg)
+				assert.Equal(t, 0, importer.importCount)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, values, 6)
+			assert.EqualValues(t, 12345, values[0].(*pgtype.Int4).Int)
+			assert.Equal(t, tt.indicator, values[1])
+			assert.Equal(t, tt.currentNum, values[2].(*pgtype.BPChar).String)
+			assert.Equal(t, tt.prevNum, values[3].(*pgtype.BPChar).String)
+			assert.Equal(t, tt.effectiveDate, *values[4].(*pgtype.Date))
+			assert.Equal(t, tt.obsoleteDate, *values[5].(*pgtype.Date))
+			assert.Equal(t, 1, importer.importCount)
+		})
+	}
+}
+
+func TestCrosswalkErr(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	importer := &cclf9Importer{ctx: ctx}
+	assert.EqualError(t, importer.Err(), "context canceled")
+}
@@ -1,7 +1,6 @@
 package cclf
 
 import (
-	"bytes"
 	"context"
 	"database/sql"
 	"encoding/csv"
@@ -9,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	f "path/filepath"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx"
@@ -26,15 +26,15 @@ import (
 // FileProcessors for attribution are created as interfaces so that they can be passed in place of the implementation; local development and other envs will require different processors.
 // This interface has two implementations; one for ingesting and testing locally, and one for ingesting in s3.
 type CSVFileProcessor interface {
-	// Fetch the csv attribution file to be imported.
-	LoadCSV(path string) (*bytes.Reader, func(), error)
+	// Open the csv attribution file to be imported as a stream. The returned func closes the stream.
+	LoadCSV(path string) (io.Reader, func(), error)
 	// Remove csv attribution file that was successfully imported.
 	CleanUpCSV(file csvFile) (err error)
 }
 
 type csvFile struct {
 	metadata csvFileMetadata
-	data     *bytes.Reader
+	data     io.Reader
 	imported bool
 	filepath string
 }
@@ -74,7 +74,7 @@ func (importer CSVImporter) ImportCSV(filepath string) error {
 	}
 	file.metadata = metadata
 
-	data, _, err := importer.FileProcessor.LoadCSV(filepath)
+	data, closeCSV, err := importer.FileProcessor.LoadCSV(filepath)
 	if err != nil {
 		if errors.Is(err, &ers.AttributionFileMismatchedEnv{}) {
 			importer.Logger.WithFields(logrus.Fields{"file": filepath}).Info(err)
@@ -87,6 +87,7 @@ func (importer CSVImporter) ImportCSV(filepath string) error {
 	file.data = data
 
 	err = importer.ProcessCSV(file)
+	closeCSV()
 	if err != nil {
 		return err
 	}
@@ -127,14 +128,6 @@ func (importer CSVImporter) ProcessCSV(csv csvFile) error {
 
 	rtx := postgres.NewRepositoryPgxTx(tx)
 	var records int
-	defer func() {
-		if err != nil {
-			if err1 := tx.Rollback(); err1 != nil {
-				importer.Logger.Errorf("Failed to rollback transaction: %s, %s", err.Error(), err1.Error())
-			}
-			return
-		}
-	}()
 
 	// CCLF model corresponds with a database record
 	record := models.CCLFFile{
@@ -147,6 +140,23 @@ func (importer CSVImporter) ProcessCSV(csv csvFile) error {
 		Type:            csv.metadata.fileType,
 	}
 
+	defer func() {
+		if err != nil {
+			if err1 := tx.Rollback(); err1 != nil {
+				importer.Logger.Errorf("Failed to rollback transaction: %s, %s", err.Error(), err1.Error())
+			}
+
+			// Keep the quarantined records of a rejected file so that they can be reviewed
+			var rejectErr *rejectRateError
+			if errors.As(err, &rejectErr) {
+				if err1 := recordRejectedFile(ctx, conn, record, rejectErr.quarantined); err1 != nil {
+					importer.Logger.Errorf("Failed to record rejected csv file %s: %s", csv.metadata.name, err1.Error())
+				}
+			}
+			return
+		}
+	}()
+
 	record.ID, err = rtx.CreateCCLFFile(ctx, record)
 	if err != nil {
 		err := fmt.Errorf("database error when calling CreateCCLFFile(): %s", err)
@@ -155,18 +165,40 @@ func (importer CSVImporter) ProcessCSV(csv csvFile) error {
 
 	csv.metadata.fileID = record.ID
 
-	rows, count, err := importer.prepareCSVData(csv.data, record.ID)
+	source, err := newCSVAttributionSource(csv.data, record.ID)
 	if err != nil {
 		return err
 	}
 
-	records, err = tx.CopyFrom(pgx.Identifier{"cclf_beneficiaries"}, []string{"file_id", "mbi"}, pgx.CopyFromRows(rows))
-	if count != records {
-		return fmt.Errorf("unexpected number of records imported (expected: %d, actual: %d)", count, records)
+	records, err = tx.CopyFrom(pgx.Identifier{"cclf_beneficiaries"}, []string{"file_id", "mbi"}, source)
+	if err != nil {
+		return fmt.Errorf("failed to write attribution beneficiaries to database using CopyFrom: %w", err)
+	}
+	if source.importCount != records {
+		return fmt.Errorf("unexpected number of records imported (expected: %d, actual: %d)", source.importCount, records)
+	}
+
+	if source.duplicateCount > 0 {
+		importer.Logger.WithFields(logrus.Fields{"duplicate_count": source.duplicateCount}).
+			Warnf("Skipped %d records with a duplicate MBI from csv file %s", source.duplicateCount, csv.metadata.name)
 	}
+	if len(source.quarantined) > 0 {
+		importer.Logger.Warnf("Quarantined %d records with an invalid MBI from csv file %s", len(source.quarantined), csv.metadata.name)
+	}
+	if err = checkRejectRate(source.quarantined, source.recordCount); err != nil {
+		return err
+	}
+
+	if err = quarantine(tx, record.ID, source.quarantined); err != nil {
+		return err
+	}
+
+	delta, err := recordAttributionDelta(ctx, rtx, record)
 	if err != nil {
-		return errors.New("failed to write attribution beneficiaries to database using CopyFrom")
+		return fmt.Errorf("could not record attribution delta for csv file %s: %w", csv.metadata.name, err)
 	}
+	importer.Logger.WithFields(logrus.Fields{"added_count": delta.AddedCount, "removed_count": delta.RemovedCount,
+		"retained_count": delta.RetainedCount}).Infof("Recorded attribution delta for csv file %s", csv.metadata.name)
 
 	err = rtx.UpdateCCLFFileImportStatus(ctx, csv.metadata.fileID, constants.ImportComplete)
 	if err != nil {
@@ -182,37 +214,92 @@ func (importer CSVImporter) ProcessCSV(csv csvFile) error {
 	return nil
 }
 
-func (importer CSVImporter) prepareCSVData(csvfile *bytes.Reader, id uint) ([][]interface{}, int, error) {
-	var rows [][]interface{}
-	r := csv.NewReader(csvfile)
+// csvAttributionSource streams the beneficiaries of a csv attribution file into CopyFrom. Like the cclf8Importer,
+// it is not safe for concurrent use by multiple goroutines and should be scoped to a single transaction.
+type csvAttributionSource struct {
+	reader *csv.Reader
+	fileID uint // CCLFFile ID that will be associated with all created benes
+
+	mbi            string
+	err            error
+	recordCount    int
+	importCount    int
+	duplicateCount int
+	processedMBIs  map[string]struct{}
+	quarantined    []quarantinedRecord
+}
+
+// newCSVAttributionSource reads and validates the header of the csv attribution file. The records are read as
+// they are copied to the database.
+func newCSVAttributionSource(data io.Reader, fileID uint) (*csvAttributionSource, error) {
+	r := csv.NewReader(data)
+	r.ReuseRecord = true
 
-	_, err := r.Read()
+	header, err := r.Read()
 	if err == io.EOF {
-		return nil, 0, errors.New("empty attribution file")
+		return nil, errors.New("empty attribution file")
 	}
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to read csv attribution header: %s", err)
+		return nil, fmt.Errorf("failed to read csv attribution header: %s", err)
 	}
+	if err = validateCSVHeader(header); err != nil {
+		return nil, err
+	}
+
+	return &csvAttributionSource{
+		reader:        r,
+		fileID:        fileID,
+		processedMBIs: make(map[string]struct{}),
+	}, nil
+}
 
-	count := 0
+// validateCSVHeader verifies that the first column of the csv attribution file contains the MBIs.
+// Any additional columns are ignored.
+func validateCSVHeader(header []string) error {
+	column := strings.TrimSpace(strings.TrimPrefix(header[0], "\ufeff"))
+	if !strings.EqualFold(column, "MBIs") && !strings.EqualFold(column, "MBI") {
+		return fmt.Errorf("invalid csv attribution header: expected the first column to be MBIs, found %q", column)
+	}
+	return nil
+}
 
+func (source *csvAttributionSource) Next() bool {
+	// As with the cclf8Importer, invalid and already processed MBIs are skipped here
+	// since returning an error or empty data from Values() would fail the copy.
 	for {
-		var record []string
-		record, err = r.Read()
+		record, err := source.reader.Read()
 		if err == io.EOF {
-			err = nil
-			break
+			return false
 		}
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to read csv attribution file: %s", err)
+			source.err = fmt.Errorf("failed to read csv attribution file: %s", err)
+			return false
+		}
+		source.recordCount++
+
+		mbi := strings.TrimSpace(record[0])
+		if err := utils.ValidateMBI(mbi); err != nil {
+			source.quarantined = append(source.quarantined, quarantinedRecord{recordNumber: source.recordCount, mbi: mbi, reason: err.Error()})
+			continue
 		}
-		row := make([]interface{}, 2)
-		row[0] = id
-		row[1] = record[0]
 
-		rows = append(rows, row)
-		count++
+		if _, found := source.processedMBIs[mbi]; found {
+			source.duplicateCount++
+			continue
+		}
+
+		source.processedMBIs[mbi] = struct{}{}
+		source.mbi = mbi
+		return true
 	}
-	return rows, count, err
+}
+
+func (source *csvAttributionSource) Values() ([]interface{}, error) {
+	source.importCount++
+	return []interface{}{source.fileID, source.mbi}, nil
+}
 
+// Err reports any error encountered while reading the csv attribution file, which aborts the CopyFrom
+func (source *csvAttributionSource) Err() error {
+	return source.err
 }
@@ -107,7 +107,7 @@ func (s *CSVTestSuite) TestImportCSV_Integration() {
 		cclfBeneRec []string
 		err         error
 	}{
-		{"Import CSV attribution success", filepath.Join(s.basePath, "cclf/archives/csv/P.PCPB.M2411.D181120.T1000000"), 0, []string{"MBI000001", "MBI000002", "MBI000003", "MBI000004", "MBI000005"}, nil},
+		{"Import CSV attribution success", filepath.Join(s.basePath, "cclf/archives/csv/P.PCPB.M2411.D181120.T1000000"), 0, []string{"1A00A00AA01", "1A00A00AA02", "1A00A00AA03", "1A00A00AA04", "1A00A00AA05"}, nil},
 		{"Import CSV attribution that already exists", filepath.Join(s.basePath, "cclf/archives/csv/P.PCPB.M2411.D181121.T1000000"), 0, []string{}, errors.New("already exists")},
 		{"Import CSV attribution invalid name", filepath.Join(s.basePath, "cclf/archives/csv/P.PC.M2411.D181120.T1000000"), 0, []string{}, errors.New("Invalid filename")},
 		{"Import Opt Out failure", filepath.Join(s.basePath, "cclf/archives/csv/T#EFT.ON.ACO.NGD1800.DPRF.D181120.T1000010"), 0, []string{}, errors.New("File is type: opt-out. Skipping attribution import.")},
@@ -156,7 +156,7 @@ func (s *CSVTestSuite) TestProcessCSV_Integration() {
 			fileID:       0,
 			fileType:     1,
 		},
-		data: bytes.NewReader([]byte("MBIS\nMBI000001\nMBI000002\nMBI000003")),
+		data: bytes.NewReader([]byte("MBIS\n1A00A00AA01\n1A00A00AA02\n1A00A00AA03")),
 	}
 
 	expectedFile := models.CCLFFile{
@@ -171,7 +171,7 @@ func (s *CSVTestSuite) TestProcessCSV_Integration() {
 		mbiRecord  []string
 		err        error
 	}{
-		{"Import CSV attribution success", file, expectedFile, []string{"MBI000001", "MBI000002", "MBI000003"}, nil},
+		{"Import CSV attribution success", file, expectedFile, []string{"1A00A00AA01", "1A00A00AA02", "1A00A00AA03"}, nil},
 		{"Import CSV attribution that already exists", file, models.CCLFFile{}, []string{}, errors.New("already exists")},
 	}
 
@@ -196,7 +196,7 @@ func (s *CSVTestSuite) TestProcessCSV_Integration() {
 				assert.Equal(s.T(), 3, len(beneRecords))
 				for i, v := range beneRecords {
 					fmt.Println(i, v)
-					assert.Contains(s.T(), []string{"MBI000001", "MBI000002", "MBI000003"}, (strings.ReplaceAll(v, " ", "")))
+					assert.Contains(s.T(), []string{"1A00A00AA01", "1A00A00AA02", "1A00A00AA03"}, (strings.ReplaceAll(v, " ", "")))
 				}
 			}
 
@@ -204,36 +204,212 @@ func (s *CSVTestSuite) TestProcessCSV_Integration() {
 	}
 }
 
-func TestPrepareCSVData(t *testing.T) {
-	c := CSVImporter{}
+func (s *CSVTestSuite) TestProcessCSVQuarantine_Integration() {
+	conf.SetEnv(s.T(), "ATTRIBUTION_MBI_REJECT_PCT", "50")
+	defer conf.UnsetEnv(s.T(), "ATTRIBUTION_MBI_REJECT_PCT")
+
+	newFile := func(name, data string) csvFile {
+		return csvFile{
+			metadata: csvFileMetadata{
+				name:      name,
+				env:       "test",
+				acoID:     "FOOACO",
+				cclfNum:   8,
+				perfYear:  24,
+				timestamp: time.Now(),
+				fileType:  1,
+			},
+			data: bytes.NewReader([]byte(data)),
+		}
+	}
+
+	// Below the reject threshold, valid records are imported and invalid records are quarantined
+	file := newFile("P.PCPB.M2411.D191006.T0209260", "MBIS\n1A00A00AA01\nMBI000002\n1A00A00AA03")
+	assert.NoError(s.T(), s.importer.ProcessCSV(file))
+	cclfRecord := postgrestest.GetCCLFFilesByName(s.T(), s.db, file.metadata.name)
+	assert.Len(s.T(), cclfRecord, 1)
+
+	beneRecords, err := postgres.NewRepository(s.db).GetCCLFBeneficiaryMBIs(context.Background(), cclfRecord[0].ID)
+	assert.NoError(s.T(), err)
+	sort.Strings(beneRecords)
+	assert.Equal(s.T(), []string{"1A00A00AA01", "1A00A00AA03"}, beneRecords)
+
+	var (
+		recordNumber int
+		mbi, reason  string
+	)
+	err = s.db.QueryRow("SELECT record_number, mbi, reason FROM cclf_quarantined_beneficiaries WHERE file_id = $1", cclfRecord[0].ID).
+		Scan(&recordNumber, &mbi, &reason)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, recordNumber)
+	assert.Equal(s.T(), "MBI000002", mbi)
+	assert.Equal(s.T(), "invalid MBI length 9", reason)
+
+	// Above the reject threshold, the whole file is rejected
+	file = newFile("P.PCPB.M2411.D191007.T0209260", "MBIS\n1A00A00AA01\nMBI000002\nMBI000003")
+	err = s.importer.ProcessCSV(file)
+	assert.ErrorContains(s.T(), err, "2 of 3 records (66.67%) have an invalid MBI")
+
+	// The rejected file is recorded as failed, without any beneficiaries, so that its quarantined records can be reviewed
+	cclfRecord = postgrestest.GetCCLFFilesByName(s.T(), s.db, file.metadata.name)
+	assert.Len(s.T(), cclfRecord, 1)
+	assert.Equal(s.T(), constants.ImportFail, cclfRecord[0].ImportStatus)
+
+	beneRecords, err = postgres.NewRepository(s.db).GetCCLFBeneficiaryMBIs(context.Background(), cclfRecord[0].ID)
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), beneRecords)
+
+	rows, err := s.db.Query("SELECT record_number, mbi FROM cclf_quarantined_beneficiaries WHERE file_id = $1 ORDER BY record_number", cclfRecord[0].ID)
+	assert.NoError(s.T(), err)
+	defer rows.Close()
+	var quarantined []string
+	for rows.Next() {
+		assert.NoError(s.T(), rows.Scan(&recordNumber, &mbi))
+		quarantined = append(quarantined, fmt.Sprintf("%d:%s", recordNumber, mbi))
+	}
+	assert.NoError(s.T(), rows.Err())
+	assert.Equal(s.T(), []string{"2:MBI000002", "3:MBI000003"}, quarantined)
+}
+
+func (s *CSVTestSuite) TestProcessCSVStreaming_Integration() {
+	newFile := func(name, data string) csvFile {
+		return csvFile{
+			metadata: csvFileMetadata{
+				name:      name,
+				env:       "test",
+				acoID:     "FOOACO",
+				cclfNum:   8,
+				perfYear:  24,
+				timestamp: time.Now(),
+				fileType:  1,
+			},
+			data: strings.NewReader(data),
+		}
+	}
+
+	// Duplicate MBIs are only imported once
+	file := newFile("P.PCPB.M2411.D191010.T0209260", "MBIs\n1A00A00AA01\n1A00A00AA02\n1A00A00AA01")
+	assert.NoError(s.T(), s.importer.ProcessCSV(file))
+	cclfRecord := postgrestest.GetCCLFFilesByName(s.T(), s.db, file.metadata.name)
+	assert.Len(s.T(), cclfRecord, 1)
+	beneRecords, err := postgres.NewRepository(s.db).GetCCLFBeneficiaryMBIs(context.Background(), cclfRecord[0].ID)
+	assert.NoError(s.T(), err)
+	sort.Strings(beneRecords)
+	assert.Equal(s.T(), []string{"1A00A00AA01", "1A00A00AA02"}, beneRecords)
+
+	// Files with an invalid header or malformed record are rejected without importing any records
+	file = newFile("P.PCPB.M2411.D191011.T0209260", "1A00A00AA01\n1A00A00AA02")
+	assert.ErrorContains(s.T(), s.importer.ProcessCSV(file), "invalid csv attribution header")
+	assert.Empty(s.T(), postgrestest.GetCCLFFilesByName(s.T(), s.db, file.metadata.name))
+
+	file = newFile("P.PCPB.M2411.D191012.T0209260", "MBIs\n1A00A00AA01\n1A00A00AA02,foo")
+	assert.ErrorContains(s.T(), s.importer.ProcessCSV(file), "failed to read csv attribution file")
+	assert.Empty(s.T(), postgrestest.GetCCLFFilesByName(s.T(), s.db, file.metadata.name))
+}
+
+func (s *CSVTestSuite) TestProcessCSVAttributionDelta_Integration() {
+	newFile := func(name string, timestamp time.Time, data string) csvFile {
+		return csvFile{
+			metadata: csvFileMetadata{
+				name:      name,
+				env:       "test",
+				acoID:     "DELTAACO",
+				cclfNum:   8,
+				perfYear:  24,
+				timestamp: timestamp,
+				fileType:  models.FileTypeDefault,
+			},
+			data: bytes.NewReader([]byte(data)),
+		}
+	}
+	repository := postgres.NewRepository(s.db)
+	ctx := context.Background()
+
+	// Every beneficiary in the first file is added
+	first := newFile("P.PCPB.M2411.D191008.T0209260", time.Now().Add(-time.Hour), "MBIS\n1A00A00AA01\n1A00A00AA02\n1A00A00AA03")
+	assert.NoError(s.T(), s.importer.ProcessCSV(first))
+	firstRecord := postgrestest.GetCCLFFilesByName(s.T(), s.db, first.metadata.name)
+	assert.Len(s.T(), firstRecord, 1)
+	delta, err := repository.GetCCLFAttributionDelta(ctx, firstRecord[0].ID)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), uint(0), delta.PreviousFileID)
+	assert.Equal(s.T(), []string{"1A00A00AA01", "1A00A00AA02", "1A00A00AA03"}, delta.AddedMBIs)
+	assert.Equal(s.T(), 3, delta.AddedCount)
+
+	second := newFile("P.PCPB.M2411.D191009.T0209260", time.Now(), "MBIS\n1A00A00AA02\n1A00A00AA03\n1A00A00AA04")
+	assert.NoError(s.T(), s.importer.ProcessCSV(second))
+	secondRecord := postgrestest.GetCCLFFilesByName(s.T(), s.db, second.metadata.name)
+	assert.Len(s.T(), secondRecord, 1)
+	delta, err = repository.GetCCLFAttributionDelta(ctx, secondRecord[0].ID)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), firstRecord[0].ID, delta.PreviousFileID)
+	assert.Equal(s.T(), []string{"1A00A00AA04"}, delta.AddedMBIs)
+	assert.Equal(s.T(), []string{"1A00A00AA01"}, delta.RemovedMBIs)
+	assert.Equal(s.T(), 1, delta.AddedCount)
+	assert.Equal(s.T(), 1, delta.RemovedCount)
+	assert.Equal(s.T(), 2, delta.RetainedCount)
+}
+
+func TestCSVAttributionSource(t *testing.T) {
 	tests := []struct {
-		name     string
-		data     *bytes.Reader
-		err      error
-		expected [][]interface{}
+		name        string
+		data        *bytes.Reader
+		err         error
+		expected    [][]interface{}
+		quarantined []quarantinedRecord
+		duplicates  int
 	}{
-		{"Valid CSV file with content", bytes.NewReader([]byte("MBIS\nMBI000001\nMBI000002\nMBI000003")), nil, [][]interface{}{
-			{uint(1), "MBI000001"},
-			{uint(1), "MBI000002"},
-			{uint(1), "MBI000003"},
-		}},
-		{"Empty CSV file", bytes.NewReader([]byte("")), errors.New("empty attribution file"), [][]interface{}(nil)},
-		{"Valid CSV file with unexpected content - more columns than headers", bytes.NewReader([]byte("MBIS\nMBI000001,10\nMBI000002,bar\nMBI000003,")), errors.New("failed to read csv attribution file"), [][]interface{}(nil)},
-		{"Valid CSV file with unexpected content - extra column and header", bytes.NewReader([]byte("MBIS,foo\nMBI000001,10\nMBI000002,bar\nMBI000003,")), nil, [][]interface{}{
-			{uint(1), "MBI000001"},
-			{uint(1), "MBI000002"},
-			{uint(1), "MBI000003"},
-		}},
+		{"Valid CSV file with content", bytes.NewReader([]byte("MBIS\n1A00A00AA01\n1A00A00AA02\n1A00A00AA03")), nil, [][]interface{}{
+			{uint(1), "1A00A00AA01"},
+			{uint(1), "1A00A00AA02"},
+			{uint(1), "1A00A00AA03"},
+		}, nil, 0},
+		{"Empty CSV file", bytes.NewReader([]byte("")), errors.New("empty attribution file"), [][]interface{}(nil), nil, 0},
+		{"Valid CSV file with unexpected content - more columns than headers", bytes.NewReader([]byte("MBIS\n1A00A00AA01,10\n1A00A00AA02,bar\n1A00A00AA03,")), errors.New("failed to read csv attribution file"), [][]interface{}(nil), nil, 0},
+		{"Valid CSV file with unexpected content - extra column and header", bytes.NewReader([]byte("MBIS,foo\n1A00A00AA01,10\n1A00A00AA02,bar\n1A00A00AA03,")), nil, [][]interface{}{
+			{uint(1), "1A00A00AA01"},
+			{uint(1), "1A00A00AA02"},
+			{uint(1), "1A00A00AA03"},
+		}, nil, 0},
+		{"Valid CSV file with invalid MBIs", bytes.NewReader([]byte("MBIS\n1A00A00AA01\nMBI000002\n   \n 1A00A00AA04 ")), nil, [][]interface{}{
+			{uint(1), "1A00A00AA01"},
+			{uint(1), "1A00A00AA04"},
+		}, []quarantinedRecord{
+			{recordNumber: 2, mbi: "MBI000002", reason: "invalid MBI length 9"},
+			{recordNumber: 3, mbi: "", reason: "missing MBI"},
+		}, 0},
+		{"Valid CSV file with duplicate MBIs", bytes.NewReader([]byte("MBIs\n1A00A00AA01\n1A00A00AA02\n1A00A00AA01\n 1A00A00AA02 ")), nil, [][]interface{}{
+			{uint(1), "1A00A00AA01"},
+			{uint(1), "1A00A00AA02"},
+		}, nil, 2},
+		{"Valid CSV file with byte order mark", bytes.NewReader([]byte("\ufeffMBI\n1A00A00AA01")), nil, [][]interface{}{
+			{uint(1), "1A00A00AA01"},
+		}, nil, 0},
+		{"Invalid header", bytes.NewReader([]byte("1A00A00AA01\n1A00A00AA02")), errors.New("invalid csv attribution header"), [][]interface{}(nil), nil, 0},
+		{"Invalid header - MBIs are not the first column", bytes.NewReader([]byte("foo,MBIS\nbar,1A00A00AA01")), errors.New("invalid csv attribution header"), [][]interface{}(nil), nil, 0},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			fmt.Print(test.name)
-			rows, _, err := c.prepareCSVData(test.data, uint(1))
+			source, err := newCSVAttributionSource(test.data, uint(1))
+			var rows [][]interface{}
+			if err == nil {
+				for source.Next() {
+					row, err := source.Values()
+					assert.NoError(t, err)
+					rows = append(rows, row)
+				}
+				err = source.Err()
+				assert.Equal(t, test.quarantined, source.quarantined)
+				assert.Equal(t, test.duplicates, source.duplicateCount)
+				assert.Equal(t, len(rows), source.importCount)
+			}
+
 			assert.Equal(t, test.expected, rows)
-			if err != nil {
-				assert.Contains(t, err.Error(), test.err.Error())
+			if test.err != nil {
+				assert.ErrorContains(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
 			}
 		})
 	}
-
 }
@@ -0,0 +1,77 @@
+package cclf
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/CMSgov/bcda-app/bcda/constants"
+	"github.com/CMSgov/bcda-app/bcda/models"
+)
+
+// recordAttributionDelta compares the beneficiaries attributed by the imported CCLF8 file with the ACO's previously
+// imported CCLF8 file of the same type and writes the resulting attribution delta.
+// It must be called before the file's import status is set to complete so that the file is not compared to itself.
+func recordAttributionDelta(ctx context.Context, r models.Repository, file models.CCLFFile) (*models.CCLFAttributionDelta, error) {
+	previous, err := r.GetLatestCCLFFile(ctx, file.ACOCMSID, file.CCLFNum, constants.ImportComplete, time.Time{}, file.Timestamp, file.Type)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get previous CCLF%d file for cmsID %s: %w", file.CCLFNum, file.ACOCMSID, err)
+	}
+
+	mbis, err := r.GetCCLFBeneficiaryMBIs(ctx, file.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MBIs for CCLF file %d: %w", file.ID, err)
+	}
+
+	var (
+		previousFileID uint
+		previousMBIs   []string
+	)
+	if previous != nil {
+		previousFileID = previous.ID
+		if previousMBIs, err = r.GetCCLFBeneficiaryMBIs(ctx, previous.ID); err != nil {
+			return nil, fmt.Errorf("failed to get MBIs for CCLF file %d: %w", previous.ID, err)
+		}
+	}
+
+	delta := newAttributionDelta(file.ID, previousFileID, previousMBIs, mbis)
+	if delta.ID, err = r.CreateCCLFAttributionDelta(ctx, delta); err != nil {
+		return nil, fmt.Errorf("failed to create attribution delta for CCLF file %d: %w", file.ID, err)
+	}
+
+	return &delta, nil
+}
+
+// newAttributionDelta returns the MBIs that were added and removed between the previous and current files,
+// along with the number of MBIs present in both. Duplicate MBIs are only counted once.
+func newAttributionDelta(fileID, previousFileID uint, previousMBIs, mbis []string) models.CCLFAttributionDelta {
+	current := make(map[string]struct{}, len(mbis))
+	for _, mbi := range mbis {
+		current[mbi] = struct{}{}
+	}
+	previous := make(map[string]struct{}, len(previousMBIs))
+	for _, mbi := range previousMBIs {
+		previous[mbi] = struct{}{}
+	}
+
+	delta := models.CCLFAttributionDelta{FileID: fileID, PreviousFileID: previousFileID}
+	for mbi := range current {
+		if _, ok := previous[mbi]; ok {
+			delta.RetainedCount++
+		} else {
+			delta.AddedMBIs = append(delta.AddedMBIs, mbi)
+		}
+	}
+	for mbi := range previous {
+		if _, ok := current[mbi]; !ok {
+			delta.RemovedMBIs = append(delta.RemovedMBIs, mbi)
+		}
+	}
+
+	sort.Strings(delta.AddedMBIs)
+	sort.Strings(delta.RemovedMBIs)
+	delta.AddedCount, delta.RemovedCount = len(delta.AddedMBIs), len(delta.RemovedMBIs)
+
+	return delta
+}
@@ -0,0 +1,81 @@
+package cclf
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/CMSgov/bcda-app/bcda/constants"
+	"github.com/CMSgov/bcda-app/bcda/models"
+	"github.com/CMSgov/bcda-app/bcda/testUtils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNewAttributionDelta(t *testing.T) {
+	tests := []struct {
+		name         string
+		previousMBIs []string
+		mbis         []string
+		expected     models.CCLFAttributionDelta
+	}{
+		{"NoPreviousFile", nil, []string{"3", "1", "2"},
+			models.CCLFAttributionDelta{AddedMBIs: []string{"1", "2", "3"}, AddedCount: 3}},
+		{"AddedRemovedAndRetained", []string{"1", "2", "3"}, []string{"2", "3", "5", "4"},
+			models.CCLFAttributionDelta{AddedMBIs: []string{"4", "5"}, RemovedMBIs: []string{"1"}, AddedCount: 2, RemovedCount: 1, RetainedCount: 2}},
+		{"Unchanged", []string{"1", "2"}, []string{"2", "1"},
+			models.CCLFAttributionDelta{RetainedCount: 2}},
+		{"Duplicates", []string{"1", "1", "2"}, []string{"2", "2", "3", "3"},
+			models.CCLFAttributionDelta{AddedMBIs: []string{"3"}, RemovedMBIs: []string{"1"}, AddedCount: 1, RemovedCount: 1, RetainedCount: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expected.FileID, tt.expected.PreviousFileID = 2, 1
+			assert.Equal(t, tt.expected, newAttributionDelta(2, 1, tt.previousMBIs, tt.mbis))
+		})
+	}
+}
+
+func TestRecordAttributionDelta(t *testing.T) {
+	file := models.CCLFFile{ID: 2, CCLFNum: 8, ACOCMSID: "A0001", Timestamp: time.Now(), Type: models.FileTypeDefault}
+	previousFile := &models.CCLFFile{ID: 1}
+
+	tests := []struct {
+		name         string
+		previousFile *models.CCLFFile
+		createErr    error
+		expected     *models.CCLFAttributionDelta
+		expectedErr  string
+	}{
+		{"WithPreviousFile", previousFile, nil,
+			&models.CCLFAttributionDelta{ID: 3, FileID: 2, PreviousFileID: 1, AddedMBIs: []string{"3"}, RemovedMBIs: []string{"1"}, AddedCount: 1, RemovedCount: 1, RetainedCount: 1}, ""},
+		{"WithoutPreviousFile", nil, nil,
+			&models.CCLFAttributionDelta{ID: 3, FileID: 2, AddedMBIs: []string{"2", "3"}, AddedCount: 2}, ""},
+		{"CreateError", previousFile, errors.New("database error"), nil, "failed to create attribution delta for CCLF file 2: database error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &models.MockRepository{}
+			repository.On("GetLatestCCLFFile", testUtils.CtxMatcher, "A0001", 8, constants.ImportComplete, time.Time{}, file.Timestamp, models.FileTypeDefault).Return(tt.previousFile, nil)
+			repository.On("GetCCLFBeneficiaryMBIs", testUtils.CtxMatcher, uint(1)).Return([]string{"1", "2"}, nil)
+			repository.On("GetCCLFBeneficiaryMBIs", testUtils.CtxMatcher, uint(2)).Return([]string{"2", "3"}, nil)
+			repository.On("CreateCCLFAttributionDelta", testUtils.CtxMatcher, mock.Anything).Return(uint(3), tt.createErr)
+
+			delta, err := recordAttributionDelta(context.Background(), repository, file)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				assert.Nil(t, delta)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, delta)
+
+			created := *tt.expected
+			created.ID = 0
+			repository.AssertCalled(t, "CreateCCLFAttributionDelta", testUtils.CtxMatcher, created)
+		})
+	}
+}
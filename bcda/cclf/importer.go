@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/CMSgov/bcda-app/bcda/cclf/metrics"
+	"github.com/CMSgov/bcda-app/bcda/utils"
 	"github.com/jackc/pgx"
 	"github.com/jackc/pgx/pgtype"
 	"github.com/sirupsen/logrus"
@@ -24,6 +25,7 @@ type cclf8Importer struct {
 	recordCount          int
 	importCount          int
 	processedMBIs        map[string]struct{}
+	quarantined          []quarantinedRecord
 	logger               logrus.FieldLogger
 	expectedRecordLength int
 }
@@ -31,10 +33,10 @@ type cclf8Importer struct {
 func (importer *cclf8Importer) Next() bool {
 	// Loops through the scanner until we either:
 	// 1. Encounter the end of the data
-	// 2. Find an MBI that we have not processed yet
+	// 2. Find a valid MBI that we have not processed yet
 	//
 	// This logic exists in the Next() function because it
-	// is the only way for us to ignore an already ingested MBI.
+	// is the only way for us to ignore an already ingested or invalid MBI.
 	// If we made this check in Values() and return an error or
 	// return empty data, then the copy will fail.
 	// NOTE: This choice was based on pgx v3.1.0.
@@ -49,6 +51,11 @@ func (importer *cclf8Importer) Next() bool {
 
 		importer.recordCount++
 		mbi := importer.getMBI()
+		if err := utils.ValidateMBI(mbi); err != nil {
+			importer.quarantined = append(importer.quarantined, quarantinedRecord{recordNumber: importer.recordCount, mbi: mbi, reason: err.Error()})
+			continue
+		}
+
 		// We've already processed this MBI before
 		if _, found := importer.processedMBIs[mbi]; found {
 			continue
@@ -109,10 +116,13 @@ func (importer *cclf8Importer) getMBI() string {
 		mbiStart, mbiEnd = 0, 11
 	)
 	b := importer.scanner.Bytes()
-	return string(bytes.TrimSpace(b[mbiStart:mbiEnd]))
+	// Short records are returned whole so that they are reported as an invalid MBI
+	return string(bytes.TrimSpace(b[mbiStart:min(mbiEnd, len(b))]))
 }
 
 // CopyFrom writes all of the beneficiary data captured in the scanner to the beneficiaries table.
+// Records with an invalid MBI are written to the quarantine table instead, and an error is returned
+// if too many records are quarantined.
 // It returns the number of rows written along with any error that occurred.
 func CopyFrom(ctx context.Context, tx *pgx.Tx, scanner *bufio.Scanner, fileID uint, reportInterval int, logger logrus.FieldLogger, expectedRecordLength int) (int, int, error) {
 	importer := &cclf8Importer{
@@ -127,5 +137,16 @@ func CopyFrom(ctx context.Context, tx *pgx.Tx, scanner *bufio.Scanner, fileID ui
 	}
 	tableName := pgx.Identifier([]string{"cclf_beneficiaries"})
 	importedCount, err := tx.CopyFrom(tableName, []string{"file_id", "mbi"}, importer)
+	if err != nil {
+		return importedCount, importer.recordCount, err
+	}
+
+	if len(importer.quarantined) > 0 {
+		logger.Warnf("Quarantined %d CCLF8 records with an invalid MBI", len(importer.quarantined))
+	}
+	if err = checkRejectRate(importer.quarantined, importer.recordCount); err != nil {
+		return importedCount, importer.recordCount, err
+	}
+	err = quarantine(tx, fileID, importer.quarantined)
 	return importedCount, importer.recordCount, err
 }
@@ -28,6 +28,27 @@ func TestNext(t *testing.T) {
 	assert.False(t, importer.Next())
 }
 
+func TestNextInvalidMBIs(t *testing.T) {
+	mbi1, mbi2 := testUtils.RandomMBI(t), testUtils.RandomMBI(t)
+	// Invalid MBIs are skipped and quarantined, including duplicates
+	mbis := []string{mbi1, "1234", "MBI00000001", mbi2, "MBI00000001"}
+	scanner := bufio.NewScanner(strings.NewReader(strings.Join(mbis, "\n")))
+
+	importer := &cclf8Importer{processedMBIs: make(map[string]struct{}), scanner: scanner}
+	for _, expected := range []string{mbi1, mbi2} {
+		assert.True(t, importer.Next())
+		assert.Equal(t, expected, string(importer.scanner.Bytes()))
+	}
+
+	assert.False(t, importer.Next())
+	assert.Equal(t, 5, importer.recordCount)
+	assert.Equal(t, []quarantinedRecord{
+		{recordNumber: 2, mbi: "1234", reason: "invalid MBI length 4"},
+		{recordNumber: 3, mbi: "MBI00000001", reason: "invalid MBI format"},
+		{recordNumber: 5, mbi: "MBI00000001", reason: "invalid MBI format"},
+	}, importer.quarantined)
+}
+
 func TestValues(t *testing.T) {
 	mbi := testUtils.RandomMBI(t)
 	scanner := bufio.NewScanner(strings.NewReader(mbi))
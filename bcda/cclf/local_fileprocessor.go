@@ -2,9 +2,9 @@ package cclf
 
 import (
 	"archive/zip"
-	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	fp "path/filepath"
 	"strings"
@@ -114,8 +114,8 @@ func (p *processor) walk(path string, info os.FileInfo, err error) error {
 		return p.handleArchiveError(path, info, fmt.Errorf("cmsID %s not supported", cmsID))
 	}
 
-	var cclf0Metadata, cclf8Metadata *cclfFileMetadata
-	var cclf0File, cclf8File *zip.File
+	var cclf0Metadata, cclf8Metadata, cclf9Metadata *cclfFileMetadata
+	var cclf0File, cclf8File, cclf9File *zip.File
 	var readError error
 
 	for _, f := range zipReader.File {
@@ -144,6 +144,13 @@ func (p *processor) walk(path string, info os.FileInfo, err error) error {
 			}
 			cclf8Metadata = &metadata
 			cclf8File = f
+		} else if metadata.cclfNum == 9 {
+			if cclf9Metadata != nil {
+				readError = fmt.Errorf("multiple CCLF9 files found in zip (%s)", path)
+				break
+			}
+			cclf9Metadata = &metadata
+			cclf9File = f
 		} else {
 			readError = fmt.Errorf("unexpected CCLF num %d processed (%s)", metadata.cclfNum, path)
 			break
@@ -172,6 +179,8 @@ func (p *processor) walk(path string, info os.FileInfo, err error) error {
 			cclf8Metadata: *cclf8Metadata,
 			cclf0File:     *cclf0File,
 			cclf8File:     *cclf8File,
+			cclf9Metadata: cclf9Metadata,
+			cclf9File:     cclf9File,
 			filePath:      path,
 		}
 
@@ -325,17 +334,15 @@ func (processor *LocalFileProcessor) CleanUpCSV(file csvFile) error {
 	return err
 }
 
-func (processor *LocalFileProcessor) LoadCSV(filepath string) (*bytes.Reader, func(), error) {
+func (processor *LocalFileProcessor) LoadCSV(filepath string) (io.Reader, func(), error) {
 	c := fp.Clean(filepath)
 	if !strings.HasPrefix(filepath, "/tmp") {
 		return nil, nil, fmt.Errorf("invalid path, %s", filepath)
 	}
-	byte_arr, err := os.ReadFile(c)
+	file, err := os.Open(c)
 	if err != nil {
 		return nil, nil, err
 	}
-	reader := bytes.NewReader(byte_arr)
-
-	return reader, func() {}, err
 
+	return file, func() { utils.CloseAndLog(logrus.WarnLevel, file.Close) }, nil
 }
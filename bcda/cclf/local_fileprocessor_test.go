@@ -131,6 +131,25 @@ func (s *LocalFileProcessorTestSuite) TestProcessCCLFArchives() {
 	}
 }
 
+func (s *LocalFileProcessorTestSuite) TestProcessCCLFArchives_CCLF9() {
+	cclfMap, _, _, err := processCCLFArchives(filepath.Join(s.basePath, "cclf/archives/valid/"))
+	assert.NoError(s.T(), err)
+
+	// Only the A0001 archive delivers the optional CCLF9 crosswalk file
+	assert.Len(s.T(), cclfMap["A0001"], 1)
+	cclfZipFile := cclfMap["A0001"][0]
+	if assert.NotNil(s.T(), cclfZipFile.cclf9Metadata) {
+		assert.Equal(s.T(), 9, cclfZipFile.cclf9Metadata.cclfNum)
+		assert.Equal(s.T(), "T.BCD.A0001.ZC9Y18.D181120.T1000010", cclfZipFile.cclf9Metadata.name)
+		assert.Equal(s.T(), cclfZipFile.cclf9Metadata.name, cclfZipFile.cclf9File.Name)
+	}
+
+	for _, cclfZipFile := range cclfMap["A9989"] {
+		assert.Nil(s.T(), cclfZipFile.cclf9Metadata)
+		assert.Nil(s.T(), cclfZipFile.cclf9File)
+	}
+}
+
 func (s *LocalFileProcessorTestSuite) TestProcessCCLFArchives_ExpireFiles() {
 	cmsID := "A0001"
 
@@ -412,7 +431,7 @@ func (s *LocalFileProcessorTestSuite) TestCleanUpCSV() {
 			fileID:    0,
 			fileType:  1,
 		},
-		data: bytes.NewReader([]byte("MBIS\nMBI000001\nMBI000002\nMBI000003")),
+		data: bytes.NewReader([]byte("MBIS\n1A00A00AA01\n1A00A00AA02\n1A00A00AA03")),
 	}
 
 	tests := []struct {
@@ -170,11 +170,11 @@ func getCCLFFileMetadata(cmsID, fileName string) (cclfFileMetadata, error) {
 	var metadata cclfFileMetadata
 	const (
 		prefix = `(P|T)\.`
-		suffix = `\.ZC(0|8)(Y|R)(\d{2})\.(D\d{6}\.T\d{6})\d`
+		suffix = `\.ZC(0|8|9)(Y|R)(\d{2})\.(D\d{6}\.T\d{6})\d`
 		aco    = `(?:\.ACO)`
 		bcd    = `(?:BCD\.)`
 
-		// CCLF filename convention for SSP with BCD identifier: P.BCD.A****.ZC[0|8][Y|R]**.Dyymmdd.Thhmmsst
+		// CCLF filename convention for SSP with BCD identifier: P.BCD.A****.ZC[0|8|9][Y|R]**.Dyymmdd.Thhmmsst
 		ssp = `A\d{4}`
 		// CCLF filename convention for NGACO: P.V***.ACO.ZC[0|8][Y|R].Dyymmdd.Thhmmsst
 		ngaco = `V\d{3}`
@@ -205,6 +205,7 @@ func TestGetCCLFMetadata(t *testing.T) {
 	assert.NoError(t, err)
 	sspProdFile, sspTestFile, sspRunoutFile := gen(sspProd, validTime), gen(sspTest, validTime),
 		strings.Replace(gen(sspProd, validTime), "ZC8Y", "ZC8R", 1)
+	sspCrosswalkFile := strings.Replace(gen(sspProd, validTime), "ZC8Y", "ZC9Y", 1)
 	cecProdFile, cecTestFile := gen(cecProd, validTime), gen(cecTest, validTime)
 	ngacoProdFile, ngacoTestFile := gen(ngacoProd, validTime), gen(ngacoTest, validTime)
 	ckccProdFile, ckccTestFile := gen(ckccProd, validTime), gen(ckccTest, validTime)
@@ -220,7 +221,7 @@ func TestGetCCLFMetadata(t *testing.T) {
 		errMsg   string
 		metadata cclfFileMetadata
 	}{
-		{"Non CCLF0 or CCLF8 file", sspID, "P.A0001.ACO.ZC9Y18.D190108.T2355000", "invalid filename", cclfFileMetadata{}},
+		{"Non CCLF0, CCLF8, or CCLF9 file", sspID, "P.BCD.A0001.ZC7Y18.D190108.T2355000", "invalid filename", cclfFileMetadata{}},
 		{"Unsupported CCLF file type", "Z9999", "P.Z0001.ACO.ZC8Y18.D190108.T2355000", "invalid filename", cclfFileMetadata{}},
 		{"Unsupported CSV file type", sspID, "P.PCPB.M2014.D00302.T2420001", "invalid filename", cclfFileMetadata{}},
 		{"Invalid date (no 13th month)", sspID, "T.BCD.A0001.ZC0Y18.D181320.T0001000", "failed to parse date", cclfFileMetadata{}},
@@ -250,6 +251,18 @@ func TestGetCCLFMetadata(t *testing.T) {
 				fileType:  models.FileTypeDefault,
 			},
 		},
+		{
+			"Production SSP CCLF9 file", sspID, sspCrosswalkFile, "",
+			cclfFileMetadata{
+				env:       "production",
+				name:      sspCrosswalkFile,
+				cclfNum:   9,
+				acoID:     sspID,
+				timestamp: validTime,
+				perfYear:  perfYear,
+				fileType:  models.FileTypeDefault,
+			},
+		},
 		{
 			"Runout SSP file", sspID, sspRunoutFile, "",
 			cclfFileMetadata{
@@ -0,0 +1,103 @@
+package cclf
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/CMSgov/bcda-app/bcda/constants"
+	"github.com/CMSgov/bcda-app/bcda/models"
+	"github.com/CMSgov/bcda-app/bcda/models/postgres"
+	"github.com/CMSgov/bcda-app/bcda/utils"
+	"github.com/jackc/pgx"
+)
+
+// quarantinedRecord is an attribution record that was not imported because its MBI is invalid
+type quarantinedRecord struct {
+	// recordNumber is the position of the record in the file, starting at 1 and excluding any header
+	recordNumber int
+	mbi          string
+	reason       string
+}
+
+// quarantine writes the records that were not imported to the cclf_quarantined_beneficiaries table
+func quarantine(tx *pgx.Tx, fileID uint, records []quarantinedRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	rows := make([][]interface{}, 0, len(records))
+	for _, r := range records {
+		rows = append(rows, []interface{}{fileID, r.recordNumber, r.mbi, r.reason})
+	}
+
+	_, err := tx.CopyFrom(pgx.Identifier{"cclf_quarantined_beneficiaries"}, []string{"file_id", "record_number", "mbi", "reason"}, pgx.CopyFromRows(rows))
+	if err != nil {
+		return fmt.Errorf("failed to write quarantined beneficiaries to database: %w", err)
+	}
+	return nil
+}
+
+// recordRejectedFile persists a file that was rejected for exceeding the reject rate, marked as failed, together with
+// its quarantined records. The import transaction is rolled back when the file is rejected, so this runs in a
+// transaction of its own once that rollback is complete.
+func recordRejectedFile(ctx context.Context, conn *pgx.Conn, file models.CCLFFile, records []quarantinedRecord) (err error) {
+	tx, err := conn.BeginEx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if err1 := tx.Rollback(); err1 != nil {
+				err = fmt.Errorf("%w (rollback failed: %s)", err, err1.Error())
+			}
+		}
+	}()
+
+	file.ImportStatus = constants.ImportFail
+	file.ID, err = postgres.NewRepositoryPgxTx(tx).CreateCCLFFile(ctx, file)
+	if err != nil {
+		return fmt.Errorf("failed to record rejected file %s: %w", file.Name, err)
+	}
+	if err = quarantine(tx, file.ID, records); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit rejected file %s: %w", file.Name, err)
+	}
+	return nil
+}
+
+// rejectRateError is returned when too many records are quarantined. It carries the quarantined records so that
+// they can be persisted with recordRejectedFile.
+type rejectRateError struct {
+	msg         string
+	quarantined []quarantinedRecord
+}
+
+func (e *rejectRateError) Error() string {
+	return e.msg
+}
+
+// checkRejectRate returns an error if the percentage of quarantined records exceeds ATTRIBUTION_MBI_REJECT_PCT.
+// The error reports the record numbers, rather than the MBIs, so that it can be logged.
+func checkRejectRate(records []quarantinedRecord, recordCount int) error {
+	if len(records) == 0 || recordCount == 0 {
+		return nil
+	}
+
+	threshold := utils.GetEnvInt("ATTRIBUTION_MBI_REJECT_PCT", 5)
+	rejectPct := float64(len(records)) / float64(recordCount) * 100
+	if rejectPct <= float64(threshold) {
+		return nil
+	}
+
+	recordNumbers := make([]int, 0, min(len(records), 10))
+	for _, r := range records[:cap(recordNumbers)] {
+		recordNumbers = append(recordNumbers, r.recordNumber)
+	}
+	return &rejectRateError{
+		msg: fmt.Sprintf("%d of %d records (%.2f%%) have an invalid MBI, exceeding the threshold of %d%% (first invalid records: %v)",
+			len(records), recordCount, rejectPct, threshold, recordNumbers),
+		quarantined: records,
+	}
+}
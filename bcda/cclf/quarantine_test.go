@@ -0,0 +1,53 @@
+package cclf
+
+import (
+	"testing"
+
+	"github.com/CMSgov/bcda-app/conf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRejectRate(t *testing.T) {
+	quarantined := make([]quarantinedRecord, 12)
+	for i := range quarantined {
+		quarantined[i] = quarantinedRecord{recordNumber: i + 1, mbi: "invalid", reason: "invalid MBI length 7"}
+	}
+
+	tests := []struct {
+		name        string
+		threshold   string
+		records     []quarantinedRecord
+		recordCount int
+		err         string
+	}{
+		{"NoQuarantinedRecords", "", nil, 100, ""},
+		{"BelowDefaultThreshold", "", quarantined[:4], 100, ""},
+		{"AtDefaultThreshold", "", quarantined[:5], 100, ""},
+		{"AboveDefaultThreshold", "", quarantined[:6], 100, "6 of 100 records (6.00%) have an invalid MBI, exceeding the threshold of 5% (first invalid records: [1 2 3 4 5 6])"},
+		{"AboveThresholdReportsFirstTen", "", quarantined, 100, "12 of 100 records (12.00%) have an invalid MBI, exceeding the threshold of 5% (first invalid records: [1 2 3 4 5 6 7 8 9 10])"},
+		{"ConfiguredThreshold", "20", quarantined, 100, ""},
+		{"AllRecordsInvalid", "", quarantined[:2], 2, "2 of 2 records (100.00%) have an invalid MBI, exceeding the threshold of 5% (first invalid records: [1 2])"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.threshold == "" {
+				conf.UnsetEnv(t, "ATTRIBUTION_MBI_REJECT_PCT")
+			} else {
+				conf.SetEnv(t, "ATTRIBUTION_MBI_REJECT_PCT", tt.threshold)
+			}
+			err := checkRejectRate(tt.records, tt.recordCount)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.err)
+
+			// The quarantined records are kept so that the rejected file can be recorded
+			var rejectErr *rejectRateError
+			assert.ErrorAs(t, err, &rejectErr)
+			assert.Equal(t, tt.records, rejectErr.quarantined)
+		})
+	}
+	conf.UnsetEnv(t, "ATTRIBUTION_MBI_REJECT_PCT")
+}
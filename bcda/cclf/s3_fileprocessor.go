@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"path/filepath"
 
 	"github.com/CMSgov/bcda-app/bcda/cclf/metrics"
@@ -61,8 +62,8 @@ func (processor *S3FileProcessor) LoadCclfFiles(path string) (cclfMap map[string
 			continue
 		}
 
-		var cclf0Metadata, cclf8Metadata *cclfFileMetadata
-		var cclf0File, cclf8File *zip.File
+		var cclf0Metadata, cclf8Metadata, cclf9Metadata *cclfFileMetadata
+		var cclf0File, cclf8File, cclf9File *zip.File
 		var readError error
 
 		for _, f := range zipReader.File {
@@ -89,6 +90,13 @@ func (processor *S3FileProcessor) LoadCclfFiles(path string) (cclfMap map[string
 				}
 				cclf8Metadata = &metadata
 				cclf8File = f
+			} else if metadata.cclfNum == 9 {
+				if cclf9Metadata != nil {
+					readError = fmt.Errorf("multiple CCLF9 files found in zip (%s/%s)", bucket, *obj.Key)
+					break
+				}
+				cclf9Metadata = &metadata
+				cclf9File = f
 			} else {
 				readError = fmt.Errorf("unexpected CCLF num %d processed (%s/%s)", metadata.cclfNum, bucket, *obj.Key)
 				break
@@ -112,6 +120,8 @@ func (processor *S3FileProcessor) LoadCclfFiles(path string) (cclfMap map[string
 				cclf8Metadata: *cclf8Metadata,
 				cclf0File:     *cclf0File,
 				cclf8File:     *cclf8File,
+				cclf9Metadata: cclf9Metadata,
+				cclf9File:     cclf9File,
 				filePath:      filepath.Join(bucket, *obj.Key),
 			}
 
@@ -193,17 +203,20 @@ func (processor *S3FileProcessor) CleanUpCSV(file csvFile) error {
 	return nil
 }
 
-func (processor *S3FileProcessor) LoadCSV(filepath string) (*bytes.Reader, func(), error) {
+func (processor *S3FileProcessor) LoadCSV(filepath string) (io.Reader, func(), error) {
 	if !optout.IsForCurrentEnv(filepath) {
 		processor.Handler.Infof("Skipping file for different environment: %s", filepath)
 		return nil, nil, &ers.AttributionFileMismatchedEnv{}
 	}
-	byte_arr, err := processor.Handler.OpenFileBytes(filepath)
+	body, err := processor.Handler.OpenFileStream(filepath)
 	if err != nil {
 		processor.Handler.Errorf("Failed to download %s\n", filepath)
 		return nil, nil, err
 	}
 
-	reader := bytes.NewReader(byte_arr)
-	return reader, func() {}, err
+	return body, func() {
+		if err := body.Close(); err != nil {
+			processor.Handler.Warningf("Could not close file %s: %s\n", filepath, err)
+		}
+	}, nil
 }
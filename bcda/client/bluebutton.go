@@ -46,13 +46,16 @@ const (
 type BlueButtonConfig struct {
 	BBServer   string
 	BBBasePath string
+	// Requests are not guarded by a circuit breaker when nil
+	CircuitBreaker *CircuitBreaker
 }
 
 // NewConfig generates a new BlueButtonConfig using various environment variables.
 func NewConfig(basePath string) BlueButtonConfig {
 	return BlueButtonConfig{
-		BBServer:   conf.GetEnv("BB_SERVER_LOCATION"),
-		BBBasePath: basePath,
+		BBServer:       conf.GetEnv("BB_SERVER_LOCATION"),
+		BBBasePath:     basePath,
+		CircuitBreaker: BlueButtonCircuitBreaker(),
 	}
 }
 
@@ -68,6 +71,21 @@ type APIClient interface {
 	GetPatientByMbi(jobData models.JobEnqueueArgs, mbi string) (string, error)
 	GetClaim(jobData models.JobEnqueueArgs, mbi string, claimsWindow ClaimsWindow) (*fhirModels.Bundle, error)
 	GetClaimResponse(jobData models.JobEnqueueArgs, mbi string, claimsWindow ClaimsWindow) (*fhirModels.Bundle, error)
+	GetObservation(jobData models.JobEnqueueArgs, patientID string, claimsWindow ClaimsWindow) (*fhirModels.Bundle, error)
+}
+
+// PageHandler is called with each page of a bundle as it is received
+type PageHandler func(page *fhirModels.Bundle) error
+
+// StreamingAPIClient retrieves bundles page by page instead of accumulating every page in memory
+type StreamingAPIClient interface {
+	APIClient
+	StreamExplanationOfBenefit(jobData models.JobEnqueueArgs, patientID string, claimsWindow ClaimsWindow, handle PageHandler) error
+	StreamPatient(jobData models.JobEnqueueArgs, patientID string, handle PageHandler) error
+	StreamCoverage(jobData models.JobEnqueueArgs, beneficiaryID string, handle PageHandler) error
+	StreamClaim(jobData models.JobEnqueueArgs, mbi string, claimsWindow ClaimsWindow, handle PageHandler) error
+	StreamClaimResponse(jobData models.JobEnqueueArgs, mbi string, claimsWindow ClaimsWindow, handle PageHandler) error
+	StreamObservation(jobData models.JobEnqueueArgs, patientID string, claimsWindow ClaimsWindow, handle PageHandler) error
 }
 
 type BlueButtonClient struct {
@@ -81,7 +99,7 @@ type BlueButtonClient struct {
 }
 
 // Ensure BlueButtonClient satisfies the interface
-var _ APIClient = &BlueButtonClient{}
+var _ StreamingAPIClient = &BlueButtonClient{}
 
 func NewBlueButtonClient(config BlueButtonConfig) (*BlueButtonClient, error) {
 	certFile := conf.GetEnv("BB_CLIENT_CERT_FILE")
@@ -129,8 +147,11 @@ func NewBlueButtonClient(config BlueButtonConfig) (*BlueButtonClient, error) {
 	}
 
 	hl := &httpLogger{transport, logger}
-	httpClient := &http.Client{Transport: hl, Timeout: time.Duration(timeout) * time.Millisecond}
+	httpClient := &http.Client{Transport: &rateLimitedTransport{hl}, Timeout: time.Duration(timeout) * time.Millisecond}
 	client := fhir.NewClient(httpClient, pageSize)
+	if config.CircuitBreaker != nil {
+		client = &circuitBreakerClient{client, config.CircuitBreaker}
+	}
 	maxTries, err := safecast.ToUint64(utils.GetEnvInt("BB_REQUEST_MAX_TRIES", 3))
 	if err != nil {
 		logger.Warn(errors.Wrap(err, "Could not convert Blue Button max retries from environment variable"))
@@ -147,6 +168,12 @@ func SetLogger(log logrus.FieldLogger) {
 }
 
 func (bbc *BlueButtonClient) GetPatient(jobData models.JobEnqueueArgs, patientID string) (*fhirModels.Bundle, error) {
+	return CollectPages(func(handle PageHandler) error {
+		return bbc.StreamPatient(jobData, patientID, handle)
+	})
+}
+
+func (bbc *BlueButtonClient) StreamPatient(jobData models.JobEnqueueArgs, patientID string, handle PageHandler) error {
 	header := make(http.Header)
 	header.Add("IncludeAddressFields", "true")
 	params := GetDefaultParams()
@@ -155,10 +182,10 @@ func (bbc *BlueButtonClient) GetPatient(jobData models.JobEnqueueArgs, patientID
 
 	u, err := bbc.getURL("Patient", params)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return bbc.makeBundleDataRequest("GET", u, jobData, header, nil)
+	return bbc.streamBundleDataRequest("GET", u, jobData, header, nil, handle)
 }
 
 func (bbc *BlueButtonClient) GetPatientByMbi(jobData models.JobEnqueueArgs, mbi string) (string, error) {
@@ -175,49 +202,75 @@ func (bbc *BlueButtonClient) GetPatientByMbi(jobData models.JobEnqueueArgs, mbi
 }
 
 func (bbc *BlueButtonClient) GetCoverage(jobData models.JobEnqueueArgs, beneficiaryID string) (*fhirModels.Bundle, error) {
+	return CollectPages(func(handle PageHandler) error {
+		return bbc.StreamCoverage(jobData, beneficiaryID, handle)
+	})
+}
+
+func (bbc *BlueButtonClient) StreamCoverage(jobData models.JobEnqueueArgs, beneficiaryID string, handle PageHandler) error {
 	params := GetDefaultParams()
 	params.Set("beneficiary", beneficiaryID)
 	updateParamWithLastUpdated(&params, jobData.Since, jobData.TransactionTime)
 
 	u, err := bbc.getURL("Coverage", params)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return bbc.makeBundleDataRequest("GET", u, jobData, nil, nil)
+	return bbc.streamBundleDataRequest("GET", u, jobData, nil, nil, handle)
 }
 
 func (bbc *BlueButtonClient) GetClaim(jobData models.JobEnqueueArgs, mbi string, claimsWindow ClaimsWindow) (*fhirModels.Bundle, error) {
+	return CollectPages(func(handle PageHandler) error {
+		return bbc.StreamClaim(jobData, mbi, claimsWindow, handle)
+	})
+}
+
+func (bbc *BlueButtonClient) StreamClaim(jobData models.JobEnqueueArgs, mbi string, claimsWindow ClaimsWindow, handle PageHandler) error {
 	headers := createURLEncodedHeader()
 	params := GetDefaultParams()
 	updateParamsWithClaimsDefaults(&params, mbi)
 	updateParamWithServiceDate(&params, claimsWindow)
 	updateParamWithLastUpdated(&params, jobData.Since, jobData.TransactionTime)
+	updateParamWithTypeFilter(&params, jobData.TypeFilter)
 
 	u, err := bbc.getURL("Claim/_search", url.Values{})
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return bbc.makeBundleDataRequest("POST", u, jobData, headers, strings.NewReader(params.Encode()))
+	return bbc.streamBundleDataRequest("POST", u, jobData, headers, strings.NewReader(params.Encode()), handle)
 }
 
 func (bbc *BlueButtonClient) GetClaimResponse(jobData models.JobEnqueueArgs, mbi string, claimsWindow ClaimsWindow) (*fhirModels.Bundle, error) {
+	return CollectPages(func(handle PageHandler) error {
+		return bbc.StreamClaimResponse(jobData, mbi, claimsWindow, handle)
+	})
+}
+
+func (bbc *BlueButtonClient) StreamClaimResponse(jobData models.JobEnqueueArgs, mbi string, claimsWindow ClaimsWindow, handle PageHandler) error {
 	headers := createURLEncodedHeader()
 	params := GetDefaultParams()
 	updateParamsWithClaimsDefaults(&params, mbi)
 	updateParamWithServiceDate(&params, claimsWindow)
 	updateParamWithLastUpdated(&params, jobData.Since, jobData.TransactionTime)
+	updateParamWithTypeFilter(&params, jobData.TypeFilter)
 
 	u, err := bbc.getURL("ClaimResponse/_search", url.Values{})
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return bbc.makeBundleDataRequest("POST", u, jobData, headers, strings.NewReader(params.Encode()))
+	return bbc.streamBundleDataRequest("POST", u, jobData, headers, strings.NewReader(params.Encode()), handle)
 }
 
 func (bbc *BlueButtonClient) GetExplanationOfBenefit(jobData models.JobEnqueueArgs, patientID string, claimsWindow ClaimsWindow) (*fhirModels.Bundle, error) {
+	return CollectPages(func(handle PageHandler) error {
+		return bbc.StreamExplanationOfBenefit(jobData, patientID, claimsWindow, handle)
+	})
+}
+
+func (bbc *BlueButtonClient) StreamExplanationOfBenefit(jobData models.JobEnqueueArgs, patientID string, claimsWindow ClaimsWindow, handle PageHandler) error {
 	header := make(http.Header)
 	header.Add("IncludeTaxNumbers", "true")
 	params := GetDefaultParams()
@@ -226,13 +279,36 @@ func (bbc *BlueButtonClient) GetExplanationOfBenefit(jobData models.JobEnqueueAr
 
 	updateParamWithServiceDate(&params, claimsWindow)
 	updateParamWithLastUpdated(&params, jobData.Since, jobData.TransactionTime)
+	updateParamWithTypeFilter(&params, jobData.TypeFilter)
 
 	u, err := bbc.getURL("ExplanationOfBenefit", params)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	return bbc.streamBundleDataRequest("GET", u, jobData, header, nil, handle)
+}
+
+func (bbc *BlueButtonClient) GetObservation(jobData models.JobEnqueueArgs, patientID string, claimsWindow ClaimsWindow) (*fhirModels.Bundle, error) {
+	return CollectPages(func(handle PageHandler) error {
+		return bbc.StreamObservation(jobData, patientID, claimsWindow, handle)
+	})
+}
+
+func (bbc *BlueButtonClient) StreamObservation(jobData models.JobEnqueueArgs, patientID string, claimsWindow ClaimsWindow, handle PageHandler) error {
+	params := GetDefaultParams()
+	params.Set("patient", patientID)
+
+	// Observations are filtered on their effective date rather than a service date
+	updateParamWithDateRange(&params, "date", claimsWindow)
+	updateParamWithLastUpdated(&params, jobData.Since, jobData.TransactionTime)
+
+	u, err := bbc.getURL("Observation", params)
+	if err != nil {
+		return err
 	}
 
-	return bbc.makeBundleDataRequest("GET", u, jobData, header, nil)
+	return bbc.streamBundleDataRequest("GET", u, jobData, nil, nil, handle)
 }
 
 func (bbc *BlueButtonClient) GetMetadata() (string, error) {
@@ -245,43 +321,22 @@ func (bbc *BlueButtonClient) GetMetadata() (string, error) {
 	return bbc.getRawData("GET", jobData, u, nil, nil)
 }
 
-func (bbc *BlueButtonClient) makeBundleDataRequest(method string, u *url.URL, jobData models.JobEnqueueArgs, headers http.Header, body io.Reader) (*fhirModels.Bundle, error) {
-	var b *fhirModels.Bundle
-	for ok := true; ok; {
-		result, nextURL, err := bbc.tryBundleRequest(method, u, jobData, headers, body)
-		if err != nil {
-			return nil, err
-		}
-
-		if b == nil {
-			b = result
-		} else {
-			b.Entries = append(b.Entries, result.Entries...)
-		}
-
-		u = nextURL
-		ok = nextURL != nil
-	}
-
-	return b, nil
-}
-
 func (bbc *BlueButtonClient) tryBundleRequest(method string, u *url.URL, jobData models.JobEnqueueArgs, headers http.Header, body io.Reader) (*fhirModels.Bundle, *url.URL, error) {
 	m := monitoring.GetMonitor()
 	txn := m.Start(u.Path, nil, nil)
 	defer m.End(txn)
 
 	var (
-		result  *fhirModels.Bundle
-		nextURL *url.URL
-		err     error
+		result   *fhirModels.Bundle
+		nextURL  *url.URL
+		err      error
+		attempts int
 	)
 
-	eb := backoff.NewExponentialBackOff()
-	eb.InitialInterval = bbc.retryInterval
-	b := backoff.WithMaxRetries(eb, bbc.maxTries)
+	b := newRetryBackOff(bbc.retryInterval, bbc.maxTries)
 
 	err = backoff.RetryNotify(func() error {
+		attempts++
 		req, err := http.NewRequest(method, u.String(), body)
 		if err != nil {
 			logger.Error(err)
@@ -296,10 +351,10 @@ func (bbc *BlueButtonClient) tryBundleRequest(method string, u *url.URL, jobData
 		addDefaultRequestHeaders(req, uuid.NewRandom(), jobData)
 
 		result, nextURL, err = bbc.client.DoBundleRequest(req)
-		if err != nil {
+		if err != nil && !errors.Is(err, ErrCircuitOpen) {
 			logger.Error(err)
 		}
-		return err
+		return b.check(err)
 	},
 		b,
 		func(err error, d time.Duration) {
@@ -307,8 +362,11 @@ func (bbc *BlueButtonClient) tryBundleRequest(method string, u *url.URL, jobData
 		},
 	)
 
+	if errors.Is(err, ErrCircuitOpen) {
+		return nil, nil, err
+	}
 	if err != nil {
-		return nil, nil, fmt.Errorf("blue button request failed %d time(s) %s", bbc.maxTries, err.Error())
+		return nil, nil, &RequestError{Attempts: attempts, Err: err}
 	}
 
 	return result, nextURL, nil
@@ -319,13 +377,15 @@ func (bbc *BlueButtonClient) getRawData(method string, jobData models.JobEnqueue
 	txn := m.Start(u.Path, nil, nil)
 	defer m.End(txn)
 
-	eb := backoff.NewExponentialBackOff()
-	eb.InitialInterval = bbc.retryInterval
-	b := backoff.WithMaxRetries(eb, bbc.maxTries)
+	b := newRetryBackOff(bbc.retryInterval, bbc.maxTries)
 
-	var result string
+	var (
+		result   string
+		attempts int
+	)
 
 	err := backoff.RetryNotify(func() error {
+		attempts++
 		req, err := http.NewRequest(method, u.String(), body)
 		if err != nil {
 			logger.Error(err)
@@ -341,10 +401,10 @@ func (bbc *BlueButtonClient) getRawData(method string, jobData models.JobEnqueue
 		addDefaultRequestHeaders(req, uuid.NewRandom(), jobData)
 
 		result, err = bbc.client.DoRaw(req)
-		if err != nil {
+		if err != nil && !errors.Is(err, ErrCircuitOpen) {
 			logger.Error(err)
 		}
-		return err
+		return b.check(err)
 	},
 		b,
 		func(err error, d time.Duration) {
@@ -352,8 +412,11 @@ func (bbc *BlueButtonClient) getRawData(method string, jobData models.JobEnqueue
 		},
 	)
 
+	if errors.Is(err, ErrCircuitOpen) {
+		return "", err
+	}
 	if err != nil {
-		return "", fmt.Errorf("blue button request failed %d time(s) %s", bbc.maxTries, err.Error())
+		return "", &RequestError{Attempts: attempts, Err: err}
 	}
 
 	return result, nil
@@ -401,15 +464,20 @@ func GetDefaultParams() (params url.Values) {
 }
 
 func updateParamWithServiceDate(params *url.Values, claimsWindow ClaimsWindow) {
-	// ServiceDate only uses yyyy-mm-dd
+	updateParamWithDateRange(params, "service-date", claimsWindow)
+}
+
+// updateParamWithDateRange restricts the named date search parameter to the claims window
+func updateParamWithDateRange(params *url.Values, name string, claimsWindow ClaimsWindow) {
+	// Date ranges only use yyyy-mm-dd
 	const isoDate = "2006-01-02"
 
 	if !claimsWindow.LowerBound.IsZero() {
-		params.Add("service-date", fmt.Sprintf("ge%s", claimsWindow.LowerBound.Format(isoDate)))
+		params.Add(name, fmt.Sprintf("ge%s", claimsWindow.LowerBound.Format(isoDate)))
 	}
 
 	if !claimsWindow.UpperBound.IsZero() {
-		params.Add("service-date", fmt.Sprintf("le%s", claimsWindow.UpperBound.Format(isoDate)))
+		params.Add(name, fmt.Sprintf("le%s", claimsWindow.UpperBound.Format(isoDate)))
 	}
 }
 
@@ -423,6 +491,17 @@ func updateParamWithLastUpdated(params *url.Values, since string, transactionTim
 	}
 }
 
+// updateParamWithTypeFilter adds the caller supplied _typeFilter search parameters.
+// Values are added (rather than set) so they further restrict any parameters we've already applied,
+// e.g. a service-date filter is applied alongside the claims window.
+func updateParamWithTypeFilter(params *url.Values, typeFilter url.Values) {
+	for key, values := range typeFilter {
+		for _, value := range values {
+			params.Add(key, value)
+		}
+	}
+}
+
 func updateParamsWithClaimsDefaults(params *url.Values, mbi string) {
 	params.Set("excludeSAMHSA", "true")
 	params.Set("includeTaxNumbers", "true")
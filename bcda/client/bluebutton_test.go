@@ -315,6 +315,19 @@ func (s *BBRequestTestSuite) TestGetClaimResponse_500() {
 	assert.Nil(s.T(), e)
 }
 
+func (s *BBRequestTestSuite) TestGetObservation() {
+	o, err := s.bbClient.GetObservation(jobData, "012345", client.ClaimsWindow{})
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 2, len(o.Entries))
+	assert.Equal(s.T(), "obs-20000000000001-1", o.Entries[0]["resource"].(map[string]interface{})["id"])
+}
+
+func (s *BBRequestTestSuite) TestGetObservation_500() {
+	o, err := s.bbClient.GetObservation(jobData, "012345", client.ClaimsWindow{})
+	assert.Regexp(s.T(), `blue button request failed \d+ time\(s\) failed to get bundle response`, err.Error())
+	assert.Nil(s.T(), o)
+}
+
 func (s *BBRequestTestSuite) TestGetMetadata() {
 	m, err := s.bbClient.GetMetadata()
 	assert.Nil(s.T(), err)
@@ -328,6 +341,22 @@ func (s *BBRequestTestSuite) TestGetMetadata_500() {
 	assert.Equal(s.T(), "", p)
 }
 
+func (s *BBRequestTestSuite) TestCircuitBreaker_500() {
+	breaker := client.NewCircuitBreaker("test", 1, time.Minute)
+	bbClient, err := client.NewBlueButtonClient(client.BlueButtonConfig{BBServer: s.ts.URL, CircuitBreaker: breaker})
+	assert.NoError(s.T(), err)
+
+	// The first failure opens the circuit, so the request is not retried
+	p, err := bbClient.GetPatient(jobData, "012345")
+	assert.ErrorIs(s.T(), err, client.ErrCircuitOpen)
+	assert.Nil(s.T(), p)
+	assert.Equal(s.T(), client.CircuitOpen, breaker.State())
+
+	m, err := bbClient.GetMetadata()
+	assert.ErrorIs(s.T(), err, client.ErrCircuitOpen)
+	assert.Equal(s.T(), "", m)
+}
+
 func (s *BBRequestTestSuite) TestGetPatientByMbi() {
 	p, err := s.bbClient.GetPatientByMbi(models.JobEnqueueArgs{}, "mbi")
 	assert.Nil(s.T(), err)
@@ -417,6 +446,28 @@ func (s *BBRequestTestSuite) TestValidateRequest() {
 				hasBulkRequestHeaders,
 			},
 		},
+		{
+			"GetExplanationOfBenefitWithTypeFilter",
+			func(bbClient *client.BlueButtonClient) (interface{}, error) {
+				jobDataTypeFilter := jobData
+				jobDataTypeFilter.TypeFilter = url.Values{"type": {"inpatient,outpatient"}, "service-date": {"ge2021-01-01"}}
+				return bbClient.GetExplanationOfBenefit(jobDataTypeFilter, "patient1", client.ClaimsWindow{LowerBound: claimsDate.LowerBound})
+			},
+			func(t *testing.T, payload interface{}) {
+				result, ok := payload.(*fhirModels.Bundle)
+				assert.True(t, ok)
+				assert.NotEmpty(t, result.Entries)
+			},
+			[]func(*testing.T, *http.Request){
+				sinceChecker,
+				nowChecker,
+				excludeSAMHSAChecker,
+				serviceDateLowerBoundChecker,
+				typeFilterChecker,
+				hasDefaultRequestHeaders,
+				hasBulkRequestHeaders,
+			},
+		},
 		{
 			"GetExplanationOfBenefitWithUpperBoundServiceDate",
 			func(bbClient *client.BlueButtonClient) (interface{}, error) {
@@ -743,6 +794,64 @@ func (s *BBRequestTestSuite) TestValidateRequest() {
 				hasClaimRequiredURLEncodedBody,
 			},
 		},
+		{
+			"GetObservation",
+			func(bbClient *client.BlueButtonClient) (interface{}, error) {
+				return bbClient.GetObservation(jobData, "patient1", client.ClaimsWindow{})
+			},
+			func(t *testing.T, payload interface{}) {
+				result, ok := payload.(*fhirModels.Bundle)
+				assert.True(t, ok)
+				assert.NotEmpty(t, result.Entries)
+			},
+			[]func(*testing.T, *http.Request){
+				sinceChecker,
+				nowChecker,
+				noExcludeSAMHSAChecker,
+				noServiceDateChecker,
+				noObservationDateChecker,
+				noIncludeAddressFieldsChecker,
+				noIncludeTaxNumbersChecker,
+				hasDefaultRequestHeaders,
+				hasBulkRequestHeaders,
+			},
+		},
+		{
+			"GetObservationNoSince",
+			func(bbClient *client.BlueButtonClient) (interface{}, error) {
+				return bbClient.GetObservation(jobDataNoSince, "patient1", client.ClaimsWindow{})
+			},
+			func(t *testing.T, payload interface{}) {
+				result, ok := payload.(*fhirModels.Bundle)
+				assert.True(t, ok)
+				assert.NotEmpty(t, result.Entries)
+			},
+			[]func(*testing.T, *http.Request){
+				noSinceChecker,
+				nowChecker,
+				hasDefaultRequestHeaders,
+				hasBulkRequestHeaders,
+			},
+		},
+		{
+			"GetObservationWithUpperAndLowerBoundDate",
+			func(bbClient *client.BlueButtonClient) (interface{}, error) {
+				return bbClient.GetObservation(jobData, "patient1", claimsDate)
+			},
+			func(t *testing.T, payload interface{}) {
+				result, ok := payload.(*fhirModels.Bundle)
+				assert.True(t, ok)
+				assert.NotEmpty(t, result.Entries)
+			},
+			[]func(*testing.T, *http.Request){
+				sinceChecker,
+				nowChecker,
+				noServiceDateChecker,
+				observationDateChecker,
+				hasDefaultRequestHeaders,
+				hasBulkRequestHeaders,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -810,6 +919,8 @@ func handlerFunc(w http.ResponseWriter, r *http.Request, useGZIP bool) {
 		file, err = os.Open("./testdata/Metadata.json")
 	} else if strings.Contains(path, "Patient") {
 		file, err = os.Open("../../shared_files/synthetic_beneficiary_data/Patient")
+	} else if strings.Contains(path, "Observation") {
+		file, err = os.Open("../../shared_files/synthetic_beneficiary_data/Observation")
 	} else if strings.Contains(path, "ClaimResponse") {
 		file, err = os.Open("../../shared_files/synthetic_beneficiary_data/ClaimResponse")
 	} else if strings.Contains(path, "Claim") {
@@ -876,6 +987,20 @@ func noServiceDateLowerBoundChecker(t *testing.T, req *http.Request) {
 	// We expect that service date only contains YYYY-MM-DD
 	assert.NotContains(t, req.URL.Query()[constants.TestSvcDate], fmt.Sprintf("ge%s", claimsDate.LowerBound.Format(constants.TestSvcDateResult)))
 }
+func noObservationDateChecker(t *testing.T, req *http.Request) {
+	assert.Empty(t, req.URL.Query()["date"])
+}
+func observationDateChecker(t *testing.T, req *http.Request) {
+	// Observations are filtered on their effective date, which only contains YYYY-MM-DD
+	assert.Equal(t, []string{
+		fmt.Sprintf("ge%s", claimsDate.LowerBound.Format(constants.TestSvcDateResult)),
+		fmt.Sprintf("le%s", claimsDate.UpperBound.Format(constants.TestSvcDateResult)),
+	}, req.URL.Query()["date"])
+}
+func typeFilterChecker(t *testing.T, req *http.Request) {
+	assert.Equal(t, []string{"inpatient,outpatient"}, req.URL.Query()["type"])
+	assert.Contains(t, req.URL.Query()[constants.TestSvcDate], "ge2021-01-01")
+}
 func noIncludeAddressFieldsChecker(t *testing.T, req *http.Request) {
 	assert.Empty(t, req.Header.Get("IncludeAddressFields"))
 }
@@ -0,0 +1,322 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/pkg/errors"
+
+	"github.com/CMSgov/bcda-app/bcda/client/fhir"
+	fhirModels "github.com/CMSgov/bcda-app/bcda/models/fhir"
+	"github.com/CMSgov/bcda-app/bcda/utils"
+	"github.com/CMSgov/bcda-app/conf"
+)
+
+// ErrCircuitOpen is returned instead of sending a request to Blue Button while the circuit breaker is open
+var ErrCircuitOpen = errors.New("blue button circuit breaker is open")
+
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// CircuitStore persists the state of a circuit breaker so that it can be shared across processes
+type CircuitStore interface {
+	LoadCircuit(ctx context.Context, name string) (state CircuitState, openedAt time.Time, err error)
+	SaveCircuit(ctx context.Context, name string, state CircuitState, openedAt time.Time) error
+}
+
+// CircuitBreaker stops requests from being sent to an unavailable service.
+//
+// The circuit opens after a number of consecutive failures. While open, requests fail immediately with ErrCircuitOpen.
+// Once the cooldown has elapsed, the circuit becomes half-open and a single trial request is allowed through.
+// The circuit closes if the trial request succeeds and reopens if it fails.
+//
+// Each change of state starts a new generation. Allow returns the generation that the request belongs to and
+// Record ignores results from earlier generations, so a slow request sent before the circuit opened cannot
+// decide the outcome of the trial request.
+type CircuitBreaker struct {
+	mu sync.Mutex
+
+	name      string
+	threshold int
+	cooldown  time.Duration
+
+	state      CircuitState
+	generation uint64
+	failures   int
+	openedAt   time.Time
+	// Whether the half-open trial request is in flight
+	trial bool
+
+	store        CircuitStore
+	syncInterval time.Duration
+	syncedAt     time.Time
+
+	now func() time.Time
+}
+
+func NewCircuitBreaker(name string, threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &CircuitBreaker{
+		name:         name,
+		threshold:    threshold,
+		cooldown:     cooldown,
+		state:        CircuitClosed,
+		syncInterval: time.Second,
+		now:          time.Now,
+	}
+}
+
+var (
+	bbBreaker     *CircuitBreaker
+	bbBreakerOnce sync.Once
+)
+
+// BlueButtonCircuitBreaker returns the circuit breaker shared by all Blue Button clients in the process
+func BlueButtonCircuitBreaker() *CircuitBreaker {
+	bbBreakerOnce.Do(func() {
+		bbBreaker = NewCircuitBreaker("bluebutton",
+			utils.GetEnvInt("BB_CIRCUIT_FAILURE_THRESHOLD", 5),
+			time.Duration(utils.GetEnvInt("BB_CIRCUIT_COOLDOWN_SECONDS", 30))*time.Second)
+	})
+	return bbBreaker
+}
+
+// ConfigureCircuitBreakerStore shares the Blue Button circuit breaker state through Postgres
+// when BB_CIRCUIT_BREAKER_STORE is set to postgres. Otherwise the state is kept in-process.
+func ConfigureCircuitBreakerStore(db *sql.DB) {
+	if conf.GetEnv("BB_CIRCUIT_BREAKER_STORE") != "postgres" {
+		return
+	}
+	BlueButtonCircuitBreaker().SetStore(NewPostgresCircuitStore(db))
+}
+
+// SetStore sets the store used to share the circuit state
+func (b *CircuitBreaker) SetStore(store CircuitStore) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.store = store
+	b.syncedAt = time.Time{}
+}
+
+// Allow returns ErrCircuitOpen if a request should not be sent. Otherwise it returns the generation
+// that the request belongs to, which must be passed to Record along with the result.
+func (b *CircuitBreaker) Allow() (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sync()
+
+	switch b.state {
+	case CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return 0, ErrCircuitOpen
+		}
+		b.setState(CircuitHalfOpen)
+		b.trial = true
+		return b.generation, nil
+	case CircuitHalfOpen:
+		if b.trial {
+			return 0, ErrCircuitOpen
+		}
+		b.trial = true
+		return b.generation, nil
+	default:
+		return b.generation, nil
+	}
+}
+
+// Record updates the circuit with the result of a request that was allowed through in the given generation.
+// Consecutive failures open a closed circuit while the trial request decides whether a half-open circuit closes.
+// Error responses from Blue Button that are not retryable (e.g. a patient was not found) do not count as failures.
+func (b *CircuitBreaker) Record(generation uint64, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Results of requests sent before the state last changed are ignored
+	if generation != b.generation {
+		return
+	}
+
+	switch b.state {
+	case CircuitClosed:
+		if !isCircuitFailure(err) {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.threshold {
+			b.open()
+		}
+	case CircuitHalfOpen:
+		b.trial = false
+		if isCircuitFailure(err) {
+			b.open()
+			return
+		}
+		b.setState(CircuitClosed)
+		b.failures = 0
+		b.save()
+	}
+}
+
+// open opens the circuit. Callers must hold the lock.
+func (b *CircuitBreaker) open() {
+	b.setState(CircuitOpen)
+	b.openedAt = b.now()
+	b.save()
+}
+
+// setState changes the state of the circuit and starts a new generation. Callers must hold the lock.
+func (b *CircuitBreaker) setState(state CircuitState) {
+	b.state = state
+	b.generation++
+}
+
+// State returns the current state of the circuit. An open circuit whose cooldown has elapsed is reported as half-open.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sync()
+
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// RetryAfter returns the time remaining until the open circuit allows a trial request
+func (b *CircuitBreaker) RetryAfter() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != CircuitOpen {
+		return 0
+	}
+	return max(b.cooldown-b.now().Sub(b.openedAt), 0)
+}
+
+// sync adopts the state saved by other processes. Failing to reach the store leaves the in-process state unchanged.
+// Callers must hold the lock.
+func (b *CircuitBreaker) sync() {
+	if b.store == nil || b.now().Sub(b.syncedAt) < b.syncInterval {
+		return
+	}
+	b.syncedAt = b.now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	state, openedAt, err := b.store.LoadCircuit(ctx, b.name)
+	if err != nil {
+		logger.Warn(errors.Wrap(err, "failed to load circuit breaker state"))
+		return
+	}
+
+	switch {
+	case state == CircuitOpen && b.state == CircuitClosed:
+		b.setState(CircuitOpen)
+		b.openedAt = openedAt
+	case state == CircuitClosed && b.state == CircuitOpen:
+		b.setState(CircuitClosed)
+		b.failures = 0
+	}
+}
+
+// save shares the state with other processes. Callers must hold the lock.
+func (b *CircuitBreaker) save() {
+	if b.store == nil {
+		return
+	}
+	b.syncedAt = b.now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := b.store.SaveCircuit(ctx, b.name, b.state, b.openedAt); err != nil {
+		logger.Warn(errors.Wrap(err, "failed to save circuit breaker state"))
+	}
+}
+
+func isCircuitFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	return fhir.IsRetryable(err)
+}
+
+// circuitBreakerClient sends requests through the underlying client only when the circuit breaker allows it
+type circuitBreakerClient struct {
+	client  fhir.Client
+	breaker *CircuitBreaker
+}
+
+// Ensure circuitBreakerClient satisfies the interface
+var _ fhir.Client = &circuitBreakerClient{}
+
+func (c *circuitBreakerClient) DoBundleRequest(req *http.Request) (*fhirModels.Bundle, *url.URL, error) {
+	generation, err := c.breaker.Allow()
+	if err != nil {
+		return nil, nil, err
+	}
+	bundle, nextURL, err := c.client.DoBundleRequest(req)
+	c.breaker.Record(generation, err)
+	return bundle, nextURL, err
+}
+
+func (c *circuitBreakerClient) DoRaw(req *http.Request) (string, error) {
+	generation, err := c.breaker.Allow()
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.client.DoRaw(req)
+	c.breaker.Record(generation, err)
+	return resp, err
+}
+
+var sqlFlavor = sqlbuilder.PostgreSQL
+
+// postgresCircuitStore stores circuit breaker state in the circuit_breakers table
+type postgresCircuitStore struct {
+	db *sql.DB
+}
+
+func NewPostgresCircuitStore(db *sql.DB) CircuitStore {
+	return &postgresCircuitStore{db}
+}
+
+func (s *postgresCircuitStore) LoadCircuit(ctx context.Context, name string) (CircuitState, time.Time, error) {
+	sb := sqlFlavor.NewSelectBuilder().Select("state", "opened_at").From("circuit_breakers")
+	sb.Where(sb.Equal("name", name))
+
+	query, args := sb.Build()
+	var (
+		state    CircuitState
+		openedAt sql.NullTime
+	)
+	if err := s.db.QueryRowContext(ctx, query, args...).Scan(&state, &openedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return CircuitClosed, time.Time{}, nil
+		}
+		return "", time.Time{}, err
+	}
+	return state, openedAt.Time, nil
+}
+
+func (s *postgresCircuitStore) SaveCircuit(ctx context.Context, name string, state CircuitState, openedAt time.Time) error {
+	ib := sqlFlavor.NewInsertBuilder().InsertInto("circuit_breakers")
+	ib.Cols("name", "state", "opened_at").Values(name, state, openedAt)
+	ib.SQL("ON CONFLICT (name) DO UPDATE SET state = EXCLUDED.state, opened_at = EXCLUDED.opened_at, updated_at = now()")
+
+	query, args := ib.Build()
+	_, err := s.db.ExecContext(ctx, query, args...)
+	return err
+}
@@ -0,0 +1,218 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CMSgov/bcda-app/bcda/client/fhir"
+	fhirModels "github.com/CMSgov/bcda-app/bcda/models/fhir"
+)
+
+var (
+	errUnavailable = errors.New("connection refused")
+	errNotFound    = &fhir.ResponseError{StatusCode: http.StatusNotFound}
+)
+
+func newTestBreaker(threshold int, cooldown time.Duration) (*CircuitBreaker, *time.Time) {
+	now := time.Now()
+	b := NewCircuitBreaker("test", threshold, cooldown)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+// allow asserts that the breaker allows a request and returns the request's generation
+func allow(t *testing.T, b *CircuitBreaker) uint64 {
+	generation, err := b.Allow()
+	assert.NoError(t, err)
+	return generation
+}
+
+func assertCircuitOpen(t *testing.T, b *CircuitBreaker) {
+	_, err := b.Allow()
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+}
+
+func TestCircuitBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	b, _ := newTestBreaker(3, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		b.Record(allow(t, b), errUnavailable)
+	}
+	// A success resets the consecutive failures
+	b.Record(allow(t, b), nil)
+
+	for i := 0; i < 2; i++ {
+		b.Record(allow(t, b), errUnavailable)
+	}
+	assert.Equal(t, CircuitClosed, b.State())
+
+	b.Record(allow(t, b), errUnavailable)
+	assert.Equal(t, CircuitOpen, b.State())
+	assertCircuitOpen(t, b)
+	assert.Equal(t, time.Minute, b.RetryAfter())
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	b, _ := newTestBreaker(1, time.Minute)
+
+	b.Record(allow(t, b), errNotFound)
+	assert.Equal(t, CircuitClosed, b.State())
+
+	b.Record(allow(t, b), &fhir.ResponseError{StatusCode: http.StatusTooManyRequests})
+	assert.Equal(t, CircuitOpen, b.State())
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	b, now := newTestBreaker(1, time.Minute)
+
+	b.Record(allow(t, b), errUnavailable)
+	assertCircuitOpen(t, b)
+
+	// Only a single trial request is allowed once the cooldown elapses
+	*now = now.Add(time.Minute)
+	assert.Equal(t, CircuitHalfOpen, b.State())
+	assert.Zero(t, b.RetryAfter())
+	trial := allow(t, b)
+	assertCircuitOpen(t, b)
+
+	// A failed trial reopens the circuit
+	b.Record(trial, errUnavailable)
+	assert.Equal(t, CircuitOpen, b.State())
+	assertCircuitOpen(t, b)
+
+	// A successful trial closes the circuit
+	*now = now.Add(time.Minute)
+	b.Record(allow(t, b), nil)
+	assert.Equal(t, CircuitClosed, b.State())
+	allow(t, b)
+	allow(t, b)
+}
+
+func TestCircuitBreakerIgnoresStaleResults(t *testing.T) {
+	b, _ := newTestBreaker(1, time.Minute)
+
+	first := allow(t, b)
+	second := allow(t, b)
+	b.Record(first, errUnavailable)
+
+	// The second request was sent before the circuit opened
+	b.Record(second, nil)
+	assert.Equal(t, CircuitOpen, b.State())
+}
+
+func TestCircuitBreakerIgnoresStaleResultsWhileHalfOpen(t *testing.T) {
+	b, now := newTestBreaker(1, time.Minute)
+
+	// A slow request is still in flight when another request opens the circuit
+	slow := allow(t, b)
+	b.Record(allow(t, b), errUnavailable)
+	assert.Equal(t, CircuitOpen, b.State())
+
+	*now = now.Add(time.Minute)
+	trial := allow(t, b)
+
+	// The slow request succeeding does not close the circuit, and its failure does not reopen it
+	b.Record(slow, nil)
+	assert.Equal(t, CircuitHalfOpen, b.State())
+	assertCircuitOpen(t, b)
+	b.Record(slow, errUnavailable)
+	assert.Equal(t, CircuitHalfOpen, b.State())
+
+	// Only the trial request decides the outcome
+	b.Record(trial, errUnavailable)
+	assert.Equal(t, CircuitOpen, b.State())
+
+	// A successful trial is not undone by the result of an earlier trial
+	*now = now.Add(time.Minute)
+	b.Record(allow(t, b), nil)
+	assert.Equal(t, CircuitClosed, b.State())
+	b.Record(trial, errUnavailable)
+	assert.Equal(t, CircuitClosed, b.State())
+}
+
+type memoryCircuitStore struct {
+	state    CircuitState
+	openedAt time.Time
+	err      error
+	saves    int
+}
+
+func (s *memoryCircuitStore) LoadCircuit(ctx context.Context, name string) (CircuitState, time.Time, error) {
+	if s.state == "" {
+		return CircuitClosed, time.Time{}, s.err
+	}
+	return s.state, s.openedAt, s.err
+}
+
+func (s *memoryCircuitStore) SaveCircuit(ctx context.Context, name string, state CircuitState, openedAt time.Time) error {
+	s.saves++
+	s.state, s.openedAt = state, openedAt
+	return s.err
+}
+
+func TestCircuitBreakerSharedState(t *testing.T) {
+	SetLogger(logrus.New())
+	store := &memoryCircuitStore{}
+	b1, now := newTestBreaker(1, time.Minute)
+	b2, _ := newTestBreaker(1, time.Minute)
+	b2.now = b1.now
+	b1.SetStore(store)
+	b2.SetStore(store)
+
+	// Opening the circuit in one process opens it in the other
+	b1.Record(allow(t, b1), errUnavailable)
+	assert.Equal(t, 1, store.saves)
+	assert.Equal(t, CircuitOpen, b2.State())
+	assertCircuitOpen(t, b2)
+
+	// Closing the circuit in one process closes it in the other
+	*now = now.Add(time.Minute)
+	b1.Record(allow(t, b1), nil)
+	assert.Equal(t, CircuitClosed, store.state)
+	assert.Equal(t, CircuitClosed, b2.State())
+	allow(t, b2)
+
+	// The in-process state is used when the store is unavailable
+	store.err = errors.New("database unavailable")
+	*now = now.Add(b2.syncInterval)
+	b2.Record(allow(t, b2), errUnavailable)
+	assert.Equal(t, CircuitOpen, b2.State())
+}
+
+func TestCircuitBreakerClient(t *testing.T) {
+	b, _ := newTestBreaker(1, time.Minute)
+	c := &circuitBreakerClient{&fakeFHIRClient{err: errUnavailable}, b}
+
+	req, err := http.NewRequest("GET", "http://bfd.example.com", nil)
+	assert.NoError(t, err)
+
+	_, _, err = c.DoBundleRequest(req)
+	assert.ErrorIs(t, err, errUnavailable)
+	_, _, err = c.DoBundleRequest(req)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	_, err = c.DoRaw(req)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 1, c.client.(*fakeFHIRClient).requests)
+}
+
+type fakeFHIRClient struct {
+	err      error
+	requests int
+}
+
+func (c *fakeFHIRClient) DoBundleRequest(req *http.Request) (*fhirModels.Bundle, *url.URL, error) {
+	c.requests++
+	return nil, nil, c.err
+}
+
+func (c *fakeFHIRClient) DoRaw(req *http.Request) (string, error) {
+	c.requests++
+	return "", c.err
+}
@@ -129,9 +129,8 @@ func getResponse(c *http.Client, req *http.Request) (body []byte, err error) {
 
 	resp, err := c.Do(req)
 	if err != nil {
-		if innerErr := resp.Body.Close(); innerErr != nil {
-			return nil, innerErr
-		}
+		// The response is nil when the request could not be sent (e.g. connection refused or timed out)
+		s.End()
 		return nil, err
 	}
 	defer resp.Body.Close()
@@ -143,8 +142,7 @@ func getResponse(c *http.Client, req *http.Request) (body []byte, err error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse response body %+v", err)
 		}
-		return nil, fmt.Errorf("received incorrect status code %d body %s",
-			resp.StatusCode, string(body))
+		return nil, newResponseError(resp, body)
 	}
 
 	body, err = io.ReadAll(resp.Body)
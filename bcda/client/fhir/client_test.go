@@ -103,6 +103,25 @@ func TestRawRequest(t *testing.T) {
 	assert.Equal(t, msg, resp)
 }
 
+func TestErrorResponse(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	client := NewClient(http.DefaultClient, 0)
+
+	req, err := http.NewRequest("GET", s.URL, nil)
+	assert.NoError(t, err)
+
+	_, err = client.DoRaw(req)
+	var respErr *ResponseError
+	assert.ErrorAs(t, err, &respErr)
+	assert.Equal(t, http.StatusServiceUnavailable, respErr.StatusCode)
+	assert.Equal(t, "unavailable\n", respErr.Body)
+	assert.EqualError(t, err, "failed to get response: received incorrect status code 503 body unavailable\n")
+}
+
 func assertEqualsBundle(t *testing.T, pathToExpected string, actual *models.Bundle) {
 	data, err := os.ReadFile(pathToExpected)
 	assert.NoError(t, err)
@@ -0,0 +1,138 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	models "github.com/CMSgov/bcda-app/bcda/models/fhir"
+)
+
+// Reasons used to classify a failed request
+const (
+	ReasonBadRequest   = "bad-request"
+	ReasonUnauthorized = "unauthorized"
+	ReasonNotFound     = "not-found"
+	ReasonClientError  = "client-error"
+	ReasonRateLimited  = "rate-limited"
+	ReasonUnavailable  = "unavailable"
+	ReasonServerError  = "server-error"
+	ReasonTimeout      = "timeout"
+	ReasonConnection   = "connection"
+)
+
+// ResponseError is returned when the FHIR server responds with an error status code
+type ResponseError struct {
+	StatusCode int
+	Body       string
+	// RetryAfter is the delay requested through the Retry-After header of a 429 or 503 response
+	RetryAfter time.Duration
+	// OperationOutcome is set when the body contains an OperationOutcome describing the error
+	OperationOutcome *models.OperationOutcome
+}
+
+func newResponseError(resp *http.Response, body []byte) *ResponseError {
+	e := &ResponseError{StatusCode: resp.StatusCode, Body: string(body)}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		e.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	}
+
+	var oo models.OperationOutcome
+	if err := json.Unmarshal(body, &oo); err == nil && oo.ResourceType == "OperationOutcome" {
+		e.OperationOutcome = &oo
+	}
+
+	return e
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("received incorrect status code %d body %s", e.StatusCode, e.Body)
+}
+
+// Retryable returns whether the request may succeed if it is sent again
+func (e *ResponseError) Retryable() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// Reason classifies the error by its status code
+func (e *ResponseError) Reason() string {
+	switch {
+	case e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity:
+		return ReasonBadRequest
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ReasonUnauthorized
+	case e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusGone:
+		return ReasonNotFound
+	case e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusGatewayTimeout:
+		return ReasonTimeout
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ReasonRateLimited
+	case e.StatusCode == http.StatusBadGateway || e.StatusCode == http.StatusServiceUnavailable:
+		return ReasonUnavailable
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ReasonServerError
+	default:
+		return ReasonClientError
+	}
+}
+
+// IsRetryable returns whether a failed request may succeed if it is sent again.
+// Errors that did not come from the FHIR server (e.g. connection errors) are retryable.
+func IsRetryable(err error) bool {
+	var respErr *ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.Retryable()
+	}
+	return true
+}
+
+// FailureReason classifies why a request failed. An empty string is returned for errors that cannot be classified.
+func FailureReason(err error) string {
+	var respErr *ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.Reason()
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ReasonTimeout
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ReasonTimeout
+		}
+		return ReasonConnection
+	}
+
+	return ""
+}
+
+// parseRetryAfter parses the Retry-After header, which contains either a number of seconds or an HTTP date
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+
+	if t, err := http.ParseTime(header); err == nil {
+		return max(t.Sub(now), 0)
+	}
+
+	return 0
+}
@@ -0,0 +1,116 @@
+package fhir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseErrorRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		retryAfter string
+		expected   time.Duration
+	}{
+		{"Too many requests", http.StatusTooManyRequests, "5", 5 * time.Second},
+		{"Service unavailable", http.StatusServiceUnavailable, "120", 2 * time.Minute},
+		{"Invalid header", http.StatusServiceUnavailable, "soon", 0},
+		{"Ignored for other status codes", http.StatusInternalServerError, "5", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", tt.retryAfter)
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer s.Close()
+
+			req, err := http.NewRequest("GET", s.URL, nil)
+			assert.NoError(t, err)
+
+			_, err = NewClient(http.DefaultClient, 0).DoRaw(req)
+			var respErr *ResponseError
+			assert.ErrorAs(t, err, &respErr)
+			assert.Equal(t, tt.expected, respErr.RetryAfter)
+			assert.True(t, IsRetryable(err))
+		})
+	}
+}
+
+func TestResponseErrorOperationOutcome(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"resourceType":"OperationOutcome","issue":[{"severity":"error","code":"processing","diagnostics":"Unsupported query parameter value"}]}`)
+	}))
+	defer s.Close()
+
+	req, err := http.NewRequest("GET", s.URL, nil)
+	assert.NoError(t, err)
+
+	_, _, err = NewClient(http.DefaultClient, 0).DoBundleRequest(req)
+	var respErr *ResponseError
+	assert.ErrorAs(t, err, &respErr)
+	assert.NotNil(t, respErr.OperationOutcome)
+	assert.Len(t, respErr.OperationOutcome.Issues, 1)
+	assert.Equal(t, "processing", respErr.OperationOutcome.Issues[0].Code)
+	assert.Equal(t, "Unsupported query parameter value", respErr.OperationOutcome.Issues[0].Diagnostics)
+	assert.False(t, IsRetryable(err))
+	assert.Equal(t, ReasonBadRequest, FailureReason(err))
+}
+
+func TestFailureReason(t *testing.T) {
+	tests := []struct {
+		err       error
+		reason    string
+		retryable bool
+	}{
+		{&ResponseError{StatusCode: http.StatusBadRequest}, ReasonBadRequest, false},
+		{&ResponseError{StatusCode: http.StatusForbidden}, ReasonUnauthorized, false},
+		{&ResponseError{StatusCode: http.StatusNotFound}, ReasonNotFound, false},
+		{&ResponseError{StatusCode: http.StatusConflict}, ReasonClientError, false},
+		{&ResponseError{StatusCode: http.StatusTooManyRequests}, ReasonRateLimited, true},
+		{&ResponseError{StatusCode: http.StatusInternalServerError}, ReasonServerError, true},
+		{&ResponseError{StatusCode: http.StatusNotImplemented}, ReasonServerError, false},
+		{&ResponseError{StatusCode: http.StatusServiceUnavailable}, ReasonUnavailable, true},
+		{&ResponseError{StatusCode: http.StatusGatewayTimeout}, ReasonTimeout, true},
+		{fmt.Errorf("failed to get response: %w", context.DeadlineExceeded), ReasonTimeout, true},
+		{errors.New("unexpected end of JSON input"), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			assert.Equal(t, tt.reason, FailureReason(tt.err))
+			assert.Equal(t, tt.retryable, IsRetryable(tt.err))
+		})
+	}
+}
+
+func TestFailureReasonConnection(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	s.Close()
+
+	req, err := http.NewRequest("GET", s.URL, nil)
+	assert.NoError(t, err)
+
+	_, err = NewClient(http.DefaultClient, 0).DoRaw(req)
+	assert.Equal(t, ReasonConnection, FailureReason(err))
+	assert.True(t, IsRetryable(err))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 30*time.Second, parseRetryAfter("30", now))
+	assert.Equal(t, time.Minute, parseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now))
+	assert.Zero(t, parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now))
+	assert.Zero(t, parseRetryAfter("-1", now))
+	assert.Zero(t, parseRetryAfter("", now))
+}
@@ -51,6 +51,46 @@ func (bbc *MockBlueButtonClient) GetClaimResponse(jobData models.JobEnqueueArgs,
 	return args.Get(0).(*fhirModels.Bundle), args.Error(1)
 }
 
+func (bbc *MockBlueButtonClient) GetObservation(jobData models.JobEnqueueArgs, patientID string, claimsWindow ClaimsWindow) (*fhirModels.Bundle, error) {
+	args := bbc.Called(jobData, patientID, claimsWindow)
+	return args.Get(0).(*fhirModels.Bundle), args.Error(1)
+}
+
+// The Stream methods pass the bundle returned by the corresponding mocked Get method as a single page
+
+func (bbc *MockBlueButtonClient) StreamExplanationOfBenefit(jobData models.JobEnqueueArgs, patientID string, claimsWindow ClaimsWindow, handle PageHandler) error {
+	return streamMockBundle(bbc.GetExplanationOfBenefit(jobData, patientID, claimsWindow))(handle)
+}
+
+func (bbc *MockBlueButtonClient) StreamPatient(jobData models.JobEnqueueArgs, patientID string, handle PageHandler) error {
+	return streamMockBundle(bbc.GetPatient(jobData, patientID))(handle)
+}
+
+func (bbc *MockBlueButtonClient) StreamCoverage(jobData models.JobEnqueueArgs, beneficiaryID string, handle PageHandler) error {
+	return streamMockBundle(bbc.GetCoverage(jobData, beneficiaryID))(handle)
+}
+
+func (bbc *MockBlueButtonClient) StreamClaim(jobData models.JobEnqueueArgs, mbi string, claimsWindow ClaimsWindow, handle PageHandler) error {
+	return streamMockBundle(bbc.GetClaim(jobData, mbi, claimsWindow))(handle)
+}
+
+func (bbc *MockBlueButtonClient) StreamClaimResponse(jobData models.JobEnqueueArgs, mbi string, claimsWindow ClaimsWindow, handle PageHandler) error {
+	return streamMockBundle(bbc.GetClaimResponse(jobData, mbi, claimsWindow))(handle)
+}
+
+func (bbc *MockBlueButtonClient) StreamObservation(jobData models.JobEnqueueArgs, patientID string, claimsWindow ClaimsWindow, handle PageHandler) error {
+	return streamMockBundle(bbc.GetObservation(jobData, patientID, claimsWindow))(handle)
+}
+
+func streamMockBundle(b *fhirModels.Bundle, err error) func(handle PageHandler) error {
+	return func(handle PageHandler) error {
+		if err != nil {
+			return err
+		}
+		return handle(b)
+	}
+}
+
 // Returns copy of a static json file (From Blue Button Sandbox originally) after replacing the patient ID of 20000000000001 with the requested identifier
 // This is private in the real function and should remain so, but in the test client it makes maintenance easier to expose it.
 func (bbc *MockBlueButtonClient) GetData(endpoint, patientID string) (string, error) {
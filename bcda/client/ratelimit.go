@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/CMSgov/bcda-app/bcda/utils"
+)
+
+// tokenBucket is a token bucket rate limiter. Tokens are replenished at a constant rate up to the burst size
+// and each request consumes a single token, waiting for one to become available if the bucket is empty.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	now func() time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+// wait blocks until a token is available or the context is done
+func (b *tokenBucket) wait(ctx context.Context) error {
+	delay := b.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reserve consumes a token and returns how long the caller must wait before the token may be used.
+// The bucket may go negative so that concurrent callers are queued in the order they reserved.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+var (
+	hostLimitersMu sync.Mutex
+	hostLimiters   = make(map[string]*tokenBucket)
+)
+
+// limiterForHost returns the limiter shared by all clients sending requests to the host.
+// A nil limiter is returned when rate limiting is disabled (BB_REQUESTS_PER_SECOND is not positive).
+func limiterForHost(host string) *tokenBucket {
+	rate := utils.GetEnvInt("BB_REQUESTS_PER_SECOND", 0)
+	if rate <= 0 {
+		return nil
+	}
+
+	hostLimitersMu.Lock()
+	defer hostLimitersMu.Unlock()
+
+	limiter, ok := hostLimiters[host]
+	if !ok {
+		burst := utils.GetEnvInt("BB_REQUEST_BURST", rate)
+		if burst <= 0 {
+			burst = 1
+		}
+		limiter = newTokenBucket(float64(rate), burst)
+		hostLimiters[host] = limiter
+	}
+	return limiter
+}
+
+// rateLimitedTransport waits on the host's limiter before sending each request,
+// which includes retries and requests for subsequent pages of a bundle.
+type rateLimitedTransport struct {
+	next http.RoundTripper
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if limiter := limiterForHost(req.URL.Host); limiter != nil {
+		if err := limiter.wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CMSgov/bcda-app/conf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucketReserve(t *testing.T) {
+	now := time.Now()
+	b := newTokenBucket(10, 2)
+	b.last = now
+	b.now = func() time.Time { return now }
+
+	// Burst tokens are immediately available
+	assert.Zero(t, b.reserve())
+	assert.Zero(t, b.reserve())
+
+	// Subsequent callers are queued behind each other
+	assert.Equal(t, 100*time.Millisecond, b.reserve())
+	assert.Equal(t, 200*time.Millisecond, b.reserve())
+
+	// Tokens are replenished over time, but never beyond the burst size
+	now = now.Add(time.Second)
+	assert.Zero(t, b.reserve())
+	assert.Zero(t, b.reserve())
+	assert.Equal(t, 100*time.Millisecond, b.reserve())
+}
+
+func TestTokenBucketWaitCancelled(t *testing.T) {
+	b := newTokenBucket(0.001, 1)
+	assert.NoError(t, b.wait(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, b.wait(ctx), context.Canceled)
+}
+
+func TestLimiterForHost(t *testing.T) {
+	resetLimiters(t)
+
+	conf.UnsetEnv(t, "BB_REQUESTS_PER_SECOND")
+	assert.Nil(t, limiterForHost("bfd.example.com"))
+
+	conf.SetEnv(t, "BB_REQUESTS_PER_SECOND", "5")
+	limiter := limiterForHost("bfd.example.com")
+	assert.NotNil(t, limiter)
+	assert.Equal(t, float64(5), limiter.burst)
+	assert.Same(t, limiter, limiterForHost("bfd.example.com"))
+	assert.NotSame(t, limiter, limiterForHost("other.example.com"))
+}
+
+func TestRateLimitedTransport(t *testing.T) {
+	resetLimiters(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	conf.SetEnv(t, "BB_REQUESTS_PER_SECOND", "20")
+	conf.SetEnv(t, "BB_REQUEST_BURST", "1")
+	client := &http.Client{Transport: &rateLimitedTransport{http.DefaultTransport}}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(ts.URL)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+	// The first request uses the burst token while the remaining two wait 50ms each
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+// resetLimiters removes the limiters and rate limit settings created by the test once it completes
+func resetLimiters(t *testing.T) {
+	t.Cleanup(func() {
+		conf.UnsetEnv(t, "BB_REQUESTS_PER_SECOND")
+		conf.UnsetEnv(t, "BB_REQUEST_BURST")
+		hostLimitersMu.Lock()
+		hostLimiters = make(map[string]*tokenBucket)
+		hostLimitersMu.Unlock()
+	})
+}
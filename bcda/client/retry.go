@@ -0,0 +1,82 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	backoff "github.com/cenkalti/backoff/v4"
+	"github.com/pkg/errors"
+
+	"github.com/CMSgov/bcda-app/bcda/client/fhir"
+)
+
+// retryBackOff is an exponential backoff that waits at least as long as Blue Button requested through
+// the Retry-After header before retrying. The requested delay is capped at the maximum backoff interval
+// so that a single request does not hold up a worker indefinitely.
+type retryBackOff struct {
+	backoff.BackOff
+	maxDelay   time.Duration
+	retryAfter time.Duration
+}
+
+func newRetryBackOff(retryInterval time.Duration, maxTries uint64) *retryBackOff {
+	eb := backoff.NewExponentialBackOff()
+	eb.InitialInterval = retryInterval
+	return &retryBackOff{BackOff: backoff.WithMaxRetries(eb, maxTries), maxDelay: eb.MaxInterval}
+}
+
+func (b *retryBackOff) Reset() {
+	b.retryAfter = 0
+	b.BackOff.Reset()
+}
+
+func (b *retryBackOff) NextBackOff() time.Duration {
+	next := b.BackOff.NextBackOff()
+	if next != backoff.Stop {
+		next = max(next, min(b.retryAfter, b.maxDelay))
+	}
+	b.retryAfter = 0
+	return next
+}
+
+// check returns errors that will not succeed if the request is retried as permanent errors
+// (e.g. a 404 or an open circuit breaker) and records the delay requested by Blue Button.
+func (b *retryBackOff) check(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, ErrCircuitOpen) || !fhir.IsRetryable(err) {
+		return backoff.Permanent(err)
+	}
+
+	var respErr *fhir.ResponseError
+	if errors.As(err, &respErr) {
+		b.retryAfter = respErr.RetryAfter
+	}
+	return err
+}
+
+// RequestError is returned once a request to Blue Button has failed and will not be sent again
+type RequestError struct {
+	// Attempts is the number of times the request was sent
+	Attempts int
+	Err      error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("blue button request failed %d time(s) %s", e.Attempts, e.Err)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// RetryCount returns the number of times the failed request was retried, or zero if the error did not
+// come from a request to Blue Button.
+func RetryCount(err error) int {
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) && reqErr.Attempts > 0 {
+		return reqErr.Attempts - 1
+	}
+	return 0
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	backoff "github.com/cenkalti/backoff/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CMSgov/bcda-app/bcda/client/fhir"
+)
+
+func TestRetryBackOffPermanentErrors(t *testing.T) {
+	b := newRetryBackOff(time.Millisecond, 3)
+
+	var permanent *backoff.PermanentError
+	assert.ErrorAs(t, b.check(&fhir.ResponseError{StatusCode: http.StatusNotFound}), &permanent)
+	assert.ErrorAs(t, b.check(ErrCircuitOpen), &permanent)
+	assert.False(t, errors.As(b.check(&fhir.ResponseError{StatusCode: http.StatusBadGateway}), &permanent))
+	assert.False(t, errors.As(b.check(errors.New("connection reset by peer")), &permanent))
+	assert.NoError(t, b.check(nil))
+}
+
+func TestRetryBackOffRetryAfter(t *testing.T) {
+	b := newRetryBackOff(time.Millisecond, 3)
+	b.Reset()
+
+	// The delay requested by the server is used when it is longer than the backoff interval
+	assert.Error(t, b.check(&fhir.ResponseError{StatusCode: http.StatusTooManyRequests, RetryAfter: 5 * time.Second}))
+	assert.Equal(t, 5*time.Second, b.NextBackOff())
+
+	// The delay only applies to the next retry
+	assert.Less(t, b.NextBackOff(), time.Second)
+
+	// The delay is capped at the maximum backoff interval
+	assert.Error(t, b.check(&fhir.ResponseError{StatusCode: http.StatusServiceUnavailable, RetryAfter: time.Hour}))
+	assert.Equal(t, backoff.DefaultMaxInterval, b.NextBackOff())
+
+	// Retries are still limited by the maximum number of tries
+	assert.Equal(t, backoff.Stop, b.NextBackOff())
+}
+
+func TestRetryBlueButtonRequest(t *testing.T) {
+	SetLogger(logrus.New())
+
+	tests := []struct {
+		name             string
+		statusCode       int
+		expectedRequests int
+	}{
+		{"Not found is not retried", http.StatusNotFound, 1},
+		{"Service unavailable is retried", http.StatusServiceUnavailable, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer ts.Close()
+
+			bbc := &BlueButtonClient{
+				client:        fhir.NewClient(http.DefaultClient, 0),
+				maxTries:      2,
+				retryInterval: time.Millisecond,
+				bbServer:      ts.URL,
+				bbBasePath:    "/v1/fhir",
+			}
+
+			_, err := bbc.GetMetadata()
+			var respErr *fhir.ResponseError
+			assert.ErrorAs(t, err, &respErr)
+			assert.Equal(t, tt.statusCode, respErr.StatusCode)
+			assert.Regexp(t, fmt.Sprintf(`blue button request failed %d time\(s\)`, tt.expectedRequests), err.Error())
+			assert.Equal(t, tt.expectedRequests, requests)
+			assert.Equal(t, tt.expectedRequests-1, RetryCount(err))
+		})
+	}
+}
+
+func TestRetryCount(t *testing.T) {
+	assert.Equal(t, 2, RetryCount(&RequestError{Attempts: 3, Err: errors.New("error")}))
+	assert.Equal(t, 0, RetryCount(fmt.Errorf("wrapped %w", &RequestError{Attempts: 1, Err: errors.New("error")})))
+	assert.Equal(t, 0, RetryCount(errors.New("error")))
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/CMSgov/bcda-app/bcda/models"
+	fhirModels "github.com/CMSgov/bcda-app/bcda/models/fhir"
+)
+
+// streamBundleDataRequest passes each page of the bundle to handle as it is received,
+// so that the caller does not need to hold the entire bundle in memory
+func (bbc *BlueButtonClient) streamBundleDataRequest(method string, u *url.URL, jobData models.JobEnqueueArgs, headers http.Header, body io.Reader, handle PageHandler) error {
+	for u != nil {
+		result, nextURL, err := bbc.tryBundleRequest(method, u, jobData, headers, body)
+		if err != nil {
+			return err
+		}
+
+		if err = handle(result); err != nil {
+			return err
+		}
+
+		u = nextURL
+	}
+
+	return nil
+}
+
+// CollectPages combines the pages of a streamed bundle into a single bundle
+func CollectPages(stream func(handle PageHandler) error) (*fhirModels.Bundle, error) {
+	var b *fhirModels.Bundle
+	err := stream(func(page *fhirModels.Bundle) error {
+		if b == nil {
+			b = page
+		} else {
+			b.Entries = append(b.Entries, page.Entries...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
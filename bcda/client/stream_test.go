@@ -0,0 +1,89 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CMSgov/bcda-app/bcda/client/fhir"
+	"github.com/CMSgov/bcda-app/bcda/models"
+	fhirModels "github.com/CMSgov/bcda-app/bcda/models/fhir"
+)
+
+// newPagingServer returns a server that responds with a bundle split across the given number of pages
+func newPagingServer(t *testing.T, pages int) *httptest.Server {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := 1
+		if p := r.URL.Query().Get("page"); p != "" {
+			_, err := fmt.Sscan(p, &page)
+			assert.NoError(t, err)
+		}
+
+		b := map[string]interface{}{
+			"resourceType": "Bundle",
+			"entry":        []map[string]interface{}{{"resource": map[string]interface{}{"id": fmt.Sprintf("page%d", page)}}},
+		}
+		if page < pages {
+			b["link"] = []map[string]string{{"relation": "next", "url": fmt.Sprintf("%s/v1/fhir/Coverage/?page=%d", ts.URL, page+1)}}
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(b))
+	}))
+	return ts
+}
+
+func newTestClient(ts *httptest.Server) *BlueButtonClient {
+	return &BlueButtonClient{
+		client:        fhir.NewClient(http.DefaultClient, 1),
+		maxTries:      1,
+		retryInterval: time.Millisecond,
+		bbServer:      ts.URL,
+		bbBasePath:    "/v1/fhir",
+	}
+}
+
+func TestStreamCoverage(t *testing.T) {
+	SetLogger(logrus.New())
+	ts := newPagingServer(t, 3)
+	defer ts.Close()
+	bbc := newTestClient(ts)
+
+	// Each page is passed to the handler as it is received
+	var ids []string
+	err := bbc.StreamCoverage(models.JobEnqueueArgs{}, "beneID", func(page *fhirModels.Bundle) error {
+		assert.Len(t, page.Entries, 1)
+		ids = append(ids, page.Entries[0]["resource"].(map[string]interface{})["id"].(string))
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"page1", "page2", "page3"}, ids)
+
+	// The non-streaming variant combines the pages
+	b, err := bbc.GetCoverage(models.JobEnqueueArgs{}, "beneID")
+	assert.NoError(t, err)
+	assert.Len(t, b.Entries, 3)
+}
+
+func TestStreamCoverageHandlerError(t *testing.T) {
+	SetLogger(logrus.New())
+	ts := newPagingServer(t, 3)
+	defer ts.Close()
+	bbc := newTestClient(ts)
+
+	// No further pages are requested once the handler fails
+	pages := 0
+	errHandler := errors.New("disk full")
+	err := bbc.StreamCoverage(models.JobEnqueueArgs{}, "beneID", func(page *fhirModels.Bundle) error {
+		pages++
+		return errHandler
+	})
+	assert.ErrorIs(t, err, errHandler)
+	assert.Equal(t, 1, pages)
+}
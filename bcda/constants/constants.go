@@ -26,6 +26,12 @@ const CliDataImpCategory = "Data import"
 const ContentType = "Content-Type"
 const JsonContentType = "application/json"
 const FHIRJsonContentType = "application/fhir+json"
+const FHIRNDJSONContentType = "application/fhir+ndjson"
+const ParquetContentType = "application/vnd.apache.parquet"
+
+// Output formats supported by the worker (ndjson is the default)
+const NDJSONOutputFormat = "ndjson"
+const ParquetOutputFormat = "parquet"
 
 const BBHeaderTS = "BlueButton-OriginalQueryTimestamp"
 const BBHeaderOriginURL = "BlueButton-OriginalUrl"
@@ -37,6 +43,7 @@ const CCLFFileRetID = "%s RETURNING id"
 const JobKeyCreateErr = "failed to create job key: %w"
 
 const JOBIDPath = "/jobs/{jobID}"
+const JOBIDRetryPath = "/jobs/{jobID}/$retry"
 
 const IssuerSSAS = "ssas"
 
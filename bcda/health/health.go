@@ -1,6 +1,8 @@
 package health
 
 import (
+	"fmt"
+
 	ssasClient "github.com/CMSgov/bcda-app/bcda/auth/client"
 	"github.com/CMSgov/bcda-app/bcda/client"
 	"github.com/CMSgov/bcda-app/bcda/database"
@@ -18,20 +20,39 @@ func IsDatabaseOK() (result string, ok bool) {
 	return "ok", true
 }
 
-func IsBlueButtonOK() bool {
+func IsBlueButtonOK() (result string, ok bool) {
+	// Avoid sending requests to Blue Button while the circuit breaker is open
+	if client.BlueButtonCircuitBreaker().State() == client.CircuitOpen {
+		log.API.Warn("Health check: Blue Button circuit breaker is open")
+		return "circuit breaker open", false
+	}
+
 	bbc, err := client.NewBlueButtonClient(client.NewConfig("/v1/fhir"))
 	if err != nil {
 		log.API.Error("Health check: Blue Button client error: ", err.Error())
-		return false
+		return "blue button client error", false
 	}
 
 	_, err = bbc.GetMetadata()
 	if err != nil {
 		log.API.Error("Health check: Blue Button connection error: ", err.Error())
-		return false
+		return "blue button connection error", false
 	}
 
-	return true
+	return "ok", true
+}
+
+// BlueButtonCircuitState reports the state of the shared Blue Button circuit breaker without sending a request to Blue Button
+func BlueButtonCircuitState() (result string, ok bool) {
+	switch state := client.BlueButtonCircuitBreaker().State(); state {
+	case client.CircuitClosed:
+		return "ok", true
+	case client.CircuitOpen:
+		log.API.Warn("Health check: Blue Button circuit breaker is open")
+		return "circuit breaker open", false
+	default:
+		return fmt.Sprintf("circuit breaker %s", state), true
+	}
 }
 
 func IsSsasOK() (result string, ok bool) {
@@ -43,6 +43,7 @@ import (
 	"github.com/CMSgov/bcda-app/bcda/auth"
 	"github.com/CMSgov/bcda-app/bcda/bcdacli"
 	"github.com/CMSgov/bcda-app/bcda/client"
+	"github.com/CMSgov/bcda-app/bcda/database"
 	"github.com/CMSgov/bcda-app/bcda/monitoring"
 	"github.com/CMSgov/bcda-app/conf"
 	"github.com/CMSgov/bcda-app/log"
@@ -50,6 +51,7 @@ import (
 
 func init() {
 	client.SetLogger(log.BBAPI)
+	client.ConfigureCircuitBreakerStore(database.Connection)
 
 	isEtlMode := conf.GetEnv("BCDA_ETL_MODE")
 	if isEtlMode != "true" {
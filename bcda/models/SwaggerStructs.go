@@ -13,6 +13,23 @@ type BulkRequestResponse struct {
 	ContentLocation string `json:"Content-Location"`
 }
 
+// FHIR searchset Bundle containing the requested resources for the beneficiaries. https://www.hl7.org/fhir/bundle.html
+// swagger:response SyncExportResponse
+type SyncExportResponse struct {
+	// in: body
+	Body struct {
+		// Bundle
+		ResourceType string `json:"resourceType"`
+		// searchset
+		Type string `json:"type"`
+		// Total number of entries
+		Total int `json:"total"`
+		Entry []struct {
+			Resource map[string]interface{} `json:"resource"`
+		} `json:"entry"`
+	}
+}
+
 type OperationOutcomeResponse struct {
 	// OperationOutcome
 	ResourceType string
@@ -113,18 +130,18 @@ type ErrorResponse struct {
 	Body OperationOutcomeResponse
 }
 
+// The requested byte range cannot be served for the file.
+// swagger:response rangeNotSatisfiableResponse
+type RangeNotSatisfiableResponse struct {
+	// The size of the stored file (i.e., `bytes */1024`)
+	ContentRange string `json:"Content-Range"`
+}
+
 // A bulk export job of this resource type is already in progress for the ACO.
 // swagger:response tooManyRequestsResponse
 type TooManyRequestsResponse struct {
 }
 
-// Data export job is in progress.
-// swagger:response jobStatusResponse
-type JobStatusResponse struct {
-	// The status of the job progress
-	XProgress string `json:"X-Progress"`
-}
-
 // JSON object containing status of requested jobs. The body will contain a FHIR Bundle resource in JSON format https://www.hl7.org/fhir/bundle.html and FHIR Task resources for the Bundle entries in JSON format https://www.hl7.org/fhir/task.html
 // swagger:response jobsStatusResponse
 type JobsStatusResponse struct {
@@ -179,12 +196,66 @@ type IngestionDatesStatusParam struct {
 	Type      string    `json:"type"`
 }
 
+// FHIR Group containing the beneficiaries added to or removed from the ACO's attribution by the latest attribution file. https://www.hl7.org/fhir/group.html
+// swagger:response AttributionDeltaResponse
+type AttributionDeltaResponse struct {
+	// in: body
+	Body struct {
+		// Group
+		ResourceType string `json:"resourceType"`
+		ID           string `json:"id"`
+		// person
+		Type   string `json:"type"`
+		Actual bool   `json:"actual"`
+		Name   string `json:"name"`
+		// Number of beneficiaries attributed by the latest attribution file
+		Quantity int `json:"quantity"`
+		// Number of added, removed, and retained beneficiaries
+		Extension []map[string]interface{} `json:"extension"`
+		// Added and removed beneficiaries, identified by MBI
+		Member []struct {
+			Entity   map[string]interface{} `json:"entity"`
+			Period   map[string]interface{} `json:"period"`
+			Inactive bool                   `json:"inactive"`
+		} `json:"member"`
+	}
+}
+
+// FHIR Group containing the beneficiaries attributed to the ACO by the latest attribution file. https://www.hl7.org/fhir/group.html
+// swagger:response GroupResponse
+type GroupResponse struct {
+	// in: body
+	Body struct {
+		// Group
+		ResourceType string `json:"resourceType"`
+		// all or runout
+		ID string `json:"id"`
+		// person
+		Type   string `json:"type"`
+		Actual bool   `json:"actual"`
+		Name   string `json:"name"`
+		// Number of beneficiaries attributed by the latest attribution file
+		Quantity int `json:"quantity"`
+		// Name, timestamp, and performance year period of the attribution file
+		Extension []map[string]interface{} `json:"extension"`
+		// Requested page of attributed beneficiaries, identified by MBI
+		Member []struct {
+			Entity   map[string]interface{} `json:"entity"`
+			Inactive bool                   `json:"inactive"`
+		} `json:"member"`
+	}
+}
+
 // File of newline-delimited JSON FHIR objects
 // swagger:response FileNDJSON
 type FileNDJSON struct {
 	// Header defining encoding type used
 	// enum: gzip
 	ContentEncoding string `json:"Content-Encoding"`
+	// Entity tag of the file, derived from the SHA-256 digest of the compressed file
+	ETag string `json:"ETag"`
+	// SHA-256 digest of the compressed file (i.e., `sha-256=<base64 digest>`), only present when the compressed file is served
+	Digest string `json:"Digest"`
 	// in: body
 	Body NDJSON
 }
@@ -195,7 +266,7 @@ type NDJSON string
 // A JobStatus parameter model.
 //
 // This is used for operations that want the ID of a job in the path
-// swagger:parameters jobStatus jobStatusV2 serveData deleteJob deleteJobV2
+// swagger:parameters jobStatus jobStatusV2 serveData deleteJob deleteJobV2 retryJob retryJobV2
 type JobIDParam struct {
 	// ID of data export job
 	//
@@ -218,6 +289,12 @@ type ServeDataRequestHeaders struct {
 	// in: header
 	// enum: gzip
 	AcceptEncoding string `json:"Accept-Encoding"`
+	// Byte range of the stored (gzip encoded) file to download (i.e., `bytes=1024-`)
+	// in: header
+	Range string `json:"Range"`
+	// Only return the requested range if the file still matches the given ETag
+	// in: header
+	IfRange string `json:"If-Range"`
 }
 
 // swagger:parameters bulkPatientRequest bulkGroupRequest
@@ -248,6 +325,48 @@ type SinceParam struct {
 	DateTime string `json:"_since"`
 }
 
+// swagger:parameters bulkPatientRequest bulkGroupRequest bulkPatientRequestV2 bulkGroupRequestV2
+type TypeFilterParam struct {
+	// FHIR search queries used to restrict the resources returned for a given resource type (i.e., `ExplanationOfBenefit?type=inpatient`).  Supported search parameters are `type` and `service-date` for ExplanationOfBenefit and `service-date` for Claim and ClaimResponse.  Multiple queries for the same resource type are combined with OR.
+	// in: query
+	// style: form
+	// explode: false
+	// required: false
+	TypeFilter []string `json:"_typeFilter"`
+}
+
+// swagger:parameters bulkPatientRequest bulkGroupRequest bulkPatientRequestV2 bulkGroupRequestV2
+type ElementsParam struct {
+	// Top level elements to include in the returned resources, optionally prefixed with the resource type (i.e., `id` or `ExplanationOfBenefit.patient`).  Mandatory elements are always included and resources are tagged as `SUBSETTED`.
+	// in: query
+	// style: form
+	// explode: false
+	// required: false
+	Elements []string `json:"_elements"`
+}
+
+// swagger:parameters bulkGroupRequest bulkGroupRequestV2 bulkPatientRequest bulkPatientRequestV2
+type PatientParam struct {
+	// MBIs of the beneficiaries to export, supplied as bare MBIs or Patient references (i.e., `Patient/1SA0A00AA00`).  Every beneficiary must be attributed to the ACO.  Only supported for Patient export when the Prefer header is omitted, in which case a limited number of beneficiaries may be requested.
+	// in: query
+	// style: form
+	// explode: false
+	// required: false
+	Patient []string `json:"patient"`
+}
+
+// swagger:parameters readGroupV2
+type GroupPagingParam struct {
+	// Maximum number of members to return (at most 1000). Members are only returned when supplied.
+	// in: query
+	// required: false
+	Count int `json:"_count"`
+	// Number of members to skip
+	// in: query
+	// required: false
+	Offset int `json:"_offset"`
+}
+
 // swagger:parameters jobsStatus jobsStatusV2
 type StatusParam struct {
 	// Job statuses requested
@@ -259,8 +378,24 @@ type StatusParam struct {
 	Status []JobStatus `json:"_status"`
 }
 
-// swagger:parameters bulkPatientRequest bulkGroupRequest bulkPatientRequestV2 bulkGroupRequestV2
+// swagger:parameters bulkPatientRequestPost bulkGroupRequestPost bulkPatientRequestPostV2 bulkGroupRequestPostV2
+type ParametersBody struct {
+	// FHIR Parameters resource containing the export parameters (i.e., `{"resourceType": "Parameters", "parameter": [{"name": "_type", "valueString": "Patient"}]}`)
+	// in: body
+	// required: true
+	Body struct {
+		ResourceType string `json:"resourceType"`
+		Parameter    []struct {
+			Name         string `json:"name"`
+			ValueString  string `json:"valueString,omitempty"`
+			ValueInstant string `json:"valueInstant,omitempty"`
+		} `json:"parameter"`
+	}
+}
+
+// swagger:parameters bulkPatientRequest bulkGroupRequest bulkPatientRequestV2 bulkGroupRequestV2 bulkPatientRequestPost bulkGroupRequestPost bulkPatientRequestPostV2 bulkGroupRequestPostV2
 type BulkRequestHeaders struct {
+	// Required unless a synchronous Patient export is requested via the patient parameter
 	// required: true
 	// in: header
 	// enum: respond-async
@@ -270,7 +405,7 @@ type BulkRequestHeaders struct {
 // A BulkGroupRequest parameter model.
 //
 // This is used for operations that want the groupID of a group in the path
-// swagger:parameters bulkGroupRequest bulkGroupRequestV2
+// swagger:parameters bulkGroupRequest bulkGroupRequestV2 bulkGroupRequestPost bulkGroupRequestPostV2 readGroupV2
 type GroupIDParam struct {
 	// ID of group export
 	// in: path
@@ -23,3 +23,12 @@ type Bundle struct {
 }
 
 type BundleEntry map[string]interface{}
+
+type OperationOutcome struct {
+	Resource
+	Issues []struct {
+		Severity    string `json:"severity"`
+		Code        string `json:"code"`
+		Diagnostics string `json:"diagnostics"`
+	} `json:"issue"`
+}
@@ -31,6 +31,27 @@ func (_m *MockRepository) CreateACO(ctx context.Context, aco ACO) error {
 	return r0
 }
 
+// CreateCCLFAttributionDelta provides a mock function with given fields: ctx, delta
+func (_m *MockRepository) CreateCCLFAttributionDelta(ctx context.Context, delta CCLFAttributionDelta) (uint, error) {
+	ret := _m.Called(ctx, delta)
+
+	var r0 uint
+	if rf, ok := ret.Get(0).(func(context.Context, CCLFAttributionDelta) uint); ok {
+		r0 = rf(ctx, delta)
+	} else {
+		r0 = ret.Get(0).(uint)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, CCLFAttributionDelta) error); ok {
+		r1 = rf(ctx, delta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateCCLFFile provides a mock function with given fields: ctx, cclfFile
 func (_m *MockRepository) CreateCCLFFile(ctx context.Context, cclfFile CCLFFile) (uint, error) {
 	ret := _m.Called(ctx, cclfFile)
@@ -200,6 +221,29 @@ func (_m *MockRepository) GetAlrMBIs(ctx context.Context, cmsID string) (*AlrMBI
 	return r0, r1
 }
 
+// GetCCLFAttributionDelta provides a mock function with given fields: ctx, cclfFileID
+func (_m *MockRepository) GetCCLFAttributionDelta(ctx context.Context, cclfFileID uint) (*CCLFAttributionDelta, error) {
+	ret := _m.Called(ctx, cclfFileID)
+
+	var r0 *CCLFAttributionDelta
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *CCLFAttributionDelta); ok {
+		r0 = rf(ctx, cclfFileID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*CCLFAttributionDelta)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, cclfFileID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetCCLFBeneficiaries provides a mock function with given fields: ctx, cclfFileID, ignoredMBIs
 func (_m *MockRepository) GetCCLFBeneficiaries(ctx context.Context, cclfFileID uint, ignoredMBIs []string) ([]*CCLFBeneficiary, error) {
 	ret := _m.Called(ctx, cclfFileID, ignoredMBIs)
@@ -223,6 +267,50 @@ func (_m *MockRepository) GetCCLFBeneficiaries(ctx context.Context, cclfFileID u
 	return r0, r1
 }
 
+// GetCCLFBeneficiariesPage provides a mock function with given fields: ctx, cclfFileID, ignoredMBIs, offset, limit
+func (_m *MockRepository) GetCCLFBeneficiariesPage(ctx context.Context, cclfFileID uint, ignoredMBIs []string, offset int, limit int) ([]*CCLFBeneficiary, error) {
+	ret := _m.Called(ctx, cclfFileID, ignoredMBIs, offset, limit)
+
+	var r0 []*CCLFBeneficiary
+	if rf, ok := ret.Get(0).(func(context.Context, uint, []string, int, int) []*CCLFBeneficiary); ok {
+		r0 = rf(ctx, cclfFileID, ignoredMBIs, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*CCLFBeneficiary)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint, []string, int, int) error); ok {
+		r1 = rf(ctx, cclfFileID, ignoredMBIs, offset, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCCLFBeneficiaryCount provides a mock function with given fields: ctx, cclfFileID, ignoredMBIs
+func (_m *MockRepository) GetCCLFBeneficiaryCount(ctx context.Context, cclfFileID uint, ignoredMBIs []string) (int, error) {
+	ret := _m.Called(ctx, cclfFileID, ignoredMBIs)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, uint, []string) int); ok {
+		r0 = rf(ctx, cclfFileID, ignoredMBIs)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint, []string) error); ok {
+		r1 = rf(ctx, cclfFileID, ignoredMBIs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetCCLFBeneficiaryMBIs provides a mock function with given fields: ctx, cclfFileID
 func (_m *MockRepository) GetCCLFBeneficiaryMBIs(ctx context.Context, cclfFileID uint) ([]string, error) {
 	ret := _m.Called(ctx, cclfFileID)
@@ -290,6 +378,29 @@ func (_m *MockRepository) GetJobByID(ctx context.Context, jobID uint) (*Job, err
 	return r0, r1
 }
 
+// GetFailedQueueJobs provides a mock function with given fields: ctx, jobID
+func (_m *MockRepository) GetFailedQueueJobs(ctx context.Context, jobID uint) ([]*FailedQueueJob, error) {
+	ret := _m.Called(ctx, jobID)
+
+	var r0 []*FailedQueueJob
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []*FailedQueueJob); ok {
+		r0 = rf(ctx, jobID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*FailedQueueJob)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, jobID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetJobKeys provides a mock function with given fields: ctx, jobID
 func (_m *MockRepository) GetJobKeys(ctx context.Context, jobID uint) ([]*JobKey, error) {
 	ret := _m.Called(ctx, jobID)
@@ -336,6 +447,29 @@ func (_m *MockRepository) GetJobKey(ctx context.Context, jobID uint, filename st
 	return r0, r1
 }
 
+// GetPendingQueueJobCounts provides a mock function with given fields: ctx, jobID
+func (_m *MockRepository) GetPendingQueueJobCounts(ctx context.Context, jobID uint) (map[string]int, error) {
+	ret := _m.Called(ctx, jobID)
+
+	var r0 map[string]int
+	if rf, ok := ret.Get(0).(func(context.Context, uint) map[string]int); ok {
+		r0 = rf(ctx, jobID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]int)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, jobID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetJobs provides a mock function with given fields: ctx, acoID, statuses
 func (_m *MockRepository) GetJobs(ctx context.Context, acoID uuid.UUID, statuses ...JobStatus) ([]*Job, error) {
 	_va := make([]interface{}, len(statuses))
@@ -419,6 +553,36 @@ func (_m *MockRepository) GetLatestCCLFFile(ctx context.Context, cmsID string, c
 	return r0, r1
 }
 
+// GetRetryJobs provides a mock function with given fields: ctx, jobID, statuses
+func (_m *MockRepository) GetRetryJobs(ctx context.Context, jobID uint, statuses ...JobStatus) ([]*Job, error) {
+	_va := make([]interface{}, len(statuses))
+	for _i := range statuses {
+		_va[_i] = statuses[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, jobID)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 []*Job
+	if rf, ok := ret.Get(0).(func(context.Context, uint, ...JobStatus) []*Job); ok {
+		r0 = rf(ctx, jobID, statuses...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*Job)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint, ...JobStatus) error); ok {
+		r1 = rf(ctx, jobID, statuses...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetSuppressedMBIs provides a mock function with given fields: ctx, lookbackDays, upperBound
 func (_m *MockRepository) GetSuppressedMBIs(ctx context.Context, lookbackDays int, upperBound time.Time) ([]string, error) {
 	ret := _m.Called(ctx, lookbackDays, upperBound)
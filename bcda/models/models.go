@@ -2,6 +2,8 @@ package models
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -27,14 +29,26 @@ type JobStatus string
 type Job struct {
 	ID              uint
 	ACOID           uuid.UUID `json:"aco_id"`
-	RequestURL      string    `json:"request_url"` // request_url
-	Status          JobStatus `json:"status"`      // status
+	RequestURL      string    `json:"request_url"`    // request_url
+	RequestMethod   string    `json:"request_method"` // HTTP method of the export request; POST requests keep a GET-style RequestURL
+	Status          JobStatus `json:"status"`         // status
 	TransactionTime time.Time // most recent data load transaction time from BFD
 	JobCount        int
+	RetryOf         uint // ID of the completed job whose failed beneficiaries this job exports
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 }
 
+// Request describes the export request as "<method> <url>". Jobs created without a
+// recorded method were requested with GET.
+func (j *Job) Request() string {
+	method := j.RequestMethod
+	if method == "" {
+		method = http.MethodGet
+	}
+	return method + " " + j.RequestURL
+}
+
 func (j *Job) StatusMessage(numCompletedJobKeys int) string {
 	if j.Status == JobStatusInProgress && j.JobCount > 0 {
 		pct := float64(numCompletedJobKeys) / float64(j.JobCount) * 100
@@ -55,12 +69,42 @@ type JobKey struct {
 	QueJobID     *int64
 	FileName     string
 	ResourceType string
+	// Number of resources written to the file
+	ResourceCount int64
+	// Size (in bytes) of the file before and after compression
+	UncompressedSize int64
+	CompressedSize   int64
+	// Hex encoded SHA-256 digest of the compressed file
+	Digest string
+	// Number of beneficiaries processed by the queue job and the number of them that resulted in an error
+	BeneficiaryCount int64
+	ErrorCount       int64
 }
 
 func (j *JobKey) IsError() bool {
 	return strings.Contains(j.FileName, "-error.ndjson")
 }
 
+// FailedQueueJob records the beneficiaries that a queue job failed to export.
+// They can be exported again by a retry job once the parent job has completed.
+type FailedQueueJob struct {
+	JobID    uint
+	QueJobID int64
+	// Args contains the queue job's arguments, restricted to the beneficiaries that failed
+	Args JobEnqueueArgs
+}
+
+// ResourceProgress summarizes the progress of a job's queue jobs for a single resource type
+type ResourceProgress struct {
+	ResourceType string
+	// Number of queue jobs that have written their job keys, out of all queue jobs enqueued for the resource type
+	CompletedQueueJobs int
+	TotalQueueJobs     int
+	// Number of beneficiaries processed by the completed queue jobs and the number of them that resulted in an error
+	BeneficiariesProcessed int64
+	Errors                 int64
+}
+
 // ACO represents an Accountable Care Organization.
 type ACO struct {
 	ID                 uint
@@ -121,6 +165,21 @@ type CCLFBeneficiary struct {
 	BlueButtonID string
 }
 
+// CCLFAttributionDelta describes how the beneficiaries attributed to an ACO changed between a CCLF8 file
+// and the ACO's previously imported CCLF8 file of the same type.
+type CCLFAttributionDelta struct {
+	ID     uint
+	FileID uint
+	// PreviousFileID is 0 when the ACO had no previously imported file, in which case every beneficiary is added
+	PreviousFileID uint
+	AddedMBIs      []string
+	RemovedMBIs    []string
+	AddedCount     int
+	RemovedCount   int
+	RetainedCount  int
+	CreatedAt      time.Time
+}
+
 const (
 	QUE_PROCESS_JOB = "ProcessJob"
 	ALR_JOB         = "AlrJob"
@@ -140,7 +199,11 @@ type JobEnqueueArgs struct {
 		LowerBound time.Time
 		UpperBound time.Time
 	}
-	DataType string
+	DataType     string
+	TypeFilter   url.Values   // search parameters applied to a single BFD request for ResourceType
+	TypeFilters  []url.Values // queries supplied via _typeFilter for ResourceType, combined with OR
+	Elements     []string     // top level elements supplied via _elements for ResourceType
+	OutputFormat string       // e.g. ndjson, parquet; defaults to ndjson when empty
 }
 
 // Needed by River (queue library)
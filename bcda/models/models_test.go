@@ -30,6 +30,14 @@ func (s *ModelsTestSuite) TestJobStatusMessage() {
 	assert.Equal(s.T(), string(JobStatusCompleted), j.StatusMessage(25))
 }
 
+func (s *ModelsTestSuite) TestJobRequest() {
+	j := Job{RequestURL: "https://bcda.test.gov/api/v2/Patient/$export?_type=Patient"}
+	assert.Equal(s.T(), "GET https://bcda.test.gov/api/v2/Patient/$export?_type=Patient", j.Request())
+
+	j.RequestMethod = "POST"
+	assert.Equal(s.T(), "POST https://bcda.test.gov/api/v2/Patient/$export?_type=Patient", j.Request())
+}
+
 func (s *ModelsTestSuite) TestACODenylist() {
 	denyListDate := time.Date(2020, time.December, 31, 23, 59, 59, 0, time.Local)
 	denyListValues := []Termination{
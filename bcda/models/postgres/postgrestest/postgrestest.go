@@ -219,9 +219,11 @@ func DeleteJobByID(t *testing.T, db *sql.DB, jobID uint) {
 
 func CreateJobKeys(t *testing.T, db *sql.DB, jobKeys ...models.JobKey) {
 	ib := sqlFlavor.NewInsertBuilder().InsertInto("job_keys")
-	ib.Cols("job_id", "file_name", "resource_type")
+	ib.Cols("job_id", "que_job_id", "file_name", "resource_type", "resource_count", "uncompressed_size", "compressed_size", "digest",
+		"beneficiary_count", "error_count")
 	for _, key := range jobKeys {
-		ib.Values(key.JobID, key.FileName, key.ResourceType)
+		ib.Values(key.JobID, key.QueJobID, key.FileName, key.ResourceType, key.ResourceCount, key.UncompressedSize, key.CompressedSize, key.Digest,
+			key.BeneficiaryCount, key.ErrorCount)
 	}
 
 	query, args := ib.Build()
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -16,6 +17,7 @@ import (
 	"github.com/CMSgov/bcda-app/bcda/models"
 	"github.com/CMSgov/bcda-app/log"
 	"github.com/CMSgov/bcda-app/optout"
+	"github.com/ccoveille/go-safecast"
 )
 
 const (
@@ -228,8 +230,42 @@ func (r *Repository) GetCCLFBeneficiaryMBIs(ctx context.Context, cclfFileID uint
 }
 
 func (r *Repository) GetCCLFBeneficiaries(ctx context.Context, cclfFileID uint, ignoredMBIs []string) ([]*models.CCLFBeneficiary, error) {
-	var beneficiaries []*models.CCLFBeneficiary
+	sb := sqlFlavor.NewSelectBuilder()
+	sb.Select("id", "file_id", "mbi", "blue_button_id")
+	whereCCLFBeneficiaries(sb, cclfFileID, ignoredMBIs)
+
+	query, args := sb.Build()
+	return r.getCCLFBeneficiaries(ctx, query, args...)
+}
+
+// GetCCLFBeneficiariesPage returns up to limit beneficiaries of the CCLF file ordered by ID, skipping the first offset beneficiaries
+func (r *Repository) GetCCLFBeneficiariesPage(ctx context.Context, cclfFileID uint, ignoredMBIs []string, offset, limit int) ([]*models.CCLFBeneficiary, error) {
+	sb := sqlFlavor.NewSelectBuilder()
+	sb.Select("id", "file_id", "mbi", "blue_button_id")
+	whereCCLFBeneficiaries(sb, cclfFileID, ignoredMBIs)
+	sb.OrderBy("id").Limit(limit).Offset(offset)
+
+	query, args := sb.Build()
+	return r.getCCLFBeneficiaries(ctx, query, args...)
+}
+
+// GetCCLFBeneficiaryCount returns the number of beneficiaries of the CCLF file
+func (r *Repository) GetCCLFBeneficiaryCount(ctx context.Context, cclfFileID uint, ignoredMBIs []string) (int, error) {
+	sb := sqlFlavor.NewSelectBuilder()
+	sb.Select("COUNT(*)")
+	whereCCLFBeneficiaries(sb, cclfFileID, ignoredMBIs)
+
+	query, args := sb.Build()
+	var count int
+	if err := r.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
 
+// whereCCLFBeneficiaries restricts the query to the beneficiaries of the CCLF file that are not ignored
+func whereCCLFBeneficiaries(sb *sqlbuilder.SelectBuilder, cclfFileID uint, ignoredMBIs []string) {
 	// Subquery to deal with duplicate MBIs found within a single CCLF file.
 	// NOTE: We no longer have duplicate MBIs after this PR: https://github.com/CMSgov/bcda-app/pull/583
 	// We have to remove duplicates on older files, but once that's done, we can remove the subquery
@@ -239,8 +275,6 @@ func (r *Repository) GetCCLFBeneficiaries(ctx context.Context, cclfFileID uint,
 		subSB.Equal("file_id", cclfFileID),
 	).GroupBy("mbi")
 
-	sb := sqlFlavor.NewSelectBuilder()
-	sb.Select("id", "file_id", "mbi", "blue_button_id")
 	sb.From("cclf_beneficiaries").Where(sb.In("id", subSB))
 
 	if len(ignoredMBIs) != 0 {
@@ -250,10 +284,12 @@ func (r *Repository) GetCCLFBeneficiaries(ctx context.Context, cclfFileID uint,
 		}
 		sb.Where(sb.NotIn("mbi", ignored...))
 	}
+}
 
-	query, args := sb.Build()
-	rows, err := r.QueryContext(ctx, query, args...)
+func (r *Repository) getCCLFBeneficiaries(ctx context.Context, query string, args ...interface{}) ([]*models.CCLFBeneficiary, error) {
+	var beneficiaries []*models.CCLFBeneficiary
 
+	rows, err := r.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -291,6 +327,112 @@ func (r *Repository) CreateSuppression(ctx context.Context, suppression optout.O
 	return err
 }
 
+const (
+	attributionDeltaAdded   = "added"
+	attributionDeltaRemoved = "removed"
+
+	// attributionDeltaBatchSize limits the number of MBIs written by a single insert statement
+	attributionDeltaBatchSize = 1000
+)
+
+func (r *Repository) CreateCCLFAttributionDelta(ctx context.Context, delta models.CCLFAttributionDelta) (uint, error) {
+	// The first file imported for an ACO does not have a previous file
+	var previousFileID interface{}
+	if delta.PreviousFileID != 0 {
+		previousFileID = delta.PreviousFileID
+	}
+
+	ib := sqlFlavor.NewInsertBuilder().InsertInto("cclf_attribution_deltas")
+	ib.Cols("file_id", "previous_file_id", "added_count", "removed_count", "retained_count").
+		Values(delta.FileID, previousFileID, delta.AddedCount, delta.RemovedCount, delta.RetainedCount)
+	query, args := ib.Build()
+	query = fmt.Sprintf(constants.CCLFFileRetID, query)
+
+	var id uint
+	if err := r.QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	if err := r.createCCLFAttributionDeltaMBIs(ctx, id, attributionDeltaAdded, delta.AddedMBIs); err != nil {
+		return 0, err
+	}
+	if err := r.createCCLFAttributionDeltaMBIs(ctx, id, attributionDeltaRemoved, delta.RemovedMBIs); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *Repository) createCCLFAttributionDeltaMBIs(ctx context.Context, deltaID uint, change string, mbis []string) error {
+	for start := 0; start < len(mbis); start += attributionDeltaBatchSize {
+		ib := sqlFlavor.NewInsertBuilder().InsertInto("cclf_attribution_delta_beneficiaries")
+		ib.Cols("delta_id", "mbi", "change")
+		for _, mbi := range mbis[start:min(start+attributionDeltaBatchSize, len(mbis))] {
+			ib.Values(deltaID, mbi, change)
+		}
+
+		query, args := ib.Build()
+		if _, err := r.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Repository) GetCCLFAttributionDelta(ctx context.Context, cclfFileID uint) (*models.CCLFAttributionDelta, error) {
+	sb := sqlFlavor.NewSelectBuilder().Select("id", "previous_file_id", "added_count", "removed_count", "retained_count", "created_at").
+		From("cclf_attribution_deltas")
+	sb.Where(sb.Equal("file_id", cclfFileID))
+
+	var (
+		delta          = models.CCLFAttributionDelta{FileID: cclfFileID}
+		previousFileID sql.NullInt64
+	)
+	query, args := sb.Build()
+	err := r.QueryRowContext(ctx, query, args...).Scan(&delta.ID, &previousFileID, &delta.AddedCount, &delta.RemovedCount,
+		&delta.RetainedCount, &delta.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if delta.PreviousFileID, err = safecast.ToUint(previousFileID.Int64); err != nil {
+		return nil, err
+	}
+
+	sb = sqlFlavor.NewSelectBuilder().Select("mbi", "change").From("cclf_attribution_delta_beneficiaries")
+	sb.Where(sb.Equal("delta_id", delta.ID))
+	sb.OrderBy("id")
+
+	query, args = sb.Build()
+	rows, err := r.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var mbi, change string
+		if err = rows.Scan(&mbi, &change); err != nil {
+			return nil, err
+		}
+		switch change {
+		case attributionDeltaAdded:
+			delta.AddedMBIs = append(delta.AddedMBIs, mbi)
+		case attributionDeltaRemoved:
+			delta.RemovedMBIs = append(delta.RemovedMBIs, mbi)
+		default:
+			return nil, fmt.Errorf("unexpected change %s for MBI in attribution delta %d", change, delta.ID)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &delta, nil
+}
+
 func (r *Repository) GetSuppressedMBIs(ctx context.Context, lookbackDays int, upperBound time.Time) ([]string, error) {
 	var suppressedMBIs []string
 
@@ -367,7 +509,7 @@ func (r *Repository) UpdateSuppressionFileImportStatus(ctx context.Context, file
 	return nil
 }
 
-var jobColumns []string = []string{"id", "aco_id", "request_url", "status", "transaction_time", "job_count", "created_at", "updated_at"}
+var jobColumns []string = []string{"id", "aco_id", "request_url", "request_method", "status", "transaction_time", "job_count", "retry_of", "created_at", "updated_at"}
 
 func (r *Repository) GetJobs(ctx context.Context, acoID uuid.UUID, statuses ...models.JobStatus) ([]*models.Job, error) {
 	s := make([]interface{}, len(statuses))
@@ -421,28 +563,57 @@ func (r *Repository) GetJobByID(ctx context.Context, jobID uint) (*models.Job, e
 
 	var (
 		j                                     models.Job
+		retryOf                               sql.NullInt64
 		transactionTime, createdAt, updatedAt sql.NullTime
 	)
 
-	err := r.QueryRowContext(ctx, query, args...).Scan(&j.ID, &j.ACOID, &j.RequestURL, &j.Status, &transactionTime,
-		&j.JobCount, &createdAt, &updatedAt)
+	err := r.QueryRowContext(ctx, query, args...).Scan(&j.ID, &j.ACOID, &j.RequestURL, &j.RequestMethod, &j.Status, &transactionTime,
+		&j.JobCount, &retryOf, &createdAt, &updatedAt)
 	j.TransactionTime, j.CreatedAt, j.UpdatedAt = transactionTime.Time, createdAt.Time, updatedAt.Time
 
 	if err != nil {
 		return nil, err
 	}
 
+	if j.RetryOf, err = safecast.ToUint(retryOf.Int64); err != nil {
+		return nil, err
+	}
+
 	return &j, nil
 }
 
+// GetRetryJobs returns the jobs created to retry the failed beneficiaries of the job
+func (r *Repository) GetRetryJobs(ctx context.Context, jobID uint, statuses ...models.JobStatus) ([]*models.Job, error) {
+	s := make([]interface{}, len(statuses))
+	for i, v := range statuses {
+		s[i] = v
+	}
+
+	sb := sqlFlavor.NewSelectBuilder().Select(jobColumns...).From("jobs")
+	sb.Where(sb.Equal("retry_of", jobID))
+
+	if len(s) > 0 {
+		sb.Where(sb.In("status", s...))
+	}
+
+	query, args := sb.Build()
+	return r.getJobs(ctx, query, args...)
+}
+
 func (r *Repository) CreateJob(ctx context.Context, j models.Job) (uint, error) {
+	// Only retry jobs reference another job
+	var retryOf interface{}
+	if j.RetryOf != 0 {
+		retryOf = j.RetryOf
+	}
+
 	// User raw builder since we need to retrieve the associated ID
 	ib := sqlFlavor.NewInsertBuilder().InsertInto("jobs")
-	ib.Cols("aco_id", "request_url", "status",
-		"transaction_time", "job_count",
+	ib.Cols("aco_id", "request_url", "request_method", "status",
+		"transaction_time", "job_count", "retry_of",
 		"created_at", "updated_at").
-		Values(j.ACOID, j.RequestURL, j.Status,
-			j.TransactionTime, j.JobCount,
+		Values(j.ACOID, j.RequestURL, j.RequestMethod, j.Status,
+			j.TransactionTime, j.JobCount, retryOf,
 			sqlbuilder.Raw("NOW()"), sqlbuilder.Raw("NOW()"))
 
 	query, args := ib.Build()
@@ -462,6 +633,7 @@ func (r *Repository) UpdateJob(ctx context.Context, j models.Job) error {
 	ub.Set(
 		ub.Assign("aco_id", j.ACOID),
 		ub.Assign("request_url", j.RequestURL),
+		ub.Assign("request_method", j.RequestMethod),
 		ub.Assign("status", j.Status),
 		ub.Assign("transaction_time", j.TransactionTime),
 		ub.Assign("job_count", j.JobCount),
@@ -487,8 +659,44 @@ func (r *Repository) UpdateJob(ctx context.Context, j models.Job) error {
 	return nil
 }
 
+// GetFailedQueueJobs returns the beneficiaries that the job's queue jobs failed to export, ordered by queue job
+func (r *Repository) GetFailedQueueJobs(ctx context.Context, jobID uint) ([]*models.FailedQueueJob, error) {
+	sb := sqlFlavor.NewSelectBuilder().Select("que_job_id", "args").From("failed_queue_jobs")
+	sb.Where(sb.Equal("job_id", jobID))
+	sb.OrderBy("que_job_id")
+
+	query, args := sb.Build()
+	rows, err := r.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var failed []*models.FailedQueueJob
+	for rows.Next() {
+		var (
+			f    = models.FailedQueueJob{JobID: jobID}
+			data []byte
+		)
+		if err = rows.Scan(&f.QueJobID, &data); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(data, &f.Args); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal arguments of failed queue job %d: %w", f.QueJobID, err)
+		}
+		failed = append(failed, &f)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return failed, nil
+}
+
 func (r *Repository) GetJobKeys(ctx context.Context, jobID uint) ([]*models.JobKey, error) {
-	sb := sqlFlavor.NewSelectBuilder().Select("id", "file_name", "resource_type").From("job_keys")
+	sb := sqlFlavor.NewSelectBuilder().Select("id", "que_job_id", "file_name", "resource_type", "resource_count", "uncompressed_size", "compressed_size", "digest",
+		"beneficiary_count", "error_count").From("job_keys")
 	sb.Where(sb.Equal("job_id", jobID))
 
 	query, args := sb.Build()
@@ -501,7 +709,7 @@ func (r *Repository) GetJobKeys(ctx context.Context, jobID uint) ([]*models.JobK
 	var keys []*models.JobKey
 	for rows.Next() {
 		jk := models.JobKey{JobID: jobID}
-		if err = rows.Scan(&jk.ID, &jk.FileName, &jk.ResourceType); err != nil {
+		if err = scanJobKey(rows, &jk); err != nil {
 			return nil, err
 		}
 		keys = append(keys, &jk)
@@ -515,7 +723,8 @@ func (r *Repository) GetJobKeys(ctx context.Context, jobID uint) ([]*models.JobK
 }
 
 func (r *Repository) GetJobKey(ctx context.Context, jobID uint, fileName string) (*models.JobKey, error) {
-	sb := sqlFlavor.NewSelectBuilder().Select("id", "file_name", "resource_type").From("job_keys")
+	sb := sqlFlavor.NewSelectBuilder().Select("id", "que_job_id", "file_name", "resource_type", "resource_count", "uncompressed_size", "compressed_size", "digest",
+		"beneficiary_count", "error_count").From("job_keys")
 	sb.Where(sb.And(sb.Equal("job_id", jobID), sb.Equal("file_name", fileName)))
 
 	query, args := sb.Build()
@@ -523,13 +732,72 @@ func (r *Repository) GetJobKey(ctx context.Context, jobID uint, fileName string)
 
 	jk := &models.JobKey{JobID: jobID}
 
-	if err := row.Scan(&jk.ID, &jk.FileName, &jk.ResourceType); err != nil {
+	if err := scanJobKey(row, jk); err != nil {
 		return nil, err
 	}
 
 	return jk, nil
 }
 
+// GetPendingQueueJobCounts returns the number of river queue jobs that have not yet finished for the given job,
+// keyed by resource type. Queue jobs enqueued through que are not tracked and will not be counted.
+func (r *Repository) GetPendingQueueJobCounts(ctx context.Context, jobID uint) (map[string]int, error) {
+	const resourceType = "args->>'ResourceType'"
+	sb := sqlFlavor.NewSelectBuilder().Select(resourceType, "COUNT(*)").From("river_job")
+	sb.Where(
+		sb.Equal("kind", models.QUE_PROCESS_JOB),
+		sb.Equal("(args->>'ID')::bigint", jobID),
+		sb.NotIn("state", "completed", "cancelled", "discarded"),
+	)
+	sb.GroupBy(resourceType)
+
+	query, args := sb.Build()
+	rows, err := r.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var (
+			resource string
+			count    int
+		)
+		if err = rows.Scan(&resource, &count); err != nil {
+			return nil, err
+		}
+		counts[resource] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// scanJobKey scans the job key columns selected by GetJobKeys and GetJobKey.
+// The resource count, file sizes, digest, and beneficiary/error counts are not populated for job keys created prior to their introduction.
+func scanJobKey(row interface{ Scan(dest ...any) error }, jk *models.JobKey) error {
+	var (
+		count, uncompressedSize, compressedSize sql.NullInt64
+		beneficiaryCount, errorCount            sql.NullInt64
+		digest                                  sql.NullString
+	)
+	if err := row.Scan(&jk.ID, &jk.QueJobID, &jk.FileName, &jk.ResourceType, &count, &uncompressedSize, &compressedSize, &digest,
+		&beneficiaryCount, &errorCount); err != nil {
+		return err
+	}
+	jk.ResourceCount = count.Int64
+	jk.UncompressedSize = uncompressedSize.Int64
+	jk.CompressedSize = compressedSize.Int64
+	jk.Digest = digest.String
+	jk.BeneficiaryCount = beneficiaryCount.Int64
+	jk.ErrorCount = errorCount.Int64
+	return nil
+}
+
 func (r *Repository) getJobs(ctx context.Context, query string, args ...interface{}) ([]*models.Job, error) {
 	rows, err := r.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -539,15 +807,19 @@ func (r *Repository) getJobs(ctx context.Context, query string, args ...interfac
 
 	var (
 		jobs                                  []*models.Job
+		retryOf                               sql.NullInt64
 		transactionTime, createdAt, updatedAt sql.NullTime
 	)
 	for rows.Next() {
 		var j models.Job
-		if err = rows.Scan(&j.ID, &j.ACOID, &j.RequestURL, &j.Status, &transactionTime,
-			&j.JobCount, &createdAt, &updatedAt); err != nil {
+		if err = rows.Scan(&j.ID, &j.ACOID, &j.RequestURL, &j.RequestMethod, &j.Status, &transactionTime,
+			&j.JobCount, &retryOf, &createdAt, &updatedAt); err != nil {
 			return nil, err
 		}
 		j.TransactionTime, j.CreatedAt, j.UpdatedAt = transactionTime.Time, createdAt.Time, updatedAt.Time
+		if j.RetryOf, err = safecast.ToUint(retryOf.Int64); err != nil {
+			return nil, err
+		}
 		jobs = append(jobs, &j)
 	}
 
@@ -575,6 +575,27 @@ func (r *RepositoryTestSuite) TestCCLFBeneficiariesMethods() {
 	assert.NoError(err)
 	assert.Len(benes, 0)
 
+	count, err := r.repository.GetCCLFBeneficiaryCount(ctx, cclfFile.ID, nil)
+	assert.NoError(err)
+	assert.Equal(2, count)
+
+	count, err = r.repository.GetCCLFBeneficiaryCount(ctx, cclfFile.ID, []string{bene1.MBI})
+	assert.NoError(err)
+	assert.Equal(1, count)
+
+	// Pages are ordered by ID
+	benes, err = r.repository.GetCCLFBeneficiariesPage(ctx, cclfFile.ID, nil, 0, 1)
+	assert.NoError(err)
+	assert.Equal([]*models.CCLFBeneficiary{bene1}, benes)
+
+	benes, err = r.repository.GetCCLFBeneficiariesPage(ctx, cclfFile.ID, nil, 1, 5)
+	assert.NoError(err)
+	assert.Equal([]*models.CCLFBeneficiary{bene2}, benes)
+
+	benes, err = r.repository.GetCCLFBeneficiariesPage(ctx, cclfFile.ID, []string{bene1.MBI}, 0, 5)
+	assert.NoError(err)
+	assert.Equal([]*models.CCLFBeneficiary{bene2}, benes)
+
 	// Negative cases
 	mbis, err = r.repository.GetCCLFBeneficiaryMBIs(ctx, 0)
 	assert.NoError(err)
@@ -585,6 +606,58 @@ func (r *RepositoryTestSuite) TestCCLFBeneficiariesMethods() {
 	assert.Len(benes, 0)
 }
 
+// TestCCLFAttributionDeltaMethods validates the CRUD operations associated with the cclf_attribution_deltas table
+func (r *RepositoryTestSuite) TestCCLFAttributionDeltaMethods() {
+	ctx := context.Background()
+	assert := r.Assert()
+
+	cmsID := testUtils.RandomHexID()[0:4]
+	previousFile := &models.CCLFFile{CCLFNum: 8, ACOCMSID: cmsID, Timestamp: time.Now().Add(-24 * time.Hour), PerformanceYear: 19, Name: uuid.New()}
+	cclfFile := &models.CCLFFile{CCLFNum: 8, ACOCMSID: cmsID, Timestamp: time.Now(), PerformanceYear: 19, Name: uuid.New()}
+	firstFile := &models.CCLFFile{CCLFNum: 8, ACOCMSID: cmsID, Timestamp: time.Now().Add(-48 * time.Hour), PerformanceYear: 19, Name: uuid.New()}
+	postgrestest.CreateCCLFFile(r.T(), r.db, previousFile)
+	postgrestest.CreateCCLFFile(r.T(), r.db, cclfFile)
+	postgrestest.CreateCCLFFile(r.T(), r.db, firstFile)
+	defer postgrestest.DeleteCCLFFilesByCMSID(r.T(), r.db, cmsID)
+
+	// Use more MBIs than are written by a single insert statement
+	delta := models.CCLFAttributionDelta{FileID: cclfFile.ID, PreviousFileID: previousFile.ID, RetainedCount: 10}
+	for i := 0; i < 1500; i++ {
+		delta.AddedMBIs = append(delta.AddedMBIs, testUtils.RandomMBI(r.T()))
+	}
+	delta.RemovedMBIs = []string{testUtils.RandomMBI(r.T()), testUtils.RandomMBI(r.T())}
+	delta.AddedCount, delta.RemovedCount = len(delta.AddedMBIs), len(delta.RemovedMBIs)
+
+	var err error
+	delta.ID, err = r.repository.CreateCCLFAttributionDelta(ctx, delta)
+	assert.NoError(err)
+
+	result, err := r.repository.GetCCLFAttributionDelta(ctx, cclfFile.ID)
+	assert.NoError(err)
+	assert.NotNil(result)
+	assert.False(result.CreatedAt.IsZero())
+	delta.CreatedAt = result.CreatedAt
+	assert.Equal(delta, *result)
+
+	// The first file for an ACO does not have a previous file or removed MBIs
+	firstDelta := models.CCLFAttributionDelta{FileID: firstFile.ID, AddedMBIs: []string{testUtils.RandomMBI(r.T())}, AddedCount: 1}
+	firstDelta.ID, err = r.repository.CreateCCLFAttributionDelta(ctx, firstDelta)
+	assert.NoError(err)
+	result, err = r.repository.GetCCLFAttributionDelta(ctx, firstFile.ID)
+	assert.NoError(err)
+	assert.Equal(uint(0), result.PreviousFileID)
+	assert.Equal(firstDelta.AddedMBIs, result.AddedMBIs)
+	assert.Nil(result.RemovedMBIs)
+
+	// Negative cases
+	result, err = r.repository.GetCCLFAttributionDelta(ctx, previousFile.ID)
+	assert.NoError(err)
+	assert.Nil(result)
+
+	_, err = r.repository.CreateCCLFAttributionDelta(ctx, delta)
+	assert.ErrorContains(err, "duplicate key value violates unique constraint")
+}
+
 // TestSuppressionsMethods validates the CRUD operations associated with the suppressions table
 func (r *RepositoryTestSuite) TestSuppresionsMethods() {
 	ctx := context.Background()
@@ -777,6 +850,54 @@ func (r *RepositoryTestSuite) TestJobsMethods() {
 	assert.EqualError(r.repository.UpdateJob(ctx, notExists), "expected to affect 1 row, affected 0")
 }
 
+// TestRetryJobsMethods validates that retry jobs reference the job they retry
+func (r *RepositoryTestSuite) TestRetryJobsMethods() {
+	var err error
+	ctx := context.Background()
+	assert := r.Assert()
+
+	cmsID := testUtils.RandomHexID()[0:4]
+	aco := models.ACO{UUID: uuid.NewRandom(), Name: uuid.New(), CMSID: &cmsID}
+	postgrestest.CreateACO(r.T(), r.db, aco)
+
+	defer postgrestest.DeleteACO(r.T(), r.db, aco.UUID)
+
+	completed := models.Job{ACOID: aco.UUID, Status: models.JobStatusCompleted}
+	completed.ID, err = r.repository.CreateJob(ctx, completed)
+	assert.NoError(err)
+
+	retry := models.Job{ACOID: aco.UUID, Status: models.JobStatusCompleted, RetryOf: completed.ID}
+	retry.ID, err = r.repository.CreateJob(ctx, retry)
+	assert.NoError(err)
+
+	pending := models.Job{ACOID: aco.UUID, Status: models.JobStatusPending, RetryOf: completed.ID}
+	pending.ID, err = r.repository.CreateJob(ctx, pending)
+	assert.NoError(err)
+
+	job, err := r.repository.GetJobByID(ctx, pending.ID)
+	assert.NoError(err)
+	assert.Equal(completed.ID, job.RetryOf)
+
+	job, err = r.repository.GetJobByID(ctx, completed.ID)
+	assert.NoError(err)
+	assert.Zero(job.RetryOf)
+
+	jobs, err := r.repository.GetRetryJobs(ctx, completed.ID)
+	assert.NoError(err)
+	assert.Len(jobs, 2)
+	assertContainsJobID(assert, jobs, retry.ID)
+	assertContainsJobID(assert, jobs, pending.ID)
+
+	jobs, err = r.repository.GetRetryJobs(ctx, completed.ID, models.JobStatusPending, models.JobStatusInProgress)
+	assert.NoError(err)
+	assert.Len(jobs, 1)
+	assertContainsJobID(assert, jobs, pending.ID)
+
+	// Only one retry of a job may be pending or in progress at a time
+	_, err = r.repository.CreateJob(ctx, models.Job{ACOID: aco.UUID, Status: models.JobStatusPending, RetryOf: completed.ID})
+	assert.ErrorContains(err, "idx_jobs_retry_of_active")
+}
+
 // TestJobKeysMethods validates the CRUD operations associated with the job_keys table for multiple results
 func (r *RepositoryTestSuite) TestJobKeysMethods() {
 	ctx := context.Background()
@@ -811,7 +932,9 @@ func (r *RepositoryTestSuite) TestJobKeyMethods() {
 
 	jobID, _ := safecast.ToUint(testUtils.CryptoRandInt31())
 	fileName := uuid.New()
-	jk1 := models.JobKey{JobID: jobID, FileName: fileName}
+	queJobID := int64(testUtils.CryptoRandInt31())
+	jk1 := models.JobKey{JobID: jobID, QueJobID: &queJobID, FileName: fileName, ResourceCount: 10, UncompressedSize: 4096, CompressedSize: 1024,
+		BeneficiaryCount: 5, ErrorCount: 1}
 	jk2 := models.JobKey{JobID: jobID, FileName: uuid.New()}
 	jk3 := models.JobKey{JobID: jobID, FileName: uuid.New()}
 
@@ -821,6 +944,53 @@ func (r *RepositoryTestSuite) TestJobKeyMethods() {
 	assert.Nil(r.T(), err)
 	assert.Equal(r.T(), jobID, key.JobID)
 	assert.Equal(r.T(), fileName, strings.TrimSpace(key.FileName))
+	assert.Equal(r.T(), int64(10), key.ResourceCount)
+	assert.Equal(r.T(), int64(4096), key.UncompressedSize)
+	assert.Equal(r.T(), int64(1024), key.CompressedSize)
+	assert.Equal(r.T(), &queJobID, key.QueJobID)
+	assert.Equal(r.T(), int64(5), key.BeneficiaryCount)
+	assert.Equal(r.T(), int64(1), key.ErrorCount)
+}
+
+// TestGetPendingQueueJobCounts verifies that only unfinished river jobs for the given job are counted
+func (r *RepositoryTestSuite) TestGetPendingQueueJobCounts() {
+	ctx := context.Background()
+	assert := r.Assert()
+
+	jobID, _ := safecast.ToUint(testUtils.CryptoRandInt31())
+	otherJobID, _ := safecast.ToUint(testUtils.CryptoRandInt31())
+
+	insertJob := func(id uint, resourceType, state string) {
+		var finalizedAt *time.Time
+		if state == "completed" || state == "cancelled" || state == "discarded" {
+			now := time.Now()
+			finalizedAt = &now
+		}
+		args := fmt.Sprintf(`{"ID": %d, "ResourceType": %q}`, id, resourceType)
+		_, err := r.db.Exec(`INSERT INTO river_job (state, max_attempts, finalized_at, args, kind) VALUES ($1, 3, $2, $3, $4)`,
+			state, finalizedAt, args, models.QUE_PROCESS_JOB)
+		assert.NoError(err)
+	}
+	defer func() {
+		_, err := r.db.Exec(`DELETE FROM river_job WHERE (args->>'ID')::bigint IN ($1, $2)`, jobID, otherJobID)
+		assert.NoError(err)
+	}()
+
+	insertJob(jobID, "Patient", "available")
+	insertJob(jobID, "Patient", "running")
+	insertJob(jobID, "Patient", "completed")
+	insertJob(jobID, "Coverage", "retryable")
+	insertJob(jobID, "Coverage", "discarded")
+	insertJob(jobID, "ExplanationOfBenefit", "cancelled")
+	insertJob(otherJobID, "Patient", "available")
+
+	counts, err := r.repository.GetPendingQueueJobCounts(ctx, jobID)
+	assert.NoError(err)
+	assert.Equal(map[string]int{"Patient": 2, "Coverage": 1}, counts)
+
+	counts, err = r.repository.GetPendingQueueJobCounts(ctx, 0)
+	assert.NoError(err)
+	assert.Empty(counts)
 }
 
 // TestCMSID verifies that we can store and retrieve the CMS_ID as expected
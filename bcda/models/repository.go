@@ -15,6 +15,7 @@ type Repository interface {
 	acoRepository
 	cclfFileRepository
 	cclfBeneficiaryRepository
+	cclfAttributionDeltaRepository
 	suppressionRepository
 	suppressionFileRepository
 	jobRepository
@@ -56,6 +57,20 @@ type cclfBeneficiaryRepository interface {
 	GetCCLFBeneficiaryMBIs(ctx context.Context, cclfFileID uint) ([]string, error)
 
 	GetCCLFBeneficiaries(ctx context.Context, cclfFileID uint, ignoredMBIs []string) ([]*CCLFBeneficiary, error)
+
+	// GetCCLFBeneficiariesPage returns up to limit beneficiaries ordered by ID, skipping the first offset beneficiaries
+	GetCCLFBeneficiariesPage(ctx context.Context, cclfFileID uint, ignoredMBIs []string, offset, limit int) ([]*CCLFBeneficiary, error)
+
+	GetCCLFBeneficiaryCount(ctx context.Context, cclfFileID uint, ignoredMBIs []string) (int, error)
+}
+
+type cclfAttributionDeltaRepository interface {
+	// CreateCCLFAttributionDelta creates the attribution delta, along with its added and removed MBIs, and returns its ID
+	CreateCCLFAttributionDelta(ctx context.Context, delta CCLFAttributionDelta) (uint, error)
+
+	// GetCCLFAttributionDelta returns the attribution delta computed when the CCLF file was imported,
+	// or nil if the file does not have one.
+	GetCCLFAttributionDelta(ctx context.Context, cclfFileID uint) (*CCLFAttributionDelta, error)
 }
 
 type suppressionRepository interface {
@@ -81,12 +96,19 @@ type jobRepository interface {
 	GetJobByID(ctx context.Context, jobID uint) (*Job, error)
 
 	UpdateJob(ctx context.Context, j Job) error
+
+	GetFailedQueueJobs(ctx context.Context, jobID uint) ([]*FailedQueueJob, error)
+
+	// GetRetryJobs returns the jobs created to retry the failed beneficiaries of the job
+	GetRetryJobs(ctx context.Context, jobID uint, statuses ...JobStatus) ([]*Job, error)
 }
 
 type JobKeyRepository interface {
 	GetJobKeys(ctx context.Context, jobID uint) ([]*JobKey, error)
 
 	GetJobKey(ctx context.Context, jobID uint, filename string) (*JobKey, error)
+
+	GetPendingQueueJobCounts(ctx context.Context, jobID uint) (map[string]int, error)
 }
 
 type alr interface {
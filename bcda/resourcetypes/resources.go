@@ -0,0 +1,305 @@
+package resourcetypes
+
+import (
+	"fmt"
+
+	"github.com/CMSgov/bcda-app/bcda/client"
+	"github.com/CMSgov/bcda-app/bcda/constants"
+	"github.com/CMSgov/bcda-app/bcda/models"
+	fhirModels "github.com/CMSgov/bcda-app/bcda/models/fhir"
+)
+
+// DataType is used to identify the type of data returned by each resource
+type DataType struct {
+	Adjudicated          bool
+	PartiallyAdjudicated bool
+}
+
+// FetchFunc streams a resource type for a single beneficiary from the BlueButton API,
+// passing each page of the bundle to handle as it is received.
+type FetchFunc func(bb client.StreamingAPIClient, jobArgs models.JobEnqueueArgs, bene models.CCLFBeneficiary, handle client.PageHandler) error
+
+// Resource describes a FHIR resource type that can be exported. Each resource type is
+// registered once and the API, service and worker all derive their behavior from it.
+type Resource struct {
+	Name     string
+	DataType DataType
+	// APIVersions lists the API versions that the resource can be requested from
+	APIVersions []string
+	// Default indicates whether the resource is exported when the caller does not supply _type
+	Default bool
+
+	// MaxBeneficiaries is the number of beneficiaries handled by a single job.
+	// It can be overridden by setting MaxBeneficiariesEnv.
+	MaxBeneficiaries    int
+	MaxBeneficiariesEnv string
+
+	// RequiresMBI indicates that BFD is queried by MBI rather than by BFD patient ID
+	RequiresMBI bool
+	// MandatoryElements are the top level elements with a minimum cardinality of one, which must
+	// be retained when the resource is subsetted via _elements.
+	MandatoryElements []string
+	// TypeFilterParams are the search parameters that callers may supply via _typeFilter. These are
+	// passed through to BFD, so they must not conflict with the ones we set when requesting data.
+	TypeFilterParams []string
+
+	Fetch FetchFunc
+}
+
+var (
+	resources   []Resource
+	dataTypeMap map[string]DataType
+)
+
+// SupportsDataType checks if the dataType is supported by the instanced DataType object
+func (r DataType) SupportsDataType(dataType string) bool {
+	switch dataType {
+	case constants.PartiallyAdjudicated:
+		return r.PartiallyAdjudicated
+	case constants.Adjudicated:
+		return r.Adjudicated
+	default:
+		return false
+	}
+}
+
+// init registers the supported resource types
+func init() {
+	dataTypeMap = make(map[string]DataType)
+
+	RegisterResource(Resource{
+		Name:                "Patient",
+		DataType:            DataType{Adjudicated: true},
+		APIVersions:         []string{"v1", "v2"},
+		Default:             true,
+		MaxBeneficiaries:    5000,
+		MaxBeneficiariesEnv: "BCDA_FHIR_MAX_RECORDS_PATIENT",
+		Fetch: func(bb client.StreamingAPIClient, jobArgs models.JobEnqueueArgs, bene models.CCLFBeneficiary, handle client.PageHandler) error {
+			return bb.StreamPatient(jobArgs, bene.BlueButtonID, handle)
+		},
+	})
+	RegisterResource(Resource{
+		Name:                "ExplanationOfBenefit",
+		DataType:            DataType{Adjudicated: true},
+		APIVersions:         []string{"v1", "v2"},
+		Default:             true,
+		MaxBeneficiaries:    50,
+		MaxBeneficiariesEnv: "BCDA_FHIR_MAX_RECORDS_EOB",
+		MandatoryElements:   []string{"status", "type", "use", "patient", "created", "insurer", "provider", "outcome", "insurance"},
+		TypeFilterParams:    []string{"type", "service-date"},
+		Fetch: func(bb client.StreamingAPIClient, jobArgs models.JobEnqueueArgs, bene models.CCLFBeneficiary, handle client.PageHandler) error {
+			return bb.StreamExplanationOfBenefit(jobArgs, bene.BlueButtonID, claimsWindow(jobArgs), handle)
+		},
+	})
+	RegisterResource(Resource{
+		Name:                "Coverage",
+		DataType:            DataType{Adjudicated: true},
+		APIVersions:         []string{"v1", "v2"},
+		Default:             true,
+		MaxBeneficiaries:    4000,
+		MaxBeneficiariesEnv: "BCDA_FHIR_MAX_RECORDS_COVERAGE",
+		MandatoryElements:   []string{"status", "beneficiary", "payor"},
+		Fetch: func(bb client.StreamingAPIClient, jobArgs models.JobEnqueueArgs, bene models.CCLFBeneficiary, handle client.PageHandler) error {
+			return bb.StreamCoverage(jobArgs, bene.BlueButtonID, handle)
+		},
+	})
+	RegisterResource(Resource{
+		Name:                "Claim",
+		DataType:            DataType{PartiallyAdjudicated: true},
+		APIVersions:         []string{"v2"},
+		Default:             true,
+		MaxBeneficiaries:    4000,
+		MaxBeneficiariesEnv: "BCDA_FHIR_MAX_RECORDS_CLAIM",
+		RequiresMBI:         true,
+		MandatoryElements:   []string{"status", "type", "use", "patient", "created", "provider", "priority", "insurance"},
+		TypeFilterParams:    []string{"service-date"},
+		Fetch: func(bb client.StreamingAPIClient, jobArgs models.JobEnqueueArgs, bene models.CCLFBeneficiary, handle client.PageHandler) error {
+			return bb.StreamClaim(jobArgs, bene.MBI, claimsWindow(jobArgs), handle)
+		},
+	})
+	RegisterResource(Resource{
+		Name:                "ClaimResponse",
+		DataType:            DataType{PartiallyAdjudicated: true},
+		APIVersions:         []string{"v2"},
+		Default:             true,
+		MaxBeneficiaries:    4000,
+		MaxBeneficiariesEnv: "BCDA_FHIR_MAX_RECORDS_CLAIM_RESPONSE",
+		RequiresMBI:         true,
+		MandatoryElements:   []string{"status", "type", "use", "patient", "created", "insurer", "outcome"},
+		TypeFilterParams:    []string{"service-date"},
+		Fetch: func(bb client.StreamingAPIClient, jobArgs models.JobEnqueueArgs, bene models.CCLFBeneficiary, handle client.PageHandler) error {
+			return bb.StreamClaimResponse(jobArgs, bene.MBI, claimsWindow(jobArgs), handle)
+		},
+	})
+	// Observation is not exported by default, so callers must request it via _type
+	RegisterResource(Resource{
+		Name:                "Observation",
+		DataType:            DataType{Adjudicated: true},
+		APIVersions:         []string{"v1", "v2"},
+		MaxBeneficiaries:    4000,
+		MaxBeneficiariesEnv: "BCDA_FHIR_MAX_RECORDS_OBSERVATION",
+		MandatoryElements:   []string{"status", "code"},
+		Fetch: func(bb client.StreamingAPIClient, jobArgs models.JobEnqueueArgs, bene models.CCLFBeneficiary, handle client.PageHandler) error {
+			return bb.StreamObservation(jobArgs, bene.BlueButtonID, claimsWindow(jobArgs), handle)
+		},
+	})
+}
+
+// RegisterResource adds a resource type to the registry. It panics if the resource type
+// is already registered or does not supply a fetch function.
+func RegisterResource(r Resource) {
+	if _, ok := dataTypeMap[r.Name]; ok {
+		panic(fmt.Sprintf("resource type %s is already registered", r.Name))
+	}
+	if r.Fetch == nil {
+		panic(fmt.Sprintf("resource type %s does not have a fetch function", r.Name))
+	}
+
+	resources = append(resources, r)
+	dataTypeMap[r.Name] = r.DataType
+}
+
+// GetResource gets the registered Resource associated with the given resourceName
+func GetResource(resourceName string) (Resource, bool) {
+	for _, r := range resources {
+		if r.Name == resourceName {
+			return r, true
+		}
+	}
+
+	return Resource{}, false
+}
+
+// GetResources returns the registered resources in the order they were registered
+func GetResources() []Resource {
+	return append([]Resource(nil), resources...)
+}
+
+// GetResourceNames returns the names of the resources that can be requested from the API version
+func GetResourceNames(apiVersion string) []string {
+	var names []string
+	for _, r := range resources {
+		for _, v := range r.APIVersions {
+			if v == apiVersion {
+				names = append(names, r.Name)
+				break
+			}
+		}
+	}
+
+	return names
+}
+
+// GetDataType gets the DataType associated with the given resourceName
+func GetDataType(resourceName string) (DataType, bool) {
+	resource, ok := dataTypeMap[resourceName]
+
+	return resource, ok
+}
+
+// GetDataTypes creates a map of the given resourceNames with their associated DataType objects
+// It returns the resource map and a status flag, with true meaning all resources were found and mapped.
+func GetDataTypes(resourceNames ...string) (map[string]DataType, bool) {
+	foundAll := true
+
+	returnMap := make(map[string]DataType, len(resourceNames))
+
+	if len(resourceNames) == 0 {
+		// If no resource specified, return copy of full map
+		for name, entry := range dataTypeMap {
+			returnMap[name] = entry
+		}
+	} else {
+		// If resources specified, return map subset
+		for _, name := range resourceNames {
+			if entry, ok := dataTypeMap[name]; ok {
+				returnMap[name] = entry
+			} else {
+				foundAll = false
+			}
+		}
+	}
+
+	return returnMap, foundAll
+}
+
+// FetchFiltered streams the resource type for a single beneficiary, applying the _typeFilter
+// queries in jobArgs.TypeFilters. BFD only accepts a single query per request, so each query is
+// fetched separately and resources matched by an earlier query are skipped.
+func (r Resource) FetchFiltered(bb client.StreamingAPIClient, jobArgs models.JobEnqueueArgs, bene models.CCLFBeneficiary, handle client.PageHandler) error {
+	switch len(jobArgs.TypeFilters) {
+	case 0:
+		return r.Fetch(bb, jobArgs, bene, handle)
+	case 1:
+		jobArgs.TypeFilter = jobArgs.TypeFilters[0]
+		return r.Fetch(bb, jobArgs, bene, handle)
+	}
+
+	seen := make(map[string]bool)
+	dedupe := func(page *fhirModels.Bundle) error {
+		entries := page.Entries[:0]
+		for _, entry := range page.Entries {
+			resource, _ := entry["resource"].(map[string]interface{})
+			id, ok := resource["id"].(string)
+			if ok && seen[id] {
+				continue
+			}
+			if ok {
+				seen[id] = true
+			}
+			entries = append(entries, entry)
+		}
+		page.Entries = entries
+		return handle(page)
+	}
+
+	for _, filter := range jobArgs.TypeFilters {
+		jobArgs.TypeFilter = filter
+		if err := r.Fetch(bb, jobArgs, bene, dedupe); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SubsetResource returns a copy of the resource containing only the requested and mandatory elements.
+// The resource is tagged as SUBSETTED so consumers know that it is incomplete.
+func SubsetResource(resource map[string]interface{}, resourceType string, elements []string, bbBasePath string) map[string]interface{} {
+	var mandatoryElements []string
+	if r, ok := GetResource(resourceType); ok {
+		mandatoryElements = r.MandatoryElements
+	}
+	keep := append([]string{"resourceType", "id", "meta"}, mandatoryElements...)
+	keep = append(keep, elements...)
+
+	subset := make(map[string]interface{}, len(keep))
+	for _, element := range keep {
+		if v, ok := resource[element]; ok {
+			subset[element] = v
+		}
+	}
+
+	system := "http://terminology.hl7.org/CodeSystem/v3-ObservationValue"
+	if bbBasePath == "/v1/fhir" {
+		system = "http://hl7.org/fhir/v3/ObservationValue"
+	}
+
+	meta := make(map[string]interface{})
+	if m, ok := subset["meta"].(map[string]interface{}); ok {
+		for k, v := range m {
+			meta[k] = v
+		}
+	}
+	tags, _ := meta["tag"].([]interface{})
+	meta["tag"] = append(append([]interface{}{}, tags...), map[string]interface{}{"system": system, "code": "SUBSETTED"})
+	subset["meta"] = meta
+
+	return subset
+}
+
+func claimsWindow(jobArgs models.JobEnqueueArgs) client.ClaimsWindow {
+	return client.ClaimsWindow{
+		LowerBound: jobArgs.ClaimsWindow.LowerBound,
+		UpperBound: jobArgs.ClaimsWindow.UpperBound,
+	}
+}
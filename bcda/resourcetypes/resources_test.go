@@ -0,0 +1,272 @@
+package resourcetypes
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/CMSgov/bcda-app/bcda/client"
+	"github.com/CMSgov/bcda-app/bcda/constants"
+	"github.com/CMSgov/bcda-app/bcda/models"
+	fhirModels "github.com/CMSgov/bcda-app/bcda/models/fhir"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ResourcesTestSuite struct {
+	suite.Suite
+}
+
+// Run all suite tests
+func TestResourcesTestSuite(t *testing.T) {
+	suite.Run(t, new(ResourcesTestSuite))
+}
+
+func (s *ResourcesTestSuite) TestSupportsDataType() {
+	tests := []struct {
+		name         string
+		dataType     DataType
+		dataTypeName string
+		expected     bool
+	}{
+		{
+			"Valid Adjudicated Type",
+			DataType{Adjudicated: true, PartiallyAdjudicated: false},
+			constants.Adjudicated,
+			true,
+		},
+		{
+			"Valid Partially-Adjudicated Type",
+			DataType{Adjudicated: false, PartiallyAdjudicated: true},
+			constants.PartiallyAdjudicated,
+			true,
+		},
+		{
+			"Invalid Type",
+			DataType{Adjudicated: true, PartiallyAdjudicated: true},
+			"invalid-type",
+			false,
+		},
+		{
+			"Invalid Partially-Adjudicated Type",
+			DataType{Adjudicated: true, PartiallyAdjudicated: false},
+			constants.PartiallyAdjudicated,
+			false,
+		},
+		{
+			"Invalid Adjudicated Type",
+			DataType{Adjudicated: false, PartiallyAdjudicated: true},
+			constants.Adjudicated,
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.dataType.SupportsDataType(tt.dataTypeName))
+		})
+	}
+}
+
+func (s *ResourcesTestSuite) TestGetDataType() {
+	tests := []struct {
+		resourceName string
+		expectedType DataType
+		expectedOk   bool
+	}{
+		{"Patient", DataType{Adjudicated: true}, true},
+		{"Coverage", DataType{Adjudicated: true}, true},
+		{"ExplanationOfBenefit", DataType{Adjudicated: true}, true},
+		{"Observation", DataType{Adjudicated: true}, true},
+		{"Claim", DataType{Adjudicated: false, PartiallyAdjudicated: true}, true},
+		{"ClaimResponse", DataType{Adjudicated: false, PartiallyAdjudicated: true}, true},
+		{"InvalidResource", DataType{}, false},
+	}
+
+	for _, tt := range tests {
+		s.T().Run("Testing "+tt.resourceName, func(t *testing.T) {
+			actualType, actualOk := GetDataType(tt.resourceName)
+			assert.Equal(t, tt.expectedType, actualType)
+			assert.Equal(t, tt.expectedOk, actualOk)
+		})
+	}
+}
+
+func (s *ResourcesTestSuite) TestGetDataTypes() {
+	tests := []struct {
+		name          string
+		resourceNames []string
+		expectedTypes map[string]DataType
+		expectedOk    bool
+	}{
+		{
+			"Empty resource names",
+			[]string{},
+			map[string]DataType{
+				"Patient":              {Adjudicated: true},
+				"Coverage":             {Adjudicated: true},
+				"ExplanationOfBenefit": {Adjudicated: true},
+				"Observation":          {Adjudicated: true},
+				"Claim":                {Adjudicated: false, PartiallyAdjudicated: true},
+				"ClaimResponse":        {Adjudicated: false, PartiallyAdjudicated: true},
+			},
+			true,
+		},
+		{
+			"Valid resource names",
+			[]string{"Patient", "Claim"},
+			map[string]DataType{
+				"Patient": {Adjudicated: true},
+				"Claim":   {Adjudicated: false, PartiallyAdjudicated: true},
+			},
+			true,
+		},
+		{
+			"One invalid resource names",
+			[]string{"Patient", "InvalidResource", "Claim"},
+			map[string]DataType{
+				"Patient": {Adjudicated: true},
+				"Claim":   {Adjudicated: false, PartiallyAdjudicated: true},
+			},
+			false,
+		},
+		{
+			"All invalid resource names",
+			[]string{"InvalidResource1", "InvalidResource2"},
+			map[string]DataType{},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			actualTypes, actualOk := GetDataTypes(tt.resourceNames...)
+			assert.Equal(t, tt.expectedTypes, actualTypes)
+			assert.Equal(t, tt.expectedOk, actualOk)
+		})
+	}
+}
+
+func (s *ResourcesTestSuite) TestGetResource() {
+	resource, ok := GetResource("Claim")
+	assert.True(s.T(), ok)
+	assert.Equal(s.T(), "Claim", resource.Name)
+	assert.True(s.T(), resource.RequiresMBI)
+	assert.Equal(s.T(), DataType{PartiallyAdjudicated: true}, resource.DataType)
+	assert.Equal(s.T(), []string{"service-date"}, resource.TypeFilterParams)
+	assert.NotNil(s.T(), resource.Fetch)
+
+	resource, ok = GetResource("Coverage")
+	assert.True(s.T(), ok)
+	assert.False(s.T(), resource.RequiresMBI)
+	assert.Empty(s.T(), resource.TypeFilterParams)
+
+	_, ok = GetResource("InvalidResource")
+	assert.False(s.T(), ok)
+}
+
+func (s *ResourcesTestSuite) TestGetResourceNames() {
+	assert.Equal(s.T(), []string{"Patient", "ExplanationOfBenefit", "Coverage", "Observation"}, GetResourceNames("v1"))
+	assert.Equal(s.T(), []string{"Patient", "ExplanationOfBenefit", "Coverage", "Claim", "ClaimResponse", "Observation"}, GetResourceNames("v2"))
+	assert.Empty(s.T(), GetResourceNames("v3"))
+}
+
+func (s *ResourcesTestSuite) TestSubsetResource() {
+	resource := map[string]interface{}{
+		"resourceType": "ExplanationOfBenefit",
+		"id":           "123",
+		"meta":         map[string]interface{}{"lastUpdated": "2020-01-01T00:00:00Z", "tag": []interface{}{map[string]interface{}{"code": "Latest"}}},
+		"status":       "active",
+		"patient":      map[string]interface{}{"reference": "Patient/-1"},
+		"total":        []interface{}{},
+		"item":         []interface{}{},
+	}
+
+	tests := []struct {
+		name       string
+		bbBasePath string
+		elements   []string
+		expKeys    []string
+		expSystem  string
+	}{
+		{"R4", "/v2/fhir", []string{"total"}, []string{"resourceType", "id", "meta", "status", "patient", "total"}, "http://terminology.hl7.org/CodeSystem/v3-ObservationValue"},
+		{"STU3", "/v1/fhir", []string{"identifier"}, []string{"resourceType", "id", "meta", "status", "patient"}, "http://hl7.org/fhir/v3/ObservationValue"},
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			subset := SubsetResource(resource, "ExplanationOfBenefit", tt.elements, tt.bbBasePath)
+			assert.Len(t, subset, len(tt.expKeys))
+			for _, key := range tt.expKeys {
+				assert.Contains(t, subset, key)
+			}
+
+			meta := subset["meta"].(map[string]interface{})
+			assert.Equal(t, "2020-01-01T00:00:00Z", meta["lastUpdated"])
+			tags := meta["tag"].([]interface{})
+			assert.Len(t, tags, 2)
+			assert.Equal(t, map[string]interface{}{"system": tt.expSystem, "code": "SUBSETTED"}, tags[1])
+		})
+	}
+
+	// Original resource is left untouched
+	assert.Len(s.T(), resource["meta"].(map[string]interface{})["tag"], 1)
+	assert.Contains(s.T(), resource, "item")
+}
+
+func (s *ResourcesTestSuite) TestRegisterResource() {
+	fetch := func(bb client.StreamingAPIClient, jobArgs models.JobEnqueueArgs, bene models.CCLFBeneficiary, handle client.PageHandler) error {
+		return nil
+	}
+	assert.PanicsWithValue(s.T(), "resource type Patient is already registered", func() {
+		RegisterResource(Resource{Name: "Patient", Fetch: fetch})
+	})
+	assert.PanicsWithValue(s.T(), "resource type Unfetchable does not have a fetch function", func() {
+		RegisterResource(Resource{Name: "Unfetchable"})
+	})
+	_, ok := GetResource("Unfetchable")
+	assert.False(s.T(), ok)
+}
+
+func (s *ResourcesTestSuite) TestFetchFiltered() {
+	pages := map[string][]fhirModels.BundleEntry{
+		"carrier": {{"resource": map[string]interface{}{"id": "1"}}, {"resource": map[string]interface{}{"id": "2"}}},
+		"pde":     {{"resource": map[string]interface{}{"id": "2"}}, {"resource": map[string]interface{}{"id": "3"}}},
+	}
+	var filters []url.Values
+	resource := Resource{
+		Name: "ExplanationOfBenefit",
+		Fetch: func(bb client.StreamingAPIClient, jobArgs models.JobEnqueueArgs, bene models.CCLFBeneficiary, handle client.PageHandler) error {
+			filters = append(filters, jobArgs.TypeFilter)
+			return handle(&fhirModels.Bundle{Entries: append([]fhirModels.BundleEntry{}, pages[jobArgs.TypeFilter.Get("type")]...)})
+		},
+	}
+
+	tests := []struct {
+		name       string
+		filters    []url.Values
+		expFilters []url.Values
+		expIDs     []string
+	}{
+		{"NoFilter", nil, []url.Values{nil}, nil},
+		{"SingleFilter", []url.Values{{"type": {"carrier"}}}, []url.Values{{"type": {"carrier"}}}, []string{"1", "2"}},
+		{"MultipleFilters", []url.Values{{"type": {"carrier"}}, {"type": {"pde"}}},
+			[]url.Values{{"type": {"carrier"}}, {"type": {"pde"}}}, []string{"1", "2", "3"}},
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			filters = nil
+			b, err := client.CollectPages(func(handle client.PageHandler) error {
+				return resource.FetchFiltered(nil, models.JobEnqueueArgs{TypeFilters: tt.filters}, models.CCLFBeneficiary{}, handle)
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expFilters, filters)
+
+			var ids []string
+			for _, entry := range b.Entries {
+				ids = append(ids, entry["resource"].(map[string]interface{})["id"].(string))
+			}
+			assert.Equal(t, tt.expIDs, ids)
+		})
+	}
+}
@@ -102,7 +102,7 @@ func CreateJobsBundleEntry(job *models.Job, host string) *fhirmodelCR.Bundle_Ent
 					Input: []*fhirmodelT.Task_Parameter{
 						{
 							Type:  &fhirdatatypes.CodeableConcept{Text: &fhirdatatypes.String{Value: "BULK FHIR Export"}},
-							Value: &fhirmodelT.Task_Parameter_ValueX{Choice: &fhirmodelT.Task_Parameter_ValueX_StringValue{StringValue: &fhirdatatypes.String{Value: "GET " + job.RequestURL}}},
+							Value: &fhirmodelT.Task_Parameter_ValueX{Choice: &fhirmodelT.Task_Parameter_ValueX_StringValue{StringValue: &fhirdatatypes.String{Value: job.Request()}}},
 						},
 					},
 					ExecutionPeriod: &fhirdatatypes.Period{
@@ -287,6 +287,26 @@ func CreateCapabilityStatement(reldate time.Time, relversion, baseurl string) *f
 	}
 	return statement
 }
+
+// ExportResources lists the resource types that can be requested via the export operations
+func ExportResources(resourceTypes ...string) []*fhirmodelCS.CapabilityStatement_Rest_Resource {
+	resources := make([]*fhirmodelCS.CapabilityStatement_Rest_Resource, 0, len(resourceTypes))
+	for _, resourceType := range resourceTypes {
+		code, ok := fhircodes.ResourceTypeCode_Value_value[responseutils.ResourceTypeCodeName(resourceType)]
+		if !ok {
+			continue
+		}
+		resources = append(resources, &fhirmodelCS.CapabilityStatement_Rest_Resource{
+			Type:          &fhirmodelCS.CapabilityStatement_Rest_Resource_TypeCode{Value: fhircodes.ResourceTypeCode_Value(code)},
+			Documentation: &fhirdatatypes.Markdown{Value: "Available through the patient-export and group-export operations"},
+			Interaction: []*fhirmodelCS.CapabilityStatement_Rest_Resource_ResourceInteraction{
+				{Code: &fhirmodelCS.CapabilityStatement_Rest_Resource_ResourceInteraction_CodeType{Value: fhirvaluesets.TypeRestfulInteractionValueSet_SEARCH_TYPE}},
+			},
+		})
+	}
+	return resources
+}
+
 func WriteCapabilityStatement(ctx context.Context, statement *fhirmodelCS.CapabilityStatement, w http.ResponseWriter) {
 	resource := &fhirmodelCR.ContainedResource{
 		OneofResource: &fhirmodelCR.ContainedResource_CapabilityStatement{CapabilityStatement: statement},
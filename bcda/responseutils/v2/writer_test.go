@@ -115,6 +115,15 @@ func (s *ResponseUtilsWriterTestSuite) TestCreateCapabilityStatement() {
 	assert.Equal(s.T(), fhircodes.FHIRVersionCode_V_3_0_1, cs.FhirVersion.Value)
 }
 
+func (s *ResponseUtilsWriterTestSuite) TestExportResources() {
+	var resourceTypes []fhircodes.ResourceTypeCode_Value
+	for _, r := range ExportResources("ClaimResponse", "Observation", "Unknown") {
+		resourceTypes = append(resourceTypes, r.Type.Value)
+		assert.Equal(s.T(), "Available through the patient-export and group-export operations", r.Documentation.Value)
+	}
+	assert.Equal(s.T(), []fhircodes.ResourceTypeCode_Value{fhircodes.ResourceTypeCode_CLAIM_RESPONSE, fhircodes.ResourceTypeCode_OBSERVATION}, resourceTypes)
+}
+
 func (s *ResponseUtilsWriterTestSuite) TestWriteCapabilityStatement() {
 	relversion := "r1"
 	baseurl := "bcda.cms.gov"
@@ -204,6 +213,11 @@ func (s *ResponseUtilsWriterTestSuite) TestCreateJobsBundleEntry() {
 	assert.Equal(s.T(), "GET "+job.RequestURL, jbe.Input[0].Value.GetStringValue().Value)
 	assert.Equal(s.T(), fhirvaluesets.TaskIntentValueSet_ORDER, jbe.Intent.Value)
 	assert.Equal(s.T(), fhircodes.TaskStatusCode_COMPLETED, jbe.Status.Value)
+
+	// POST $export jobs are reported with the method used to request them
+	job.RequestMethod = "POST"
+	jbe = CreateJobsBundleEntry(&job, constants.TestAPIUrl).Resource.GetTask()
+	assert.Equal(s.T(), "POST "+job.RequestURL, jbe.Input[0].Value.GetStringValue().Value)
 }
 
 func (s *ResponseUtilsWriterTestSuite) TestGetFhirStatusCode() {
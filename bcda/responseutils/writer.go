@@ -6,7 +6,9 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/CMSgov/bcda-app/bcda/constants"
 	"github.com/CMSgov/bcda-app/bcda/models"
@@ -96,7 +98,7 @@ func CreateJobsBundleEntry(job *models.Job, host string) *fhirmodels.Bundle_Entr
 					Input: []*fhirmodels.Task_Parameter{
 						{
 							Type:  &fhirdatatypes.CodeableConcept{Text: &fhirdatatypes.String{Value: "BULK FHIR Export"}},
-							Value: &fhirmodels.Task_Parameter_Value{Value: &fhirmodels.Task_Parameter_Value_StringValue{StringValue: &fhirdatatypes.String{Value: "GET " + job.RequestURL}}},
+							Value: &fhirmodels.Task_Parameter_Value{Value: &fhirmodels.Task_Parameter_Value_StringValue{StringValue: &fhirdatatypes.String{Value: job.Request()}}},
 						},
 					},
 					ExecutionPeriod: &fhirdatatypes.Period{
@@ -185,7 +187,8 @@ func WriteOperationOutcome(w io.Writer, outcome *fhirmodels.OperationOutcome) (i
 	return w.Write(outcomeJSON)
 }
 
-func CreateCapabilityStatement(reldate time.Time, relversion, baseurl string) *fhirmodels.CapabilityStatement {
+// CreateCapabilityStatement describes the STU3 API, including the resource types that can be exported
+func CreateCapabilityStatement(reldate time.Time, relversion, baseurl string, resourceTypes ...string) *fhirmodels.CapabilityStatement {
 	bbServer := conf.GetEnv("BB_SERVER_LOCATION")
 	statement := &fhirmodels.CapabilityStatement{
 		Status: &fhircodes.PublicationStatusCode{Value: fhircodes.PublicationStatusCode_ACTIVE},
@@ -260,6 +263,7 @@ func CreateCapabilityStatement(reldate time.Time, relversion, baseurl string) *f
 						Code: &fhircodes.SystemRestfulInteractionCode{Value: fhircodes.SystemRestfulInteractionCode_SEARCH_SYSTEM},
 					},
 				},
+				Resource: exportResources(resourceTypes),
 				Operation: []*fhirmodels.CapabilityStatement_Rest_Operation{
 					{
 						Name: &fhirdatatypes.String{Value: "patient-export"},
@@ -283,6 +287,38 @@ func CreateCapabilityStatement(reldate time.Time, relversion, baseurl string) *f
 	}
 	return statement
 }
+
+// exportResources lists the resource types that can be requested via the export operations
+func exportResources(resourceTypes []string) []*fhirmodels.CapabilityStatement_Rest_Resource {
+	resources := make([]*fhirmodels.CapabilityStatement_Rest_Resource, 0, len(resourceTypes))
+	for _, resourceType := range resourceTypes {
+		code, ok := fhircodes.ResourceTypeCode_Value_value[ResourceTypeCodeName(resourceType)]
+		if !ok {
+			continue
+		}
+		resources = append(resources, &fhirmodels.CapabilityStatement_Rest_Resource{
+			Type:          &fhircodes.ResourceTypeCode{Value: fhircodes.ResourceTypeCode_Value(code)},
+			Documentation: &fhirdatatypes.Markdown{Value: "Available through the patient-export and group-export operations"},
+			Interaction: []*fhirmodels.CapabilityStatement_Rest_Resource_ResourceInteraction{
+				{Code: &fhircodes.TypeRestfulInteractionCode{Value: fhircodes.TypeRestfulInteractionCode_SEARCH_TYPE}},
+			},
+		})
+	}
+	return resources
+}
+
+// ResourceTypeCodeName converts a resource type into the name of its code, e.g. ExplanationOfBenefit => EXPLANATION_OF_BENEFIT
+func ResourceTypeCodeName(resourceType string) string {
+	var b strings.Builder
+	for i, r := range resourceType {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
 func WriteCapabilityStatement(ctx context.Context, statement *fhirmodels.CapabilityStatement, w http.ResponseWriter) {
 	resource := &fhirmodels.ContainedResource{
 		OneofResource: &fhirmodels.ContainedResource_CapabilityStatement{CapabilityStatement: statement},
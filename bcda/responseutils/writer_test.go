@@ -107,17 +107,27 @@ func (s *ResponseUtilsWriterTestSuite) TestWriteError() {
 func (s *ResponseUtilsWriterTestSuite) TestCreateCapabilityStatement() {
 	relversion := "r1"
 	baseurl := "bcda.cms.gov"
-	cs := CreateCapabilityStatement(time.Now(), relversion, baseurl)
+	cs := CreateCapabilityStatement(time.Now(), relversion, baseurl, "Patient", "ExplanationOfBenefit", "Observation", "Unknown")
 	assert.Equal(s.T(), relversion, cs.Software.Version.Value)
 	assert.Equal(s.T(), "Beneficiary Claims Data API", cs.Software.Name.Value)
 	assert.Equal(s.T(), baseurl, cs.Implementation.Url.Value)
 	assert.Equal(s.T(), "3.0.1", cs.FhirVersion.Value)
+
+	var resourceTypes []fhircodes.ResourceTypeCode_Value
+	for _, r := range cs.Rest[0].Resource {
+		resourceTypes = append(resourceTypes, r.Type.Value)
+	}
+	assert.Equal(s.T(), []fhircodes.ResourceTypeCode_Value{
+		fhircodes.ResourceTypeCode_PATIENT,
+		fhircodes.ResourceTypeCode_EXPLANATION_OF_BENEFIT,
+		fhircodes.ResourceTypeCode_OBSERVATION,
+	}, resourceTypes)
 }
 
 func (s *ResponseUtilsWriterTestSuite) TestWriteCapabilityStatement() {
 	relversion := "r1"
 	baseurl := "bcda.cms.gov"
-	cs := CreateCapabilityStatement(time.Now(), relversion, baseurl)
+	cs := CreateCapabilityStatement(time.Now(), relversion, baseurl, "Patient", "Coverage")
 	WriteCapabilityStatement(context.Background(), cs, s.rr)
 	var respCS *fhirmodels.CapabilityStatement
 
@@ -136,6 +146,7 @@ func (s *ResponseUtilsWriterTestSuite) TestWriteCapabilityStatement() {
 	assert.Equal(s.T(), cs.Implementation.Url.Value, respCS.Implementation.Url.Value)
 	assert.Equal(s.T(), "3.0.1", respCS.FhirVersion.Value)
 	assert.Equal(s.T(), cs.FhirVersion.Value, respCS.FhirVersion.Value)
+	assert.Len(s.T(), respCS.Rest[0].Resource, len(cs.Rest[0].Resource))
 }
 
 func (s *ResponseUtilsWriterTestSuite) TestWriteJobsBundle() {
@@ -202,6 +213,11 @@ func (s *ResponseUtilsWriterTestSuite) TestCreateJobsBundleEntry() {
 	assert.Equal(s.T(), "GET "+job.RequestURL, jbe.Input[0].Value.GetStringValue().Value)
 	assert.Equal(s.T(), fhircodes.RequestIntentCode_ORDER, jbe.Intent.Value)
 	assert.Equal(s.T(), fhircodes.TaskStatusCode_COMPLETED, jbe.Status.Value)
+
+	// POST $export jobs are reported with the method used to request them
+	job.RequestMethod = "POST"
+	jbe = CreateJobsBundleEntry(&job, constants.TestAPIUrl).Resource.GetTask()
+	assert.Equal(s.T(), "POST "+job.RequestURL, jbe.Input[0].Value.GetStringValue().Value)
 }
 
 func (s *ResponseUtilsWriterTestSuite) TestGetFhirStatusCode() {
@@ -53,6 +53,109 @@ func (_m *MockService) GetAlrJobs(ctx context.Context, alrMBI *models.AlrMBIs) [
 	return r0
 }
 
+// GetAttributionDelta provides a mock function with given fields: ctx, cmsID, fileType
+func (_m *MockService) GetAttributionDelta(ctx context.Context, cmsID string, fileType models.CCLFFileType) (*models.CCLFFile, *models.CCLFAttributionDelta, error) {
+	ret := _m.Called(ctx, cmsID, fileType)
+
+	var r0 *models.CCLFFile
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.CCLFFileType) *models.CCLFFile); ok {
+		r0 = rf(ctx, cmsID, fileType)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CCLFFile)
+		}
+	}
+
+	var r1 *models.CCLFAttributionDelta
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.CCLFFileType) *models.CCLFAttributionDelta); ok {
+		r1 = rf(ctx, cmsID, fileType)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*models.CCLFAttributionDelta)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, models.CCLFFileType) error); ok {
+		r2 = rf(ctx, cmsID, fileType)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetBeneficiaryJobs provides a mock function with given fields: ctx, conditions
+func (_m *MockService) GetBeneficiaryJobs(ctx context.Context, conditions RequestConditions) ([]*models.CCLFBeneficiary, []*models.JobEnqueueArgs, error) {
+	ret := _m.Called(ctx, conditions)
+
+	var r0 []*models.CCLFBeneficiary
+	if rf, ok := ret.Get(0).(func(context.Context, RequestConditions) []*models.CCLFBeneficiary); ok {
+		r0 = rf(ctx, conditions)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.CCLFBeneficiary)
+		}
+	}
+
+	var r1 []*models.JobEnqueueArgs
+	if rf, ok := ret.Get(1).(func(context.Context, RequestConditions) []*models.JobEnqueueArgs); ok {
+		r1 = rf(ctx, conditions)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]*models.JobEnqueueArgs)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, RequestConditions) error); ok {
+		r2 = rf(ctx, conditions)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetGroupMembers provides a mock function with given fields: ctx, cmsID, fileType, offset, count
+func (_m *MockService) GetGroupMembers(ctx context.Context, cmsID string, fileType models.CCLFFileType, offset int, count int) (*models.CCLFFile, []*models.CCLFBeneficiary, int, error) {
+	ret := _m.Called(ctx, cmsID, fileType, offset, count)
+
+	var r0 *models.CCLFFile
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.CCLFFileType, int, int) *models.CCLFFile); ok {
+		r0 = rf(ctx, cmsID, fileType, offset, count)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CCLFFile)
+		}
+	}
+
+	var r1 []*models.CCLFBeneficiary
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.CCLFFileType, int, int) []*models.CCLFBeneficiary); ok {
+		r1 = rf(ctx, cmsID, fileType, offset, count)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]*models.CCLFBeneficiary)
+		}
+	}
+
+	var r2 int
+	if rf, ok := ret.Get(2).(func(context.Context, string, models.CCLFFileType, int, int) int); ok {
+		r2 = rf(ctx, cmsID, fileType, offset, count)
+	} else {
+		r2 = ret.Get(2).(int)
+	}
+
+	var r3 error
+	if rf, ok := ret.Get(3).(func(context.Context, string, models.CCLFFileType, int, int) error); ok {
+		r3 = rf(ctx, cmsID, fileType, offset, count)
+	} else {
+		r3 = ret.Error(3)
+	}
+
+	return r0, r1, r2, r3
+}
+
 // GetJobAndKeys provides a mock function with given fields: ctx, jobID
 func (_m *MockService) GetJobAndKeys(ctx context.Context, jobID uint) (*models.Job, []*models.JobKey, error) {
 	ret := _m.Called(ctx, jobID)
@@ -108,6 +211,29 @@ func (_m *MockService) GetJobKey(ctx context.Context, jobID uint, filename strin
 	return r0, r1
 }
 
+// GetJobProgress provides a mock function with given fields: ctx, jobID
+func (_m *MockService) GetJobProgress(ctx context.Context, jobID uint) ([]*models.ResourceProgress, error) {
+	ret := _m.Called(ctx, jobID)
+
+	var r0 []*models.ResourceProgress
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []*models.ResourceProgress); ok {
+		r0 = rf(ctx, jobID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.ResourceProgress)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, jobID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetJobPriority provides a mock function with given fields: acoID, resourceType, sinceParam
 func (_m *MockService) GetJobPriority(acoID string, resourceType string, sinceParam bool) int16 {
 	ret := _m.Called(acoID, resourceType, sinceParam)
@@ -217,3 +343,35 @@ func (_m *MockService) GetACOConfigForID(cmsID string) (*ACOConfig, bool) {
 
 	return r0, r1
 }
+
+// NewRetryJob provides a mock function with given fields: ctx, jobID
+func (_m *MockService) NewRetryJob(ctx context.Context, jobID uint) (*models.Job, []*models.JobEnqueueArgs, error) {
+	ret := _m.Called(ctx, jobID)
+
+	var r0 *models.Job
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *models.Job); ok {
+		r0 = rf(ctx, jobID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Job)
+		}
+	}
+
+	var r1 []*models.JobEnqueueArgs
+	if rf, ok := ret.Get(1).(func(context.Context, uint) []*models.JobEnqueueArgs); ok {
+		r1 = rf(ctx, jobID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]*models.JobEnqueueArgs)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, uint) error); ok {
+		r2 = rf(ctx, jobID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	goerrors "errors"
 	"fmt"
+	"net/url"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,6 +17,7 @@ import (
 
 	"github.com/CMSgov/bcda-app/bcda/constants"
 	"github.com/CMSgov/bcda-app/bcda/models"
+	"github.com/CMSgov/bcda-app/bcda/resourcetypes"
 	"github.com/CMSgov/bcda-app/bcda/utils"
 	"github.com/CMSgov/bcda-app/conf"
 	"github.com/CMSgov/bcda-app/log"
@@ -33,6 +36,19 @@ type RequestConditions struct {
 	TransactionTime time.Time
 	CreationTime    time.Time
 
+	// Search parameters (keyed by resource type) supplied via _typeFilter. Each resource type
+	// may have several queries, which are combined with OR.
+	TypeFilter map[string][]url.Values
+
+	// Top level elements supplied via _elements, optionally prefixed with the resource type
+	Elements []string
+
+	// MBIs supplied via the patient parameter. When set, only these beneficiaries are exported.
+	MBIs []string
+
+	// Output format supplied via _outputFormat (e.g. ndjson, parquet)
+	OutputFormat string
+
 	// Fields set in the service
 	fileType models.CCLFFileType
 
@@ -62,18 +78,28 @@ type Service interface {
 
 	GetAlrJobs(ctx context.Context, alrMBI *models.AlrMBIs) []*models.JobAlrEnqueueArgs
 
+	GetBeneficiaryJobs(ctx context.Context, conditions RequestConditions) ([]*models.CCLFBeneficiary, []*models.JobEnqueueArgs, error)
+
 	GetJobAndKeys(ctx context.Context, jobID uint) (*models.Job, []*models.JobKey, error)
 
 	GetJobKey(ctx context.Context, jobID uint, filename string) (*models.JobKey, error)
 
+	GetJobProgress(ctx context.Context, jobID uint) ([]*models.ResourceProgress, error)
+
 	GetJobs(ctx context.Context, acoID uuid.UUID, statuses ...models.JobStatus) ([]*models.Job, error)
 
 	CancelJob(ctx context.Context, jobID uint) (uint, error)
 
+	NewRetryJob(ctx context.Context, jobID uint) (*models.Job, []*models.JobEnqueueArgs, error)
+
 	GetJobPriority(acoID string, resourceType string, sinceParam bool) int16
 
 	GetLatestCCLFFile(ctx context.Context, cmsID string, fileType models.CCLFFileType) (*models.CCLFFile, error)
 
+	GetAttributionDelta(ctx context.Context, cmsID string, fileType models.CCLFFileType) (*models.CCLFFile, *models.CCLFAttributionDelta, error)
+
+	GetGroupMembers(ctx context.Context, cmsID string, fileType models.CCLFFileType, offset, count int) (*models.CCLFFile, []*models.CCLFBeneficiary, int, error)
+
 	GetACOConfigForID(cmsID string) (*ACOConfig, bool)
 }
 
@@ -184,6 +210,34 @@ func (s *service) GetQueJobs(ctx context.Context, conditions RequestConditions)
 	return queJobs, nil
 }
 
+// GetBeneficiaryJobs returns the beneficiaries from the latest CCLF file that would be exported for the given request
+// conditions, along with the job arguments describing the data to retrieve for them. Suppressed beneficiaries are
+// excluded and every MBI in conditions.MBIs must be attributed to the ACO.
+func (s *service) GetBeneficiaryJobs(ctx context.Context, conditions RequestConditions) ([]*models.CCLFBeneficiary, []*models.JobEnqueueArgs, error) {
+	var err error
+	if conditions.timeConstraint, err = s.timeConstraints(ctx, conditions.CMSID); err != nil {
+		return nil, nil, fmt.Errorf("failed to set time constraints for caller: %w", err)
+	}
+
+	if conditions.ReqType == Runout {
+		conditions.fileType = models.FileTypeRunout
+	} else {
+		conditions.fileType = models.FileTypeDefault
+	}
+
+	benes, err := s.getBeneficiaries(ctx, conditions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	jobs, err := s.createQueueJobs(ctx, conditions, conditions.Since, benes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return benes, jobs, nil
+}
+
 func (s *service) GetJobAndKeys(ctx context.Context, jobID uint) (*models.Job, []*models.JobKey, error) {
 	j, err := s.repository.GetJobByID(ctx, jobID)
 	if err != nil {
@@ -210,6 +264,61 @@ func (s *service) GetJobKey(ctx context.Context, jobID uint, filename string) (*
 	return s.repository.GetJobKey(ctx, jobID, filename)
 }
 
+// GetJobProgress returns the progress of the job broken down by resource type, sorted by resource type.
+// Completed queue jobs are derived from the job keys while the remaining queue jobs are derived from the river queue.
+func (s *service) GetJobProgress(ctx context.Context, jobID uint) ([]*models.ResourceProgress, error) {
+	keys, err := s.repository.GetJobKeys(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := s.repository.GetPendingQueueJobCounts(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make(map[string]*models.ResourceProgress)
+	getProgress := func(resourceType string) *models.ResourceProgress {
+		p, ok := progress[resourceType]
+		if !ok {
+			p = &models.ResourceProgress{ResourceType: resourceType}
+			progress[resourceType] = p
+		}
+		return p
+	}
+
+	completed := make(map[string]map[int64]struct{})
+	for _, key := range keys {
+		p := getProgress(key.ResourceType)
+		p.BeneficiariesProcessed += key.BeneficiaryCount
+		p.Errors += key.ErrorCount
+
+		if key.QueJobID == nil {
+			continue
+		}
+		if completed[key.ResourceType] == nil {
+			completed[key.ResourceType] = make(map[int64]struct{})
+		}
+		completed[key.ResourceType][*key.QueJobID] = struct{}{}
+	}
+
+	for resourceType, count := range pending {
+		getProgress(resourceType).TotalQueueJobs += count
+	}
+
+	result := make([]*models.ResourceProgress, 0, len(progress))
+	for resourceType, p := range progress {
+		p.CompletedQueueJobs = len(completed[resourceType])
+		p.TotalQueueJobs += p.CompletedQueueJobs
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ResourceType < result[j].ResourceType
+	})
+
+	return result, nil
+}
+
 func (s *service) GetJobs(ctx context.Context, acoID uuid.UUID, statuses ...models.JobStatus) ([]*models.Job, error) {
 	jobs, err := s.repository.GetJobs(ctx, acoID, statuses...)
 	if err != nil {
@@ -253,6 +362,60 @@ func (s *service) CancelJob(ctx context.Context, jobID uint) (uint, error) {
 	return 0, ErrJobNotCancellable
 }
 
+// NewRetryJob builds a job that exports the beneficiaries that could not be exported by the completed job.
+// The returned queue jobs keep the arguments of the queue jobs that failed, including the transaction time,
+// _since and claims window, so the retry job exports the same data that the original job would have.
+// The caller is responsible for creating the job and setting its ID on the queue jobs before enqueuing them.
+func (s *service) NewRetryJob(ctx context.Context, jobID uint) (*models.Job, []*models.JobEnqueueArgs, error) {
+	job, err := s.repository.GetJobByID(ctx, jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != models.JobStatusCompleted {
+		return nil, nil, ErrJobNotRetryable
+	}
+
+	retries, err := s.repository.GetRetryJobs(ctx, jobID, models.JobStatusPending, models.JobStatusInProgress)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(retries) > 0 {
+		return nil, nil, ErrRetryInProgress
+	}
+
+	failures, err := s.repository.GetFailedQueueJobs(ctx, jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(failures) == 0 {
+		return nil, nil, ErrNoFailedBeneficiaries
+	}
+
+	retryJob := models.Job{
+		ACOID:           job.ACOID,
+		RequestURL:      job.RequestURL,
+		RequestMethod:   job.RequestMethod,
+		Status:          models.JobStatusPending,
+		TransactionTime: job.TransactionTime,
+		JobCount:        len(failures),
+		RetryOf:         jobID,
+	}
+
+	transactionID, ok := ctx.Value(middleware.CtxTransactionKey).(string)
+	if !ok {
+		transactionID = uuid.New()
+	}
+
+	queJobs := make([]*models.JobEnqueueArgs, 0, len(failures))
+	for _, f := range failures {
+		args := f.Args
+		args.TransactionID = transactionID
+		queJobs = append(queJobs, &args)
+	}
+
+	return &retryJob, queJobs, nil
+}
+
 func (s *service) createQueueJobs(ctx context.Context, conditions RequestConditions, since time.Time, beneficiaries []*models.CCLFBeneficiary) (jobs []*models.JobEnqueueArgs, err error) {
 	// persist in format ready for usage with _lastUpdated -- i.e., prepended with 'gt'
 	var sinceArg string
@@ -287,7 +450,7 @@ func (s *service) createQueueJobs(ctx context.Context, conditions RequestConditi
 						} else {
 							transactionTime = conditions.TransactionTime
 						}
-						if resource, ok := GetDataType(rt); ok {
+						if resource, ok := resourcetypes.GetDataType(rt); ok {
 							if resource.SupportsDataType(dataType) {
 								jobId, err := safecast.ToInt(conditions.JobID)
 								if err != nil {
@@ -304,6 +467,9 @@ func (s *service) createQueueJobs(ctx context.Context, conditions RequestConditi
 									TransactionTime: transactionTime,
 									BBBasePath:      s.bbBasePath,
 									DataType:        dataType,
+									TypeFilters:     conditions.TypeFilter[rt],
+									Elements:        getElements(conditions.Elements, rt),
+									OutputFormat:    conditions.OutputFormat,
 								}
 
 								s.setClaimsDate(&enqueueArgs, conditions)
@@ -409,7 +575,7 @@ func (s *service) getNewAndExistingBeneficiaries(ctx context.Context, conditions
 		return newBeneficiaries, nil, nil
 	}
 
-	oldMBIs, err := s.repository.GetCCLFBeneficiaryMBIs(ctx, cclfFileOld.ID)
+	isNewMBI, err := s.isNewMBI(ctx, cclfFileNew, cclfFileOld)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to retrieve MBIs for cmsID %s cclfFileID %d %s",
 			conditions.CMSID, cclfFileOld.ID, err.Error())
@@ -425,22 +591,50 @@ func (s *service) getNewAndExistingBeneficiaries(ctx context.Context, conditions
 			conditions.CMSID, cclfFileNew.ID)
 	}
 
-	// Split the results beteween new and old benes based on the existence of the bene in the old map
-	oldMBIMap := make(map[string]struct{}, len(oldMBIs))
-	for _, oldMBI := range oldMBIs {
-		oldMBIMap[oldMBI] = struct{}{}
-	}
+	// Split the results beteween new and old benes based on the existence of the bene in the old file
 	for _, bene := range benes {
-		if _, ok := oldMBIMap[bene.MBI]; ok {
-			beneficiaries = append(beneficiaries, bene)
-		} else {
+		if isNewMBI(bene.MBI) {
 			newBeneficiaries = append(newBeneficiaries, bene)
+		} else {
+			beneficiaries = append(beneficiaries, bene)
 		}
 	}
 
 	return newBeneficiaries, beneficiaries, nil
 }
 
+// isNewMBI returns a function reporting whether an MBI in cclfFileNew is absent from cclfFileOld. When the two files
+// are consecutive, the attribution delta recorded during import is used instead of comparing the MBIs of the two files.
+func (s *service) isNewMBI(ctx context.Context, cclfFileNew, cclfFileOld *models.CCLFFile) (func(mbi string) bool, error) {
+	delta, err := s.repository.GetCCLFAttributionDelta(ctx, cclfFileNew.ID)
+	if err != nil {
+		return nil, err
+	}
+	if delta != nil && delta.PreviousFileID == cclfFileOld.ID {
+		addedMBIMap := make(map[string]struct{}, len(delta.AddedMBIs))
+		for _, mbi := range delta.AddedMBIs {
+			addedMBIMap[mbi] = struct{}{}
+		}
+		return func(mbi string) bool {
+			_, ok := addedMBIMap[mbi]
+			return ok
+		}, nil
+	}
+
+	oldMBIs, err := s.repository.GetCCLFBeneficiaryMBIs(ctx, cclfFileOld.ID)
+	if err != nil {
+		return nil, err
+	}
+	oldMBIMap := make(map[string]struct{}, len(oldMBIs))
+	for _, oldMBI := range oldMBIs {
+		oldMBIMap[oldMBI] = struct{}{}
+	}
+	return func(mbi string) bool {
+		_, ok := oldMBIMap[mbi]
+		return !ok
+	}, nil
+}
+
 func (s *service) getBeneficiaries(ctx context.Context, conditions RequestConditions) ([]*models.CCLFBeneficiary, error) {
 	var cutoffTime time.Time
 
@@ -480,25 +674,18 @@ func (s *service) getBeneficiaries(ctx context.Context, conditions RequestCondit
 }
 
 func (s *service) getBenesByFileID(ctx context.Context, cclfFileID uint, conditions RequestConditions) ([]*models.CCLFBeneficiary, error) {
-	var (
-		ignoredMBIs []string
-		err         error
-	)
-	if !s.sp.includeSuppressedBeneficiaries {
-		upperBound := conditions.optOutDate
-		if conditions.optOutDate.IsZero() {
-			upperBound = time.Now()
-		}
+	ignoredMBIs, err := s.getSuppressedMBIs(ctx, conditions)
+	if err != nil {
+		return nil, err
+	}
 
-		if cfg, ok := SetACOCfgFromCtx(ctx); ok {
-			if !cfg.IgnoreSuppressions {
-				ignoredMBIs, err = s.repository.GetSuppressedMBIs(ctx, s.sp.lookbackDays, upperBound)
-				if err != nil {
-					return nil, fmt.Errorf("failed to retreive suppressedMBIs %s", err.Error())
-				}
-			}
+	if len(conditions.MBIs) != 0 {
+		// Suppressed beneficiaries are kept so that they can be told apart from unattributed ones
+		benes, err := s.repository.GetCCLFBeneficiaries(ctx, cclfFileID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get beneficiaries %s", err.Error())
 		}
-
+		return filterBenesByMBIs(benes, conditions.MBIs, ignoredMBIs)
 	}
 
 	benes, err := s.repository.GetCCLFBeneficiaries(ctx, cclfFileID, ignoredMBIs)
@@ -509,6 +696,72 @@ func (s *service) getBenesByFileID(ctx context.Context, cclfFileID uint, conditi
 	return benes, nil
 }
 
+// getSuppressedMBIs returns the MBIs of the beneficiaries that must be excluded because they opted out of data sharing
+func (s *service) getSuppressedMBIs(ctx context.Context, conditions RequestConditions) ([]string, error) {
+	if s.sp.includeSuppressedBeneficiaries {
+		return nil, nil
+	}
+
+	upperBound := conditions.optOutDate
+	if conditions.optOutDate.IsZero() {
+		upperBound = time.Now()
+	}
+
+	cfg, ok := SetACOCfgFromCtx(ctx)
+	if !ok || cfg.IgnoreSuppressions {
+		return nil, nil
+	}
+
+	ignoredMBIs, err := s.repository.GetSuppressedMBIs(ctx, s.sp.lookbackDays, upperBound)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retreive suppressedMBIs %s", err.Error())
+	}
+	return ignoredMBIs, nil
+}
+
+// filterBenesByMBIs restricts the beneficiaries of the CCLF file to the requested MBIs, excluding the ignored MBIs.
+// Every requested MBI must be attributed to the ACO (i.e. belong to one of the beneficiaries), otherwise an
+// UnattributedMBIError is returned.
+func filterBenesByMBIs(benes []*models.CCLFBeneficiary, mbis []string, ignoredMBIs []string) ([]*models.CCLFBeneficiary, error) {
+	attributed := make(map[string]struct{}, len(benes))
+	for _, bene := range benes {
+		attributed[bene.MBI] = struct{}{}
+	}
+
+	requested := make(map[string]struct{}, len(mbis))
+	var unattributed int
+	for _, mbi := range mbis {
+		requested[mbi] = struct{}{}
+		if _, ok := attributed[mbi]; !ok {
+			unattributed++
+		}
+	}
+	if unattributed != 0 {
+		return nil, UnattributedMBIError{Count: unattributed}
+	}
+
+	ignored := make(map[string]struct{}, len(ignoredMBIs))
+	for _, mbi := range ignoredMBIs {
+		ignored[mbi] = struct{}{}
+	}
+
+	var filtered []*models.CCLFBeneficiary
+	for _, bene := range benes {
+		if _, ok := requested[bene.MBI]; !ok {
+			continue
+		}
+		if _, ok := ignored[bene.MBI]; !ok {
+			filtered = append(filtered, bene)
+		}
+	}
+	// Every requested MBI is attributed, so an empty result means they were all suppressed
+	if len(filtered) == 0 {
+		return nil, UnavailableMBIError{Count: len(mbis)}
+	}
+
+	return filtered, nil
+}
+
 // timeConstraints searches for any time bounds that we should apply on the associated ACO
 func (s *service) timeConstraints(ctx context.Context, cmsID string) (timeConstraint, error) {
 	var constraint timeConstraint
@@ -528,6 +781,21 @@ func (s *service) timeConstraints(ctx context.Context, cmsID string) (timeConstr
 	return constraint, nil
 }
 
+// getElements returns the _elements that apply to the resource type with any resource type prefix removed.
+// An element without a prefix applies to every resource type.
+func getElements(elements []string, resourceType string) []string {
+	var result []string
+	for _, element := range elements {
+		rt, name, found := strings.Cut(element, ".")
+		if !found {
+			result = append(result, element)
+		} else if rt == resourceType {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
 // setClaimsDate computes the claims window to apply on the args
 func (s *service) setClaimsDate(args *models.JobEnqueueArgs, conditions RequestConditions) {
 	// If the caller made a request for runout data
@@ -589,37 +857,12 @@ func isPriorityACO(acoID string) bool {
 }
 
 func getMaxBeneCount(requestType string) (int, error) {
-	const (
-		BCDA_FHIR_MAX_RECORDS_EOB_DEFAULT           = 50
-		BCDA_FHIR_MAX_RECORDS_PATIENT_DEFAULT       = 5000
-		BCDA_FHIR_MAX_RECORDS_COVERAGE_DEFAULT      = 4000
-		BCDA_FHIR_MAX_RECORDS_CLAIM_DEFAULT         = 4000
-		BCDA_FHIR_MAX_RECORDS_CLAIMRESPONSE_DEFAULT = 4000
-	)
-	var envVar string
-	var defaultVal int
-
-	switch requestType {
-	case "ExplanationOfBenefit":
-		envVar = "BCDA_FHIR_MAX_RECORDS_EOB"
-		defaultVal = BCDA_FHIR_MAX_RECORDS_EOB_DEFAULT
-	case "Patient":
-		envVar = "BCDA_FHIR_MAX_RECORDS_PATIENT"
-		defaultVal = BCDA_FHIR_MAX_RECORDS_PATIENT_DEFAULT
-	case "Coverage":
-		envVar = "BCDA_FHIR_MAX_RECORDS_COVERAGE"
-		defaultVal = BCDA_FHIR_MAX_RECORDS_COVERAGE_DEFAULT
-	case "Claim":
-		envVar = "BCDA_FHIR_MAX_RECORDS_CLAIM"
-		defaultVal = BCDA_FHIR_MAX_RECORDS_CLAIM_DEFAULT
-	case "ClaimResponse":
-		envVar = "BCDA_FHIR_MAX_RECORDS_CLAIM_RESPONSE"
-		defaultVal = BCDA_FHIR_MAX_RECORDS_CLAIMRESPONSE_DEFAULT
-	default:
+	resource, ok := resourcetypes.GetResource(requestType)
+	if !ok {
 		err := errors.New("invalid request type")
 		return -1, err
 	}
-	maxBeneficiaries := utils.GetEnvInt(envVar, defaultVal)
+	maxBeneficiaries := utils.GetEnvInt(resource.MaxBeneficiariesEnv, resource.MaxBeneficiaries)
 
 	return maxBeneficiaries, nil
 }
@@ -657,6 +900,56 @@ func (s *service) GetLatestCCLFFile(ctx context.Context, cmsID string, fileType
 	return cclfFile, nil
 }
 
+// GetAttributionDelta returns the latest CCLF8 file for the ACO, along with how the file changed the ACO's attributed
+// beneficiaries. ErrAttributionDeltaNotFound is returned when the file was imported before attribution deltas were recorded.
+func (s *service) GetAttributionDelta(ctx context.Context, cmsID string, fileType models.CCLFFileType) (*models.CCLFFile, *models.CCLFAttributionDelta, error) {
+	cclfFile, err := s.GetLatestCCLFFile(ctx, cmsID, fileType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	delta, err := s.repository.GetCCLFAttributionDelta(ctx, cclfFile.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get attribution delta for cmsID %s cclfFileID %d: %w", cmsID, cclfFile.ID, err)
+	}
+	if delta == nil {
+		return nil, nil, ErrAttributionDeltaNotFound
+	}
+
+	return cclfFile, delta, nil
+}
+
+// GetGroupMembers returns the latest CCLF8 file for the ACO along with the number of beneficiaries it attributes to the ACO
+// and up to count of those beneficiaries ordered by ID, starting at offset. No beneficiaries are loaded when count is zero.
+// Suppressed beneficiaries are excluded unless the ACO is configured to ignore suppressions.
+func (s *service) GetGroupMembers(ctx context.Context, cmsID string, fileType models.CCLFFileType, offset, count int) (*models.CCLFFile, []*models.CCLFBeneficiary, int, error) {
+	cclfFile, err := s.GetLatestCCLFFile(ctx, cmsID, fileType)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	ignoredMBIs, err := s.getSuppressedMBIs(ctx, RequestConditions{CMSID: cmsID, fileType: fileType})
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	total, err := s.repository.GetCCLFBeneficiaryCount(ctx, cclfFile.ID, ignoredMBIs)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to count beneficiaries %s", err.Error())
+	}
+
+	if count == 0 || offset >= total {
+		return cclfFile, nil, total, nil
+	}
+
+	benes, err := s.repository.GetCCLFBeneficiariesPage(ctx, cclfFile.ID, ignoredMBIs, offset, count)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to get beneficiaries %s", err.Error())
+	}
+
+	return cclfFile, benes, total, nil
+}
+
 type CCLFNotFoundError struct {
 	FileNumber int
 	CMSID      string
@@ -669,9 +962,36 @@ func (e CCLFNotFoundError) Error() string {
 		e.FileNumber, e.CMSID, e.FileType, e.CutoffTime.String())
 }
 
+// UnattributedMBIError indicates that the caller requested beneficiaries that are not attributed to their ACO.
+// It only records how many MBIs were not attributed so that they are not echoed in responses or logs.
+type UnattributedMBIError struct {
+	Count int
+}
+
+func (e UnattributedMBIError) Error() string {
+	return fmt.Sprintf("%d requested MBI(s) are not attributed to the ACO", e.Count)
+}
+
+// UnavailableMBIError indicates that none of the attributed beneficiaries requested by the caller can be exported,
+// e.g. because they have all opted out of data sharing.
+// Like UnattributedMBIError, it only records how many MBIs were requested.
+type UnavailableMBIError struct {
+	Count int
+}
+
+func (e UnavailableMBIError) Error() string {
+	return fmt.Sprintf("%d requested MBI(s) are unavailable for export", e.Count)
+}
+
 var (
 	ErrJobNotCancelled   = goerrors.New("job was not cancelled due to internal server error")
 	ErrJobNotCancellable = goerrors.New("job was not cancelled because it is not Pending or In Progress")
+
+	ErrJobNotRetryable       = goerrors.New("job was not retried because it is not Completed")
+	ErrNoFailedBeneficiaries = goerrors.New("job was not retried because it does not have any failed beneficiaries")
+	ErrRetryInProgress       = goerrors.New("job was not retried because a retry of the job is already Pending or In Progress")
+
+	ErrAttributionDeltaNotFound = goerrors.New("attribution delta was not recorded for the latest CCLF8 file")
 )
 
 type CtxACOCfgType string
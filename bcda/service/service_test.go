@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"net/url"
 	"regexp"
 	"slices"
 	"strconv"
@@ -108,6 +109,7 @@ func TestGetMaxBeneCount(t *testing.T) {
 		conf.UnsetEnv(t, "BCDA_FHIR_MAX_RECORDS_COVERAGE")
 		conf.UnsetEnv(t, "BCDA_FHIR_MAX_RECORDS_CLAIM")
 		conf.UnsetEnv(t, "BCDA_FHIR_MAX_RECORDS_CLAIM_RESPONSE")
+		conf.UnsetEnv(t, "BCDA_FHIR_MAX_RECORDS_OBSERVATION")
 	}()
 
 	getEnvVar := func(resourceType string) string {
@@ -122,6 +124,8 @@ func TestGetMaxBeneCount(t *testing.T) {
 			return "BCDA_FHIR_MAX_RECORDS_CLAIM"
 		case "ClaimResponse":
 			return "BCDA_FHIR_MAX_RECORDS_CLAIM_RESPONSE"
+		case "Observation":
+			return "BCDA_FHIR_MAX_RECORDS_OBSERVATION"
 		default:
 			return ""
 		}
@@ -150,6 +154,8 @@ func TestGetMaxBeneCount(t *testing.T) {
 		{"MaxClaim", "Claim", 20, setter},
 		{"defaultClaimResponse", "ClaimResponse", 4000, clearer},
 		{"MaxClaimResponse", "ClaimResponse", 25, setter},
+		{"DefaultObservation", "Observation", 4000, clearer},
+		{"MaxObservation", "Observation", 30, setter},
 	}
 
 	for _, tt := range tests {
@@ -167,6 +173,14 @@ func TestGetMaxBeneCount(t *testing.T) {
 	assert.EqualError(t, err, "invalid request type")
 }
 
+func TestGetElements(t *testing.T) {
+	elements := []string{"id", "ExplanationOfBenefit.total", "Patient.name"}
+	assert.Equal(t, []string{"id", "total"}, getElements(elements, "ExplanationOfBenefit"))
+	assert.Equal(t, []string{"id", "name"}, getElements(elements, "Patient"))
+	assert.Equal(t, []string{"id"}, getElements(elements, "Coverage"))
+	assert.Nil(t, getElements(nil, "Coverage"))
+}
+
 func TestGetJobAndKeys(t *testing.T) {
 	ipJob := models.Job{ID: 22, Status: models.JobStatusInProgress}
 	ipJobEmptyKey := models.Job{ID: 46, Status: models.JobStatusInProgress}
@@ -204,6 +218,43 @@ func TestGetJobAndKeys(t *testing.T) {
 	}
 }
 
+func TestGetJobProgress(t *testing.T) {
+	ctx := context.Background()
+	queJobID := func(id int64) *int64 { return &id }
+
+	jobID := uint(22)
+	jobKeys := []*models.JobKey{
+		{JobID: jobID, QueJobID: queJobID(1), ResourceType: "Patient", FileName: "a.ndjson", BeneficiaryCount: 10, ErrorCount: 1},
+		{JobID: jobID, QueJobID: queJobID(1), ResourceType: "Patient", FileName: "a-error.ndjson"},
+		{JobID: jobID, QueJobID: queJobID(2), ResourceType: "Patient", FileName: models.BlankFileName, BeneficiaryCount: 5},
+		{JobID: jobID, QueJobID: queJobID(3), ResourceType: "Coverage", FileName: "b.ndjson", BeneficiaryCount: 10},
+		{JobID: jobID, ResourceType: "Coverage", FileName: "c.ndjson", BeneficiaryCount: 3},
+	}
+	pending := map[string]int{"Patient": 1, "ExplanationOfBenefit": 4}
+
+	repository := &models.MockRepository{}
+	repository.On("GetJobKeys", testUtils.CtxMatcher, jobID).Return(jobKeys, nil)
+	repository.On("GetPendingQueueJobCounts", testUtils.CtxMatcher, jobID).Return(pending, nil)
+	serviceInstance := NewService(repository, &Config{}, "")
+
+	progress, err := serviceInstance.GetJobProgress(ctx, jobID)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.ResourceProgress{
+		{ResourceType: "Coverage", CompletedQueueJobs: 1, TotalQueueJobs: 1, BeneficiariesProcessed: 13},
+		{ResourceType: "ExplanationOfBenefit", CompletedQueueJobs: 0, TotalQueueJobs: 4},
+		{ResourceType: "Patient", CompletedQueueJobs: 2, TotalQueueJobs: 3, BeneficiariesProcessed: 15, Errors: 1},
+	}, progress)
+
+	repository = &models.MockRepository{}
+	repository.On("GetJobKeys", testUtils.CtxMatcher, jobID).Return(jobKeys, nil)
+	repository.On("GetPendingQueueJobCounts", testUtils.CtxMatcher, jobID).Return(nil, errors.New("river unavailable"))
+	serviceInstance = NewService(repository, &Config{}, "")
+
+	progress, err = serviceInstance.GetJobProgress(ctx, jobID)
+	assert.EqualError(t, err, "river unavailable")
+	assert.Nil(t, progress)
+}
+
 ///////////////////////////////////////////////////////////////////////////
 /////////////////////////// INTEGRATION TESTS /////////////////////////////
 ///////////////////////////////////////////////////////////////////////////
@@ -267,6 +318,7 @@ func (s *ServiceTestSuite) TestIncludeSuppressedBeneficiaries_Integration() {
 			repository.On("GetLatestCCLFFile", testUtils.CtxMatcher, mock.Anything, mock.Anything, mock.Anything, time.Time{}, mock.MatchedBy(timeIsSetMatcher), models.FileTypeDefault).Return(tt.cclfFileOld, nil)
 			if tt.cclfFileOld != nil {
 				repository.On("GetCCLFBeneficiaryMBIs", testUtils.CtxMatcher, tt.cclfFileOld.ID).Return([]string{"1", "2", "3"}, nil)
+				repository.On("GetCCLFAttributionDelta", testUtils.CtxMatcher, tt.cclfFileNew.ID).Return(nil, nil)
 			}
 
 			var suppressedMBIs []string
@@ -281,6 +333,87 @@ func (s *ServiceTestSuite) TestIncludeSuppressedBeneficiaries_Integration() {
 	}
 }
 
+func (s *ServiceTestSuite) TestGetBenesByFileIDWithMBIs() {
+	benes := []*models.CCLFBeneficiary{getCCLFBeneficiary(1, "MBI00000001"), getCCLFBeneficiary(2, "MBI00000002"),
+		getCCLFBeneficiary(3, "MBI00000003"), getCCLFBeneficiary(4, "MBI00000004")}
+	suppressedMBIs := []string{"MBI00000004"}
+
+	tests := []struct {
+		name            string
+		mbis            []string
+		expectedMBIs    []string
+		expUnattributed int
+		expUnavailable  int
+	}{
+		{"SubsetOfBeneficiaries", []string{"MBI00000001", "MBI00000003"}, []string{"MBI00000001", "MBI00000003"}, 0, 0},
+		// MBI00000004 is attributed but suppressed, so it is not exported
+		{"SuppressedBeneficiary", []string{"MBI00000002", "MBI00000004"}, []string{"MBI00000002"}, 0, 0},
+		{"AllSuppressedBeneficiaries", []string{"MBI00000004"}, nil, 0, 1},
+		{"UnattributedBeneficiaries", []string{"MBI00000001", "MBI00000005", "MBI00000006"}, nil, 2, 0},
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			repository := &models.MockRepository{}
+			// Suppressed beneficiaries are filtered out in memory, so they are not excluded by the query
+			repository.On("GetCCLFBeneficiaries", testUtils.CtxMatcher, uint(1), []string(nil)).Return(benes, nil)
+			repository.On("GetSuppressedMBIs", testUtils.CtxMatcher, mock.Anything, mock.Anything).Return(suppressedMBIs, nil)
+			serviceInstance := &service{repository: repository}
+			ctx := NewACOCfgCtx(context.Background(), &ACOConfig{})
+
+			result, err := serviceInstance.getBenesByFileID(ctx, 1, RequestConditions{MBIs: tt.mbis})
+			// Attribution is checked against the beneficiaries that were already retrieved
+			repository.AssertNotCalled(t, "GetCCLFBeneficiaryMBIs", testUtils.CtxMatcher, mock.Anything)
+			if tt.expUnattributed != 0 {
+				var unattributedErr UnattributedMBIError
+				assert.True(t, errors.As(err, &unattributedErr))
+				assert.Equal(t, tt.expUnattributed, unattributedErr.Count)
+				assert.NotContains(t, err.Error(), tt.mbis[1])
+				return
+			}
+			if tt.expUnavailable != 0 {
+				var unavailableErr UnavailableMBIError
+				assert.True(t, errors.As(err, &unavailableErr))
+				assert.Equal(t, tt.expUnavailable, unavailableErr.Count)
+				assert.NotContains(t, err.Error(), tt.mbis[0])
+				return
+			}
+
+			assert.NoError(t, err)
+			var mbis []string
+			for _, bene := range result {
+				mbis = append(mbis, bene.MBI)
+			}
+			assert.Equal(t, tt.expectedMBIs, mbis)
+		})
+	}
+}
+
+func (s *ServiceTestSuite) TestGetBeneficiaryJobs() {
+	benes := []*models.CCLFBeneficiary{getCCLFBeneficiary(1, "MBI00000001"), getCCLFBeneficiary(2, "MBI00000002")}
+	ctx := context.WithValue(context.Background(), middleware.CtxTransactionKey, uuid.New())
+
+	repository := &models.MockRepository{}
+	repository.On("GetACOByCMSID", testUtils.CtxMatcher, "A0000").Return(&models.ACO{}, nil)
+	repository.On("GetLatestCCLFFile", testUtils.CtxMatcher, "A0000", cclf8FileNum, constants.ImportComplete, time.Time{}, time.Time{}, models.FileTypeDefault).Return(getCCLFFile(1, false, false), nil)
+	repository.On("GetCCLFBeneficiaries", testUtils.CtxMatcher, uint(1), []string(nil)).Return(benes, nil)
+	serviceInstance := &service{repository: repository, sp: suppressionParameters{includeSuppressedBeneficiaries: true},
+		acoConfigs: []ACOConfig{{patternExp: regexp.MustCompile("A0000"), Data: []string{constants.Adjudicated}}}}
+
+	result, jobs, err := serviceInstance.GetBeneficiaryJobs(ctx, RequestConditions{CMSID: "A0000", Resources: []string{"Patient", "Coverage"}, MBIs: []string{"MBI00000002"}})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []*models.CCLFBeneficiary{benes[1]}, result)
+	// The job arguments are built from the beneficiaries that were already retrieved
+	assert.Len(s.T(), jobs, 2)
+	for _, job := range jobs {
+		assert.Equal(s.T(), []string{fmt.Sprint(benes[1].ID)}, job.BeneficiaryIDs)
+	}
+	repository.AssertNumberOfCalls(s.T(), "GetCCLFBeneficiaries", 1)
+
+	_, _, err = serviceInstance.GetBeneficiaryJobs(ctx, RequestConditions{CMSID: "A0000", MBIs: []string{"MBI00000003"}})
+	assert.True(s.T(), errors.As(err, &UnattributedMBIError{}))
+}
+
 func (s *ServiceTestSuite) TestGetNewAndExistingBeneficiaries_Integration() {
 	tests := []struct {
 		name string
@@ -394,6 +527,7 @@ func (s *ServiceTestSuite) TestGetNewAndExistingBeneficiaries_Integration() {
 
 			if tt.cclfFileOld != nil {
 				repository.On("GetCCLFBeneficiaryMBIs", testUtils.CtxMatcher, tt.cclfFileOld.ID).Return(tt.oldMBIs, nil)
+				repository.On("GetCCLFAttributionDelta", testUtils.CtxMatcher, tt.cclfFileNew.ID).Return(nil, nil)
 			}
 			suppressedMBI := "suppressedMBI"
 			if tt.cclfFileNew != nil {
@@ -796,11 +930,12 @@ func (s *ServiceTestSuite) TestGetQueJobs_Integration() {
 	for _, tt := range tests {
 		s.T().Run(tt.name, func(t *testing.T) {
 			conditions := RequestConditions{
-				CMSID:     tt.acoID,
-				ACOID:     uuid.NewUUID(),
-				Resources: tt.resourceTypes,
-				Since:     tt.expSince,
-				ReqType:   tt.reqType,
+				CMSID:      tt.acoID,
+				ACOID:      uuid.NewUUID(),
+				Resources:  tt.resourceTypes,
+				Since:      tt.expSince,
+				ReqType:    tt.reqType,
+				TypeFilter: map[string][]url.Values{"ExplanationOfBenefit": {{"type": {"inpatient"}}}},
 			}
 
 			repository := &models.MockRepository{}
@@ -815,6 +950,7 @@ func (s *ServiceTestSuite) TestGetQueJobs_Integration() {
 			repository.On("GetCCLFBeneficiaries", testUtils.CtxMatcher, mock.Anything, mock.Anything).Return(tt.expBenes, nil)
 			// use benes1 as the "old" benes. Allows us to verify the since parameter is populated as expected
 			repository.On("GetCCLFBeneficiaryMBIs", testUtils.CtxMatcher, mock.Anything).Return(benes1MBI, nil)
+			repository.On("GetCCLFAttributionDelta", testUtils.CtxMatcher, mock.Anything).Return(nil, nil)
 
 			cfg := &Config{
 				cutoffDuration:          time.Hour,
@@ -869,6 +1005,7 @@ func (s *ServiceTestSuite) TestGetQueJobs_Integration() {
 				}
 
 				assert.Equal(t, basePath, qj.BBBasePath)
+				assert.Equal(t, conditions.TypeFilter[qj.ResourceType], qj.TypeFilters)
 			}
 
 			for _, resourceType := range tt.resourceTypes {
@@ -964,6 +1101,7 @@ func (s *ServiceTestSuite) TestGetQueJobsErrorHandling_Integration() {
 		repository.On("GetLatestCCLFFile", testUtils.CtxMatcher, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(getCCLFFile(1, false, false), nil)
 		repository.On("GetCCLFBeneficiaries", testUtils.CtxMatcher, mock.Anything, mock.Anything).Return(nil, nil)
 		repository.On("GetCCLFBeneficiaryMBIs", testUtils.CtxMatcher, mock.Anything).Return([]string{"old"}, nil)
+		repository.On("GetCCLFAttributionDelta", testUtils.CtxMatcher, mock.Anything).Return(nil, nil)
 		serviceInstance := NewService(repository, cfg, basePath)
 		serviceInstance.(*service).acoConfigs = acoCfgs
 		_, err := serviceInstance.GetQueJobs(context.WithValue(ctx, middleware.CtxTransactionKey, uuid.New()), conditions)
@@ -1044,6 +1182,7 @@ func (s *ServiceTestSuite) TestGetQueJobsByDataType_Integration() {
 			repository.On("GetCCLFBeneficiaries", testUtils.CtxMatcher, mock.Anything, mock.Anything).Return(tt.expBenes, nil)
 			// use benes1 as the "old" benes. Allows us to verify the since parameter is populated as expected
 			repository.On("GetCCLFBeneficiaryMBIs", testUtils.CtxMatcher, mock.Anything).Return(benes1MBI, nil)
+			repository.On("GetCCLFAttributionDelta", testUtils.CtxMatcher, mock.Anything).Return(nil, nil)
 
 			cfg := &Config{
 				cutoffDuration:          time.Hour,
@@ -1165,6 +1304,64 @@ func (s *ServiceTestSuite) TestCancelJob_Integration() {
 	}
 }
 
+func (s *ServiceTestSuite) TestNewRetryJob() {
+	acoID := uuid.NewRandom()
+	transactionTime := time.Now().Add(-time.Hour)
+	completed := &models.Job{ID: 1, ACOID: acoID, RequestURL: "/api/v2/Group/all/$export", Status: models.JobStatusCompleted, TransactionTime: transactionTime}
+	failures := []*models.FailedQueueJob{
+		{JobID: 1, QueJobID: 10, Args: models.JobEnqueueArgs{ID: 1, ResourceType: "Patient", BeneficiaryIDs: []string{"1"}, TransactionID: "original"}},
+		{JobID: 1, QueJobID: 11, Args: models.JobEnqueueArgs{ID: 1, ResourceType: "ExplanationOfBenefit", BeneficiaryIDs: []string{"2", "3"}, Since: "gt2020-02-13T08:00:00.000-05:00", TransactionID: "original"}},
+	}
+	failures[1].Args.ClaimsWindow.LowerBound = transactionTime.AddDate(-1, 0, 0)
+	synthErr := fmt.Errorf("Synthetic error for testing.")
+
+	tests := []struct {
+		name        string
+		job         *models.Job
+		getJobErr   error
+		retries     []*models.Job
+		getRetryErr error
+		failures    []*models.FailedQueueJob
+		expErr      error
+	}{
+		{"Success", completed, nil, nil, nil, failures, nil},
+		{"JobNotFound", nil, synthErr, nil, nil, nil, synthErr},
+		{"JobNotCompleted", &models.Job{ID: 1, Status: models.JobStatusInProgress}, nil, nil, nil, failures, ErrJobNotRetryable},
+		{"RetryInProgress", completed, nil, []*models.Job{{ID: 2, Status: models.JobStatusPending, RetryOf: 1}}, nil, failures, ErrRetryInProgress},
+		{"GetRetryJobsError", completed, nil, nil, synthErr, failures, synthErr},
+		{"NoFailedBeneficiaries", completed, nil, nil, nil, nil, ErrNoFailedBeneficiaries},
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			repository := &models.MockRepository{}
+			repository.On("GetJobByID", testUtils.CtxMatcher, uint(1)).Return(tt.job, tt.getJobErr)
+			repository.On("GetRetryJobs", testUtils.CtxMatcher, uint(1), models.JobStatusPending, models.JobStatusInProgress).Return(tt.retries, tt.getRetryErr)
+			repository.On("GetFailedQueueJobs", testUtils.CtxMatcher, uint(1)).Return(tt.failures, nil)
+			svc := &service{repository: repository}
+
+			ctx := context.WithValue(context.Background(), middleware.CtxTransactionKey, "retry")
+			retryJob, queJobs, err := svc.NewRetryJob(ctx, 1)
+			if tt.expErr != nil {
+				assert.ErrorIs(t, err, tt.expErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, models.Job{ACOID: acoID, RequestURL: completed.RequestURL, Status: models.JobStatusPending,
+				TransactionTime: transactionTime, JobCount: len(failures), RetryOf: 1}, *retryJob)
+			assert.Len(t, queJobs, len(failures))
+
+			// The retry job keeps the original arguments of each failed queue job
+			for i, j := range queJobs {
+				expected := failures[i].Args
+				expected.TransactionID = "retry"
+				assert.Equal(t, expected, *j)
+			}
+			repository.AssertNotCalled(t, "CreateJob", mock.Anything, mock.Anything)
+		})
+	}
+}
+
 func (s *ServiceTestSuite) TestGetJobPriority_Integration() {
 	const (
 		defaultACOID  = "Some ACO"
@@ -1268,6 +1465,143 @@ func (s *ServiceTestSuite) TestGetLatestCCLFFileNotFound_Integration() {
 	assert.Equal(s.T(), time.Time{}, err.(CCLFNotFoundError).CutoffTime)
 }
 
+func (s *ServiceTestSuite) TestGetAttributionDelta_Integration() {
+	cclfFile := getCCLFFile(1, false, false)
+	delta := &models.CCLFAttributionDelta{ID: 2, FileID: 1, PreviousFileID: 3, AddedMBIs: []string{"MBI00000001"}, AddedCount: 1, RetainedCount: 5}
+
+	tests := []struct {
+		name        string
+		cclfFile    *models.CCLFFile
+		delta       *models.CCLFAttributionDelta
+		deltaErr    error
+		expectedErr error
+	}{
+		{"Success", cclfFile, delta, nil, nil},
+		{"NoCCLFFile", nil, nil, nil, CCLFNotFoundError{8, "A0000", models.FileTypeDefault, time.Time{}}},
+		{"NoAttributionDelta", cclfFile, nil, nil, ErrAttributionDeltaNotFound},
+		{"RepositoryError", cclfFile, nil, errors.New("database error"), errors.New("database error")},
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			repository := &models.MockRepository{}
+			repository.On("GetLatestCCLFFile", testUtils.CtxMatcher, "A0000", cclf8FileNum, constants.ImportComplete, time.Time{}, time.Time{}, models.FileTypeDefault).Return(tt.cclfFile, nil)
+			repository.On("GetCCLFAttributionDelta", testUtils.CtxMatcher, uint(1)).Return(tt.delta, tt.deltaErr)
+			serviceInstance := NewService(repository, &Config{}, "")
+
+			file, d, err := serviceInstance.GetAttributionDelta(context.Background(), "A0000", models.FileTypeDefault)
+			if tt.expectedErr != nil {
+				assert.ErrorContains(t, err, tt.expectedErr.Error())
+				assert.Nil(t, file)
+				assert.Nil(t, d)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.cclfFile, file)
+			assert.Equal(t, tt.delta, d)
+		})
+	}
+}
+
+func (s *ServiceTestSuite) TestGetGroupMembers_Integration() {
+	cclfFile := getCCLFFile(1, false, false)
+	benes := []*models.CCLFBeneficiary{getCCLFBeneficiary(1, "MBI00000001"), getCCLFBeneficiary(2, "MBI00000002")}
+	suppressedMBIs := []string{"MBI00000003"}
+
+	tests := []struct {
+		name               string
+		cclfFile           *models.CCLFFile
+		cfg                *ACOConfig
+		offset, count      int
+		expectedSuppressed []string
+		expectedBenes      []*models.CCLFBeneficiary
+		countErr           error
+		benesErr           error
+		expectedErr        error
+	}{
+		{"Success", cclfFile, &ACOConfig{}, 0, 2, suppressedMBIs, benes, nil, nil, nil},
+		{"IgnoreSuppressions", cclfFile, &ACOConfig{IgnoreSuppressions: true}, 0, 2, nil, benes, nil, nil, nil},
+		{"NoMembersRequested", cclfFile, &ACOConfig{}, 0, 0, suppressedMBIs, nil, nil, nil, nil},
+		{"OffsetPastLastMember", cclfFile, &ACOConfig{}, 2, 2, suppressedMBIs, nil, nil, nil, nil},
+		{"NoCCLFFile", nil, &ACOConfig{}, 0, 2, nil, nil, nil, nil, CCLFNotFoundError{8, "A0000", models.FileTypeRunout, time.Time{}}},
+		{"CountError", cclfFile, &ACOConfig{}, 0, 2, suppressedMBIs, nil, errors.New("database error"), nil, errors.New("database error")},
+		{"RepositoryError", cclfFile, &ACOConfig{}, 0, 2, suppressedMBIs, benes, nil, errors.New("database error"), errors.New("database error")},
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			repository := &models.MockRepository{}
+			repository.On("GetLatestCCLFFile", testUtils.CtxMatcher, "A0000", cclf8FileNum, constants.ImportComplete, time.Time{}, time.Time{}, models.FileTypeRunout).Return(tt.cclfFile, nil)
+			if tt.expectedSuppressed != nil {
+				repository.On("GetSuppressedMBIs", testUtils.CtxMatcher, 10, mock.Anything).Return(suppressedMBIs, nil)
+			}
+			if tt.cclfFile != nil {
+				repository.On("GetCCLFBeneficiaryCount", testUtils.CtxMatcher, uint(1), tt.expectedSuppressed).Return(len(benes), tt.countErr)
+			}
+			if tt.expectedBenes != nil {
+				repository.On("GetCCLFBeneficiariesPage", testUtils.CtxMatcher, uint(1), tt.expectedSuppressed, tt.offset, tt.count).Return(tt.expectedBenes, tt.benesErr)
+			}
+			serviceInstance := NewService(repository, &Config{SuppressionLookbackDays: 10}, "")
+
+			file, members, total, err := serviceInstance.GetGroupMembers(NewACOCfgCtx(context.Background(), tt.cfg), "A0000", models.FileTypeRunout, tt.offset, tt.count)
+			if tt.expectedErr != nil {
+				assert.ErrorContains(t, err, tt.expectedErr.Error())
+				assert.Nil(t, file)
+				assert.Nil(t, members)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.cclfFile, file)
+			assert.Equal(t, len(benes), total)
+			assert.Equal(t, tt.expectedBenes, members)
+			repository.AssertExpectations(t)
+			if tt.expectedBenes == nil {
+				repository.AssertNotCalled(t, "GetCCLFBeneficiariesPage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func (s *ServiceTestSuite) TestGetNewAndExistingBeneficiariesAttributionDelta() {
+	cclfFileNew, cclfFileOld := getCCLFFile(1, false, false), getCCLFFile(2, false, false)
+	benes := []*models.CCLFBeneficiary{getCCLFBeneficiary(1, "MBI00000001"), getCCLFBeneficiary(2, "MBI00000002"), getCCLFBeneficiary(3, "MBI00000003")}
+
+	tests := []struct {
+		name  string
+		delta *models.CCLFAttributionDelta
+	}{
+		// The delta was recorded against the old file, so the old file's MBIs are not retrieved
+		{"ConsecutiveFiles", &models.CCLFAttributionDelta{FileID: cclfFileNew.ID, PreviousFileID: cclfFileOld.ID, AddedMBIs: []string{"MBI00000003"}}},
+		// The delta was recorded against a different file, so the MBIs are compared
+		{"NonConsecutiveFiles", &models.CCLFAttributionDelta{FileID: cclfFileNew.ID, PreviousFileID: 5, AddedMBIs: []string{"MBI00000002", "MBI00000003"}}},
+		{"NoAttributionDelta", nil},
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			repository := &models.MockRepository{}
+			repository.On("GetLatestCCLFFile", testUtils.CtxMatcher, "A0000", cclf8FileNum, constants.ImportComplete, mock.Anything, time.Time{}, models.FileTypeDefault).Return(cclfFileNew, nil)
+			repository.On("GetLatestCCLFFile", testUtils.CtxMatcher, "A0000", cclf8FileNum, constants.ImportComplete, time.Time{}, mock.Anything, models.FileTypeDefault).Return(cclfFileOld, nil)
+			repository.On("GetCCLFAttributionDelta", testUtils.CtxMatcher, cclfFileNew.ID).Return(tt.delta, nil)
+			repository.On("GetCCLFBeneficiaryMBIs", testUtils.CtxMatcher, cclfFileOld.ID).Return([]string{"MBI00000001", "MBI00000002"}, nil)
+			repository.On("GetCCLFBeneficiaries", testUtils.CtxMatcher, cclfFileNew.ID, []string(nil)).Return(benes, nil)
+			serviceInstance := &service{repository: repository, sp: suppressionParameters{includeSuppressedBeneficiaries: true}}
+
+			newBenes, oldBenes, err := serviceInstance.getNewAndExistingBeneficiaries(context.Background(),
+				RequestConditions{CMSID: "A0000", Since: time.Now().Add(-1 * time.Hour), fileType: models.FileTypeDefault})
+			assert.NoError(t, err)
+			assert.Equal(t, []*models.CCLFBeneficiary{benes[2]}, newBenes)
+			assert.Equal(t, benes[0:2], oldBenes)
+
+			if tt.delta != nil && tt.delta.PreviousFileID == cclfFileOld.ID {
+				repository.AssertNotCalled(t, "GetCCLFBeneficiaryMBIs", testUtils.CtxMatcher, cclfFileOld.ID)
+			} else {
+				repository.AssertCalled(t, "GetCCLFBeneficiaryMBIs", testUtils.CtxMatcher, cclfFileOld.ID)
+			}
+		})
+	}
+}
+
 func (s *ServiceTestSuite) TestGetACOConfigForID_Integration() {
 	repository := &models.MockRepository{}
 
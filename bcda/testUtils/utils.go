@@ -59,11 +59,23 @@ func RandomHexID() string {
 	return fmt.Sprintf("%x", b)
 }
 
-// RandomMBI returns an 11 character string that represents an MBI
+// RandomMBI returns an 11 character string that conforms to the CMS MBI format
 func RandomMBI(t *testing.T) string {
-	b, err := someRandomBytes(6)
+	const (
+		nonZero      = "123456789"
+		numeric      = "0123456789"
+		alpha        = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+		alphaNumeric = alpha + numeric
+	)
+	format := []string{nonZero, alpha, alphaNumeric, numeric, alpha, alphaNumeric, numeric, alpha, alpha, numeric, numeric}
+
+	b, err := someRandomBytes(len(format))
 	assert.NoError(t, err)
-	return fmt.Sprintf("%x", b)[0:11]
+	mbi := make([]byte, len(format))
+	for i, chars := range format {
+		mbi[i] = chars[int(b[i])%len(chars)]
+	}
+	return string(mbi)
 }
 
 func someRandomBytes(n int) ([]byte, error) {
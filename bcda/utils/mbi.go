@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// mbiExp matches the CMS Medicare Beneficiary Identifier format: 11 characters where each position is restricted
+// to a digit (1-9 for the first position), a letter, or either. Issued MBIs exclude the letters S, L, O, I, B and Z,
+// but synthetic MBIs use them to avoid colliding with real beneficiaries, so they are not rejected here.
+var mbiExp = regexp.MustCompile(`^[1-9][A-Z][A-Z0-9][0-9][A-Z][A-Z0-9][0-9][A-Z]{2}[0-9]{2}$`)
+
+// ValidateMBI returns an error describing why the MBI does not conform to the CMS MBI format
+func ValidateMBI(mbi string) error {
+	switch {
+	case mbi == "":
+		return fmt.Errorf("missing MBI")
+	case len(mbi) != 11:
+		return fmt.Errorf("invalid MBI length %d", len(mbi))
+	case !mbiExp.MatchString(mbi):
+		return fmt.Errorf("invalid MBI format")
+	}
+	return nil
+}
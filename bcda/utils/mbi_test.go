@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateMBI(t *testing.T) {
+	tests := []struct {
+		name string
+		mbi  string
+		err  string
+	}{
+		{"Valid", "1EG4TE5MK73", ""},
+		{"ValidSynthetic", "1S00E00AA00", ""},
+		{"Missing", "", "missing MBI"},
+		{"TooShort", "1EG4TE5MK7", "invalid MBI length 10"},
+		{"TooLong", "1EG4TE5MK733", "invalid MBI length 12"},
+		{"LeadingZero", "0EG4TE5MK73", "invalid MBI format"},
+		{"Lowercase", "1eg4te5mk73", "invalid MBI format"},
+		{"LetterInNumericPosition", "1EGATE5MK73", "invalid MBI format"},
+		{"DigitInAlphaPosition", "1EG4TE5M173", "invalid MBI format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMBI(tt.mbi)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
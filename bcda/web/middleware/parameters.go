@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	responseutils "github.com/CMSgov/bcda-app/bcda/responseutils"
+	"github.com/CMSgov/bcda-app/log"
+)
+
+// maxParametersBodySize limits the size of the Parameters resource that callers may POST.
+const maxParametersBodySize = 1 << 20
+
+// parametersResource is the subset of the FHIR Parameters resource used by the bulk data kick-off request.
+type parametersResource struct {
+	ResourceType string `json:"resourceType"`
+	Parameter    []struct {
+		Name           string  `json:"name"`
+		ValueString    *string `json:"valueString"`
+		ValueInstant   *string `json:"valueInstant"`
+		ValueDateTime  *string `json:"valueDateTime"`
+		ValueCode      *string `json:"valueCode"`
+		ValueReference *struct {
+			Reference string `json:"reference"`
+		} `json:"valueReference"`
+	} `json:"parameter"`
+}
+
+// ParseParametersBody converts the FHIR Parameters resource supplied with a POST $export request
+// into the equivalent query parameters. This allows the request to flow through ValidateRequestURL
+// and the export handlers exactly like a GET request, and gives the job a canonical request URL
+// that can be used to detect duplicate requests.
+// Parameters may also be supplied in the query string, as long as they are not supplied in the body as well.
+func ParseParametersBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw, _ := getResponseWriterFromRequestPath(w, r)
+		if rw == nil {
+			return
+		}
+
+		badRequest := func(errMsg string) {
+			log.API.Warn(errMsg)
+			rw.Exception(r.Context(), w, http.StatusBadRequest, responseutils.RequestErr, errMsg)
+		}
+
+		queryParams, err := url.ParseQuery(r.URL.RawQuery)
+		if err != nil {
+			badRequest(fmt.Sprintf("Invalid query string: %s", err.Error()))
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || (mediaType != "application/fhir+json" && mediaType != "application/json") {
+			badRequest("Content-Type header must be application/fhir+json")
+			return
+		}
+
+		var body parametersResource
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxParametersBodySize)).Decode(&body); err != nil {
+			badRequest(fmt.Sprintf("Invalid request body: %s", err.Error()))
+			return
+		}
+
+		if body.ResourceType != "Parameters" {
+			badRequest("Invalid request body: resourceType must be Parameters")
+			return
+		}
+
+		// The parameters are kept in the order they were supplied, like the query string of a GET request,
+		// so that the request URLs of equivalent GET and POST requests match.
+		var names []string
+		values := make(map[string][]string)
+		for _, p := range body.Parameter {
+			if p.Name == "" {
+				badRequest("Invalid parameter: name is required")
+				return
+			}
+			if _, ok := queryParams[p.Name]; ok {
+				badRequest(fmt.Sprintf("Invalid parameter: %s cannot be supplied in both the query string and the request body", p.Name))
+				return
+			}
+
+			var value string
+			switch {
+			case p.ValueString != nil:
+				value = *p.ValueString
+			case p.ValueInstant != nil:
+				value = *p.ValueInstant
+			case p.ValueDateTime != nil:
+				value = *p.ValueDateTime
+			case p.ValueCode != nil:
+				value = *p.ValueCode
+			case p.ValueReference != nil:
+				value = p.ValueReference.Reference
+			default:
+				badRequest(fmt.Sprintf("Invalid parameter: %s must have a value", p.Name))
+				return
+			}
+			// Repeated parameters (e.g. one _type entry per resource type) are combined into the
+			// comma separated form used by GET requests. _typeFilter queries are already split on commas.
+			existing, ok := values[p.Name]
+			switch {
+			case !ok:
+				names = append(names, p.Name)
+				values[p.Name] = []string{value}
+			case p.Name != "_typeFilter":
+				existing[0] += "," + value
+			default:
+				values[p.Name] = append(existing, value)
+			}
+		}
+
+		query := make([]string, 0, len(names)+1)
+		if r.URL.RawQuery != "" {
+			query = append(query, r.URL.RawQuery)
+		}
+		for _, name := range names {
+			for _, value := range values[name] {
+				query = append(query, url.QueryEscape(name)+"="+url.QueryEscape(value))
+			}
+		}
+		r.URL.RawQuery = strings.Join(query, "&")
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CMSgov/bcda-app/log"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseParametersBody(t *testing.T) {
+	var ctx context.Context
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})
+
+	body := `{
+		"resourceType": "Parameters",
+		"parameter": [
+			{"name": "_type", "valueString": "Patient"},
+			{"name": "_type", "valueString": "ExplanationOfBenefit"},
+			{"name": "_since", "valueInstant": "2020-02-13T08:00:00.000-05:00"},
+			{"name": "_typeFilter", "valueString": "ExplanationOfBenefit?type=inpatient"},
+			{"name": "_outputFormat", "valueString": "application/fhir+ndjson"}
+		]
+	}`
+	req, err := http.NewRequest("POST", "/api/v2/Group/all/$export", strings.NewReader(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/fhir+json")
+	rr := httptest.NewRecorder()
+	ParseParametersBody(ValidateRequestURL(handler)).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rp, ok := GetRequestParamsFromCtx(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"Patient", "ExplanationOfBenefit"}, rp.ResourceTypes)
+	assert.Equal(t, "inpatient", rp.TypeFilter["ExplanationOfBenefit"][0].Get("type"))
+	assert.Equal(t, "v2", rp.Version)
+
+	// The request URL should be the same as the equivalent GET request so that duplicates can be detected.
+	// The parameters are kept in the order they were supplied.
+	assert.Equal(t, "/api/v2/Group/all/$export?_type=Patient%2CExplanationOfBenefit&_since=2020-02-13T08%3A00%3A00.000-05%3A00&_typeFilter=ExplanationOfBenefit%3Ftype%3Dinpatient&_outputFormat=application%2Ffhir%2Bndjson", rp.RequestURL)
+}
+
+func TestParseParametersBodyWithQueryString(t *testing.T) {
+	var ctx context.Context
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})
+
+	body := `{"resourceType": "Parameters", "parameter": [{"name": "_since", "valueInstant": "2020-02-13T08:00:00.000-05:00"}]}`
+	req, err := http.NewRequest("POST", "/api/v2/Patient/$export?_type=Coverage,Patient", strings.NewReader(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/fhir+json")
+	rr := httptest.NewRecorder()
+	ParseParametersBody(ValidateRequestURL(handler)).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// Parameters that are only supplied in the query string are kept ahead of those in the body
+	rp, ok := GetRequestParamsFromCtx(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"Coverage", "Patient"}, rp.ResourceTypes)
+	assert.Equal(t, "/api/v2/Patient/$export?_type=Coverage,Patient&_since=2020-02-13T08%3A00%3A00.000-05%3A00", rp.RequestURL)
+}
+
+func TestParseParametersBodyInvalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		contentType string
+		body        string
+		errMsg      string
+	}{
+		{"conflictingParameters", "/api/v1/Patient/$export?_type=Patient", "application/fhir+json", `{"resourceType": "Parameters", "parameter": [{"name": "_type", "valueString": "Coverage"}]}`,
+			"_type cannot be supplied in both the query string and the request body"},
+		{"invalidQueryString", "/api/v1/Patient/$export?_type=%zz", "application/fhir+json", `{"resourceType": "Parameters"}`, "Invalid query string"},
+		{"invalidContentType", "/api/v1/Patient/$export", "text/plain", `{"resourceType": "Parameters"}`, "Content-Type header must be application/fhir+json"},
+		{"invalidJSON", "/api/v1/Patient/$export", "application/fhir+json", `{"resourceType": `, "Invalid request body"},
+		{"invalidResourceType", "/api/v1/Patient/$export", "application/json", `{"resourceType": "Patient"}`, "resourceType must be Parameters"},
+		{"missingName", "/api/v2/Patient/$export", "application/fhir+json", `{"resourceType": "Parameters", "parameter": [{"valueString": "Patient"}]}`, "name is required"},
+		{"missingValue", "/api/v2/Patient/$export", "application/fhir+json", `{"resourceType": "Parameters", "parameter": [{"name": "_type"}]}`, "_type must have a value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", tt.url, strings.NewReader(tt.body))
+			assert.NoError(t, err)
+			req = req.WithContext(log.NewStructuredLoggerEntry(logrus.New(), context.Background()))
+			req.Header.Set("Content-Type", tt.contentType)
+			rr := httptest.NewRecorder()
+			ParseParametersBody(noop).ServeHTTP(rr, req)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.errMsg)
+		})
+	}
+}
@@ -43,6 +43,12 @@ func CheckConcurrentJobs(next http.Handler) http.Handler {
 			panic("RequestParameters should be set before calling this handler")
 		}
 
+		// Synchronous requests do not create a job, so they cannot duplicate one
+		if rp.Synchronous {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		rw, _ := getResponseWriterFromRequestPath(w, r)
 		if rw == nil {
 			return
@@ -87,6 +87,22 @@ func TestHasConcurrentJobs(t *testing.T) {
 	}
 }
 
+func TestSynchronousRequestSkipsConcurrentJobs(t *testing.T) {
+	// A pending job for all resources would otherwise reject the request
+	mockRepo := &models.MockRepository{}
+	mockRepo.On("GetJobs", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(
+		[]*models.Job{{RequestURL: constants.V1Path + constants.PatientExportPath, CreatedAt: time.Now()}},
+		nil,
+	)
+	repository = mockRepo
+
+	rp := RequestParameters{ResourceTypes: []string{"Patient"}, Version: "v1", Synchronous: true}
+	rr := httptest.NewRecorder()
+	CheckConcurrentJobs(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {})).ServeHTTP(rr, getRequest(rp))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockRepo.AssertNotCalled(t, "GetJobs", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestFailedToGetJobs(t *testing.T) {
 	mockRepo := &models.MockRepository{}
 	// ctx, acoID, inprogress, pending
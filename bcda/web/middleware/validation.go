@@ -4,24 +4,44 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/CMSgov/bcda-app/bcda/constants"
+	"github.com/CMSgov/bcda-app/bcda/resourcetypes"
 	responseutils "github.com/CMSgov/bcda-app/bcda/responseutils"
 	responseutilsv2 "github.com/CMSgov/bcda-app/bcda/responseutils/v2"
+	"github.com/CMSgov/bcda-app/bcda/utils"
 	"github.com/CMSgov/bcda-app/log"
 )
 
-var supportedOutputFormats = map[string]struct{}{
-	"ndjson":                  {},
-	"application/fhir+ndjson": {},
-	"application/ndjson":      {}}
+// supportedOutputFormats maps the accepted _outputFormat values to the output format used by the worker
+var supportedOutputFormats = map[string]string{
+	"ndjson":                         constants.NDJSONOutputFormat,
+	"application/fhir+ndjson":        constants.NDJSONOutputFormat,
+	"application/ndjson":             constants.NDJSONOutputFormat,
+	"parquet":                        constants.ParquetOutputFormat,
+	"application/parquet":            constants.ParquetOutputFormat,
+	"application/vnd.apache.parquet": constants.ParquetOutputFormat}
+
+// typeFilterExp matches the beginning of a _typeFilter query, e.g. ExplanationOfBenefit?
+var typeFilterExp = regexp.MustCompile(`^[A-Z][A-Za-z]+\?`)
+
+// elementExp matches a top level element, optionally prefixed with the resource type, e.g. id or Patient.id
+var elementExp = regexp.MustCompile(`^([A-Z][A-Za-z]+\.)?[a-z][A-Za-z0-9]*$`)
 
 type RequestParameters struct {
 	Since         time.Time
 	ResourceTypes []string
-	Version       string // e.g. v1, v2
+	TypeFilter    map[string][]url.Values // resource type => search parameters of each filter, combined with OR
+	Elements      []string                // e.g. id, ExplanationOfBenefit.patient
+	MBIs          []string                // beneficiaries requested via the patient parameter
+	OutputFormat  string                  // e.g. ndjson, parquet
+	Synchronous   bool                    // data is returned in the response instead of through a job
+	Version       string                  // e.g. v1, v2
 	RequestURL    string
 }
 
@@ -58,21 +78,14 @@ func ValidateRequestURL(next http.Handler) http.Handler {
 		//validate "_outputFormat" parameter
 		params, ok := r.URL.Query()["_outputFormat"]
 		if ok {
-			if _, found := supportedOutputFormats[params[0]]; !found {
+			outputFormat, found := supportedOutputFormats[params[0]]
+			if !found {
 				errMsg := fmt.Sprintf("_outputFormat parameter must be one of %v", getKeys(supportedOutputFormats))
 				log.API.Error(errMsg)
 				rw.Exception(r.Context(), w, http.StatusBadRequest, responseutils.FormatErr, errMsg)
 				return
 			}
-		}
-
-		// we do not support "_elements" parameter
-		_, ok = r.URL.Query()["_elements"]
-		if ok {
-			errMsg := "Invalid parameter: this server does not support the _elements parameter."
-			log.API.Warn(errMsg)
-			rw.Exception(r.Context(), w, http.StatusBadRequest, responseutils.RequestErr, errMsg)
-			return
+			rp.OutputFormat = outputFormat
 		}
 
 		// Check and see if the user has a duplicated the query parameter symbol (?)
@@ -122,12 +135,100 @@ func ValidateRequestURL(next http.Handler) http.Handler {
 			rp.ResourceTypes = resourceTypes
 		}
 
+		// validate optional "_elements" parameter
+		params, ok = r.URL.Query()["_elements"]
+		if ok {
+			elements, err := parseElements(params, rp.ResourceTypes, version)
+			if err != nil {
+				log.API.Warn(err.Error())
+				rw.Exception(r.Context(), w, http.StatusBadRequest, responseutils.RequestErr, err.Error())
+				return
+			}
+			rp.Elements = elements
+		}
+
+		// validate optional "_typeFilter" parameter
+		params, ok = r.URL.Query()["_typeFilter"]
+		if ok {
+			typeFilter, err := parseTypeFilter(params, rp.ResourceTypes, version)
+			if err != nil {
+				log.API.Warn(err.Error())
+				rw.Exception(r.Context(), w, http.StatusBadRequest, responseutils.RequestErr, err.Error())
+				return
+			}
+			rp.TypeFilter = typeFilter
+		}
+
+		// validate optional "patient" parameter
+		params, ok = r.URL.Query()["patient"]
+		if ok {
+			synchronous := isSynchronousRequest(r)
+			if !strings.Contains(r.URL.Path, "/Group/") && !synchronous {
+				errMsg := "Invalid parameter: patient is only supported for Group export and synchronous Patient export"
+				log.API.Warn(errMsg)
+				rw.Exception(r.Context(), w, http.StatusBadRequest, responseutils.RequestErr, errMsg)
+				return
+			}
+			mbis, err := parsePatients(params)
+			if err != nil {
+				log.API.Warn(err.Error())
+				rw.Exception(r.Context(), w, http.StatusBadRequest, responseutils.RequestErr, err.Error())
+				return
+			}
+			if synchronous {
+				if err := validateSynchronousRequest(mbis, rp.OutputFormat); err != nil {
+					log.API.Warn(err.Error())
+					rw.Exception(r.Context(), w, http.StatusBadRequest, responseutils.RequestErr, err.Error())
+					return
+				}
+			}
+			rp.MBIs = mbis
+			rp.Synchronous = synchronous
+		}
+
 		ctx := SetRequestParamsCtx(r.Context(), rp)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// parsePatients parses the MBIs supplied via the patient parameter. Each value may be a bare MBI
+// or a Patient reference (e.g. Patient/1SA0A00AA00). Repeated MBIs are ignored.
+func parsePatients(params []string) ([]string, error) {
+	var mbis []string
+	seen := make(map[string]struct{})
+	for _, patient := range strings.Split(strings.Join(params, ","), ",") {
+		mbi := strings.ToUpper(strings.TrimPrefix(strings.TrimSpace(patient), "Patient/"))
+		if err := utils.ValidateMBI(mbi); err != nil {
+			return nil, fmt.Errorf("Invalid parameter: patient value %s must be an MBI or a Patient reference (e.g. Patient/1SA0A00AA00)", patient)
+		}
+		if _, ok := seen[mbi]; ok {
+			continue
+		}
+		seen[mbi] = struct{}{}
+		mbis = append(mbis, mbi)
+	}
+	return mbis, nil
+}
+
+// isSynchronousRequest reports whether the request is for a synchronous Patient export, which
+// is requested by omitting the Prefer header.
+func isSynchronousRequest(r *http.Request) bool {
+	return r.Header.Get("Prefer") == "" && strings.HasSuffix(r.URL.Path, "/Patient/$export")
+}
+
+// validateSynchronousRequest ensures that a synchronous export is small enough to be served within the request
+func validateSynchronousRequest(mbis []string, outputFormat string) error {
+	maxPatients := utils.GetEnvInt("BCDA_SYNC_EXPORT_MAX_PATIENTS", 5)
+	if len(mbis) > maxPatients {
+		return fmt.Errorf("Invalid parameter: at most %d patients may be requested without Prefer: respond-async", maxPatients)
+	}
+	if outputFormat == constants.ParquetOutputFormat {
+		return fmt.Errorf("Invalid parameter: _outputFormat %s is only supported with Prefer: respond-async", outputFormat)
+	}
+	return nil
+}
+
 func ValidateRequestHeaders(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		h := r.Header
@@ -152,6 +253,12 @@ func ValidateRequestHeaders(next http.Handler) http.Handler {
 			return
 		}
 
+		// Synchronous requests are identified (and validated) by ValidateRequestURL
+		if rp, ok := GetRequestParamsFromCtx(r.Context()); ok && rp.Synchronous && preferHeader == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		if preferHeader == "" {
 			logger.Warn("Prefer header is required")
 			rw.Exception(r.Context(), w, http.StatusBadRequest, responseutils.FormatErr, "Prefer header is required")
@@ -166,7 +273,94 @@ func ValidateRequestHeaders(next http.Handler) http.Handler {
 	})
 }
 
-func getKeys(kv map[string]struct{}) []string {
+// parseElements returns the unique _elements requested. Elements qualified with a resource type must name one of the
+// requested resource types or, when _type is not supplied, a resource type that can be exported from the API version.
+func parseElements(params []string, resourceTypes []string, version string) ([]string, error) {
+	if len(resourceTypes) == 0 {
+		resourceTypes = resourcetypes.GetResourceNames(version)
+	}
+
+	var elements []string
+	for _, element := range strings.Split(strings.Join(params, ","), ",") {
+		if !elementExp.MatchString(element) {
+			return nil, fmt.Errorf("Invalid parameter: _elements value %s must be a top level element (e.g. id or Patient.id)", element)
+		}
+		if resourceType, _, found := strings.Cut(element, "."); found && !slices.Contains(resourceTypes, resourceType) {
+			return nil, fmt.Errorf("Invalid parameter: _elements value %s must be qualified with a requested resource type", element)
+		}
+		if slices.Contains(elements, element) {
+			continue
+		}
+		elements = append(elements, element)
+	}
+	return elements, nil
+}
+
+// parseTypeFilter converts the _typeFilter values into search parameters keyed by resource type.
+// Each value may contain multiple comma-separated queries, e.g.
+// ExplanationOfBenefit?type=inpatient,outpatient,Claim?service-date=ge2020-01-01
+// Commas that do not start a new query are treated as part of the previous query's value.
+// Multiple queries for the same resource type are combined with OR, so each one is kept.
+func parseTypeFilter(params []string, resourceTypes []string, version string) (map[string][]url.Values, error) {
+	var queries []string
+	for _, param := range params {
+		for _, part := range strings.Split(param, ",") {
+			if typeFilterExp.MatchString(part) || len(queries) == 0 {
+				queries = append(queries, part)
+			} else {
+				queries[len(queries)-1] += "," + part
+			}
+		}
+	}
+
+	typeFilter := make(map[string][]url.Values, len(queries))
+	for _, query := range queries {
+		resourceType, rawQuery, found := strings.Cut(query, "?")
+		if !found || resourceType == "" || rawQuery == "" {
+			return nil, fmt.Errorf("Invalid _typeFilter %s: must be in the format ResourceType?search-parameters", query)
+		}
+
+		if len(resourceTypes) > 0 && !slices.Contains(resourceTypes, resourceType) {
+			return nil, fmt.Errorf("Invalid _typeFilter %s: resource type %s is not in the requested _type", query, resourceType)
+		}
+		if len(resourceTypes) == 0 && !slices.Contains(resourcetypes.GetResourceNames(version), resourceType) {
+			return nil, fmt.Errorf("Invalid _typeFilter %s: resource type %s cannot be exported from %s", query, resourceType, version)
+		}
+
+		// The search parameters that callers may supply are registered with each resource type
+		resource, ok := resourcetypes.GetResource(resourceType)
+		if !ok || len(resource.TypeFilterParams) == 0 {
+			return nil, fmt.Errorf("Invalid _typeFilter %s: filtering is not supported for resource type %s", query, resourceType)
+		}
+
+		values, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid _typeFilter %s: %s", query, err.Error())
+		}
+
+		for key, vals := range values {
+			if !slices.Contains(resource.TypeFilterParams, key) {
+				return nil, fmt.Errorf("Invalid _typeFilter %s: search parameter %s is not supported for %s. Supported parameters %v",
+					query, key, resourceType, resource.TypeFilterParams)
+			}
+			for _, v := range vals {
+				if v == "" {
+					return nil, fmt.Errorf("Invalid _typeFilter %s: search parameter %s must have a value", query, key)
+				}
+			}
+		}
+
+		// Repeating an identical filter does not match any additional resources
+		if slices.ContainsFunc(typeFilter[resourceType], func(v url.Values) bool { return v.Encode() == values.Encode() }) {
+			continue
+		}
+		typeFilter[resourceType] = append(typeFilter[resourceType], values)
+	}
+
+	return typeFilter, nil
+}
+
+func getKeys[V any](kv map[string]V) []string {
 	keys := make([]string, 0, len(kv))
 	for k := range kv {
 		keys = append(keys, k)
@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
 	"github.com/CMSgov/bcda-app/bcda/constants"
+	"github.com/CMSgov/bcda-app/conf"
 	"github.com/CMSgov/bcda-app/log"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -41,6 +43,152 @@ func TestValidRequestURL(t *testing.T) {
 	// assert.True(t, now.Equal(rp.Since), "Since parameter does not match")
 	assert.Equal(t, rp.ResourceTypes, []string{"Patient"})
 	assert.Equal(t, rp.Version, "v1")
+	assert.Equal(t, constants.NDJSONOutputFormat, rp.OutputFormat)
+}
+
+func TestValidRequestURLParquet(t *testing.T) {
+	var ctx context.Context
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})
+
+	req, err := http.NewRequest("GET", "/api/v2/Patient/$export?_outputFormat="+url.QueryEscape("application/vnd.apache.parquet"), nil)
+	assert.NoError(t, err)
+	rr := httptest.NewRecorder()
+	ValidateRequestURL(handler).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rp, ok := GetRequestParamsFromCtx(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, constants.ParquetOutputFormat, rp.OutputFormat)
+}
+
+func TestValidRequestURLElements(t *testing.T) {
+	var ctx context.Context
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})
+
+	req, err := http.NewRequest("GET", "/api/v2/Patient/$export?_elements=id,patient,ExplanationOfBenefit.total,id", nil)
+	assert.NoError(t, err)
+	rr := httptest.NewRecorder()
+	ValidateRequestURL(handler).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rp, ok := GetRequestParamsFromCtx(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"id", "patient", "ExplanationOfBenefit.total"}, rp.Elements)
+}
+
+func TestValidRequestURLPatient(t *testing.T) {
+	var ctx context.Context
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})
+
+	req, err := http.NewRequest("GET", "/api/v2/Group/all/$export?patient=Patient/1SA0A00AA01,1sa0a00aa02&patient=1SA0A00AA01", nil)
+	assert.NoError(t, err)
+	rr := httptest.NewRecorder()
+	ValidateRequestURL(handler).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rp, ok := GetRequestParamsFromCtx(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"1SA0A00AA01", "1SA0A00AA02"}, rp.MBIs)
+	assert.False(t, rp.Synchronous)
+}
+
+func TestValidRequestURLSynchronous(t *testing.T) {
+	var ctx context.Context
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})
+
+	req, err := http.NewRequest("GET", "/api/v2/Patient/$export?patient=1SA0A00AA01,1SA0A00AA02", nil)
+	assert.NoError(t, err)
+	req = req.WithContext(log.NewStructuredLoggerEntry(logrus.New(), context.Background()))
+	req.Header.Set("Accept", "application/fhir+json")
+	rr := httptest.NewRecorder()
+	ValidateRequestURL(ValidateRequestHeaders(handler)).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rp, ok := GetRequestParamsFromCtx(ctx)
+	assert.True(t, ok)
+	assert.True(t, rp.Synchronous)
+	assert.Equal(t, []string{"1SA0A00AA01", "1SA0A00AA02"}, rp.MBIs)
+}
+
+func TestInvalidRequestURLSynchronous(t *testing.T) {
+	conf.SetEnv(t, "BCDA_SYNC_EXPORT_MAX_PATIENTS", "2")
+
+	tests := []struct {
+		name   string
+		url    string
+		prefer string
+		errMsg string
+	}{
+		{"tooManyPatients", "/api/v2/Patient/$export?patient=1SA0A00AA01,1SA0A00AA02,1SA0A00AA03", "", "at most 2 patients may be requested without Prefer: respond-async"},
+		{"parquet", "/api/v2/Patient/$export?patient=1SA0A00AA01&_outputFormat=parquet", "", "_outputFormat parquet is only supported with Prefer: respond-async"},
+		{"asyncPatient", "/api/v2/Patient/$export?patient=1SA0A00AA01", constants.TestRespondAsync, "patient is only supported for Group export and synchronous Patient export"},
+		{"noPatient", "/api/v2/Patient/$export", "", "Prefer header is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tt.url, nil)
+			assert.NoError(t, err)
+			req = req.WithContext(log.NewStructuredLoggerEntry(logrus.New(), context.Background()))
+			req.Header.Set("Accept", "application/fhir+json")
+			req.Header.Set("Prefer", tt.prefer)
+
+			rr := httptest.NewRecorder()
+			ValidateRequestURL(ValidateRequestHeaders(noop)).ServeHTTP(rr, req)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.errMsg)
+		})
+	}
+}
+
+func TestValidRequestURLTypeFilter(t *testing.T) {
+	var ctx context.Context
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})
+
+	tests := []struct {
+		name        string
+		query       string
+		expFilter   map[string][]url.Values
+		expResource []string
+	}{
+		{"SingleFilter", "_type=ExplanationOfBenefit&_typeFilter=" + url.QueryEscape("ExplanationOfBenefit?type=inpatient"),
+			map[string][]url.Values{"ExplanationOfBenefit": {{"type": {"inpatient"}}}}, []string{"ExplanationOfBenefit"}},
+		{"MultipleValues", "_typeFilter=" + url.QueryEscape("ExplanationOfBenefit?type=inpatient,outpatient&service-date=ge2020-01-01"),
+			map[string][]url.Values{"ExplanationOfBenefit": {{"type": {"inpatient,outpatient"}, "service-date": {"ge2020-01-01"}}}}, nil},
+		{"MultipleResources", "_typeFilter=" + url.QueryEscape("ExplanationOfBenefit?type=carrier,Claim?service-date=ge2021-01-01"),
+			map[string][]url.Values{"ExplanationOfBenefit": {{"type": {"carrier"}}}, "Claim": {{"service-date": {"ge2021-01-01"}}}}, nil},
+		{"RepeatedParameter", "_typeFilter=" + url.QueryEscape("ExplanationOfBenefit?type=carrier") + "&_typeFilter=" + url.QueryEscape("Claim?service-date=ge2021-01-01"),
+			map[string][]url.Values{"ExplanationOfBenefit": {{"type": {"carrier"}}}, "Claim": {{"service-date": {"ge2021-01-01"}}}}, nil},
+		{"RepeatedResourceType", "_typeFilter=" + url.QueryEscape("ExplanationOfBenefit?type=carrier,ExplanationOfBenefit?type=pde"),
+			map[string][]url.Values{"ExplanationOfBenefit": {{"type": {"carrier"}}, {"type": {"pde"}}}}, nil},
+		{"RepeatedIdenticalFilter", "_typeFilter=" + url.QueryEscape("ExplanationOfBenefit?type=carrier") + "&_typeFilter=" + url.QueryEscape("ExplanationOfBenefit?type=carrier"),
+			map[string][]url.Values{"ExplanationOfBenefit": {{"type": {"carrier"}}}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/api/v2/Patient/$export?"+tt.query, nil)
+			assert.NoError(t, err)
+			rr := httptest.NewRecorder()
+			ValidateRequestURL(handler).ServeHTTP(rr, req)
+			assert.Equal(t, http.StatusOK, rr.Code)
+
+			rp, ok := GetRequestParamsFromCtx(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, tt.expFilter, rp.TypeFilter)
+			assert.Equal(t, tt.expResource, rp.ResourceTypes)
+		})
+	}
 }
 
 func TestInvalidRequestURL(t *testing.T) {
@@ -52,12 +200,24 @@ func TestInvalidRequestURL(t *testing.T) {
 		errMsg string
 	}{
 		{"invalidOutputFormat", fmt.Sprintf("%s_outputFormat=invalid", base), "_outputFormat parameter must be one of"},
-		{"elementsNotSupported", fmt.Sprintf("%s_elements=invalid", base), "does not support the _elements parameter"},
+		{"invalidElement", fmt.Sprintf("%s_elements=Patient.name.given", base), "_elements value Patient.name.given must be a top level element"},
+		{"emptyElement", fmt.Sprintf("%s_elements=id,,patient", base), "_elements value  must be a top level element"},
+		{"elementTypeNotRequested", fmt.Sprintf("%s_type=Patient&_elements=ExplanationOfBenefit.total", base), "_elements value ExplanationOfBenefit.total must be qualified with a requested resource type"},
+		{"elementTypeNotInVersion", fmt.Sprintf("%s_elements=Claim.status", base), "_elements value Claim.status must be qualified with a requested resource type"},
 		{"contains?", fmt.Sprintf("%s?_type=Patient", base), "query parameters cannot start with ?"},
 		{"invalidSince", fmt.Sprintf("%s_since=05-25-1977", base), "Date must be in FHIR Instant format"},
 		{"futureSince", fmt.Sprintf("%s_since=%s", base, time.Now().Add(24*time.Hour).Format(time.RFC3339Nano)),
 			"Date must be a date that has already passed"},
 		{"repeatedType", fmt.Sprintf("%s_type=Patient,Patient", base), "Repeated resource type Patient"},
+		{"typeFilterNoQuery", fmt.Sprintf("%s_typeFilter=ExplanationOfBenefit", base), "must be in the format ResourceType?search-parameters"},
+		{"typeFilterNotRequestedType", fmt.Sprintf("%s_type=Patient&_typeFilter=%s", base, url.QueryEscape("ExplanationOfBenefit?type=carrier")), "is not in the requested _type"},
+		{"typeFilterUnsupportedType", fmt.Sprintf("%s_typeFilter=%s", base, url.QueryEscape("Patient?gender=female")), "filtering is not supported for resource type Patient"},
+		{"typeFilterUnsupportedParam", fmt.Sprintf("%s_typeFilter=%s", base, url.QueryEscape("ExplanationOfBenefit?patient=123")), "search parameter patient is not supported"},
+		{"typeFilterEmptyValue", fmt.Sprintf("%s_typeFilter=%s", base, url.QueryEscape("ExplanationOfBenefit?type=")), "search parameter type must have a value"},
+		{"typeFilterTypeNotInVersion", fmt.Sprintf("%s_typeFilter=%s", base, url.QueryEscape("Claim?service-date=ge2021-01-01")), "resource type Claim cannot be exported from v1"},
+		{"patientNotGroup", fmt.Sprintf("%spatient=1SA0A00AA01", base), "patient is only supported for Group export"},
+		{"invalidPatient", "/api/v1/Group/all/$export?patient=Patient/123", "patient value Patient/123 must be an MBI or a Patient reference"},
+		{"invalidPatientFormat", "/api/v1/Group/all/$export?patient=MBI00000001", "patient value MBI00000001 must be an MBI or a Patient reference"},
 		{"noVersion", "/api/Patient$export", "cannot retrieve version"},
 	}
 
@@ -69,6 +229,7 @@ func TestInvalidRequestURL(t *testing.T) {
 			assert.NoError(t, err)
 
 			req = req.WithContext(ctx)
+			req.Header.Set("Prefer", constants.TestRespondAsync)
 
 			rr := httptest.NewRecorder()
 			ValidateRequestURL(noop).ServeHTTP(rr, req)
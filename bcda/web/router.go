@@ -55,17 +55,23 @@ func NewAPIRouter() http.Handler {
 		// Apply rate limiting on test +  production only
 		requestValidators = append(requestValidators, middleware.CheckConcurrentJobs)
 	}
+	// POST $export requests supply their parameters via a FHIR Parameters resource
+	postRequestValidators := append([]func(http.Handler) http.Handler{middleware.ParseParametersBody}, requestValidators...)
 
 	r.Route("/api/v1", func(r chi.Router) {
 		r.With(append(commonAuth, requestValidators...)...).Get(m.WrapHandler("/Patient/$export", v1.BulkPatientRequest))
+		r.With(append(commonAuth, postRequestValidators...)...).Post(m.WrapHandler("/Patient/$export", v1.BulkPatientRequestPost))
 		if conf.GetEnv("ENABLE_ALR_ENDPOINTS") == "true" {
 			r.With(append(commonAuth, requestValidators...)...).Get(m.WrapHandler("/alr/$export", v1.ALRRequest))
 		}
 		r.With(append(commonAuth, requestValidators...)...).Get(m.WrapHandler("/Group/{groupId}/$export", v1.BulkGroupRequest))
+		r.With(append(commonAuth, postRequestValidators...)...).Post(m.WrapHandler("/Group/{groupId}/$export", v1.BulkGroupRequestPost))
 		r.With(append(commonAuth, auth.RequireTokenJobMatch)...).Get(m.WrapHandler(constants.JOBIDPath, v1.JobStatus))
 		r.With(append(commonAuth, nonExportRequestValidators...)...).Get(m.WrapHandler("/jobs", v1.JobsStatus))
 		r.With(append(commonAuth, auth.RequireTokenJobMatch)...).Delete(m.WrapHandler(constants.JOBIDPath, v1.DeleteJob))
+		r.With(append(commonAuth, auth.RequireTokenJobMatch)...).Post(m.WrapHandler(constants.JOBIDRetryPath, v1.RetryJob))
 		r.With(commonAuth...).Get(m.WrapHandler("/attribution_status", v1.AttributionStatus))
+		r.With(commonAuth...).Get(m.WrapHandler("/attribution_delta", v1.AttributionDelta))
 		r.Get(m.WrapHandler("/metadata", v1.Metadata))
 	})
 
@@ -73,20 +79,26 @@ func NewAPIRouter() http.Handler {
 		FileServer(r, "/api/v2/swagger", http.Dir("./swaggerui/v2"))
 		r.Route("/api/v2", func(r chi.Router) {
 			r.With(append(commonAuth, requestValidators...)...).Get(m.WrapHandler("/Patient/$export", v2.BulkPatientRequest))
+			r.With(append(commonAuth, postRequestValidators...)...).Post(m.WrapHandler("/Patient/$export", v2.BulkPatientRequestPost))
 			if conf.GetEnv("ENABLE_ALR_ENDPOINTS") == "true" {
 				r.With(append(commonAuth, requestValidators...)...).Get(m.WrapHandler("/alr/$export", v2.ALRRequest))
 			}
 			r.With(append(commonAuth, requestValidators...)...).Get(m.WrapHandler("/Group/{groupId}/$export", v2.BulkGroupRequest))
+			r.With(append(commonAuth, postRequestValidators...)...).Post(m.WrapHandler("/Group/{groupId}/$export", v2.BulkGroupRequestPost))
+			r.With(commonAuth...).Get(m.WrapHandler("/Group/{groupId}", v2.Group))
 			r.With(append(commonAuth, auth.RequireTokenJobMatch)...).Get(m.WrapHandler(constants.JOBIDPath, v2.JobStatus))
 			r.With(append(commonAuth, nonExportRequestValidators...)...).Get(m.WrapHandler("/jobs", v2.JobsStatus))
 			r.With(append(commonAuth, auth.RequireTokenJobMatch)...).Delete(m.WrapHandler(constants.JOBIDPath, v2.DeleteJob))
+			r.With(append(commonAuth, auth.RequireTokenJobMatch)...).Post(m.WrapHandler(constants.JOBIDRetryPath, v2.RetryJob))
 			r.With(commonAuth...).Get(m.WrapHandler("/attribution_status", v2.AttributionStatus))
+			r.With(commonAuth...).Get(m.WrapHandler("/attribution_delta", v2.AttributionDelta))
 			r.Get(m.WrapHandler("/metadata", v2.Metadata))
 		})
 	}
 
 	r.Get(m.WrapHandler("/_version", v1.GetVersion))
 	r.Get(m.WrapHandler("/_health", v1.HealthCheck))
+	r.Get(m.WrapHandler("/_health/deep", v1.DeepHealthCheck))
 	r.Get(m.WrapHandler("/_auth", v1.GetAuthInfo))
 	return r
 }
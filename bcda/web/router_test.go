@@ -63,6 +63,13 @@ func (s *RouterTestSuite) deleteAPIRoute(route string) *http.Response {
 	return rr.Result()
 }
 
+func (s *RouterTestSuite) postAPIRoute(route string) *http.Response {
+	req := httptest.NewRequest("POST", route, nil)
+	rr := httptest.NewRecorder()
+	s.apiRouter.ServeHTTP(rr, req)
+	return rr.Result()
+}
+
 func (s *RouterTestSuite) getDataRoute(route string) *http.Response {
 	req := httptest.NewRequest("GET", route, nil)
 	rr := httptest.NewRecorder()
@@ -245,6 +252,10 @@ func (s *RouterTestSuite) TestV2EndpointsEnabled() {
 	assert.Equal(s.T(), http.StatusUnauthorized, res.StatusCode)
 	res = s.getAPIRoute("/api/v2/attribution_status")
 	assert.Equal(s.T(), http.StatusUnauthorized, res.StatusCode)
+	res = s.getAPIRoute("/api/v2/attribution_delta")
+	assert.Equal(s.T(), http.StatusUnauthorized, res.StatusCode)
+	res = s.getAPIRoute("/api/v2/Group/all")
+	assert.Equal(s.T(), http.StatusUnauthorized, res.StatusCode)
 	res = s.getAPIRoute("/api/v2/metadata")
 	assert.Equal(s.T(), http.StatusOK, res.StatusCode)
 }
@@ -264,11 +275,23 @@ func (s *RouterTestSuite) TestDeleteJobRoute() {
 	assert.Equal(s.T(), http.StatusUnauthorized, res.StatusCode)
 }
 
+func (s *RouterTestSuite) TestRetryJobRoute() {
+	res := s.postAPIRoute(constants.V1Path + constants.JobsFilePath + "/$retry")
+	assert.Equal(s.T(), http.StatusUnauthorized, res.StatusCode)
+	res = s.postAPIRoute(constants.V2Path + constants.JobsFilePath + "/$retry")
+	assert.Equal(s.T(), http.StatusUnauthorized, res.StatusCode)
+}
+
 func (s *RouterTestSuite) TestAttributionStatus() {
 	res := s.getAPIRoute("/api/v1/attribution_status")
 	assert.Equal(s.T(), http.StatusUnauthorized, res.StatusCode)
 }
 
+func (s *RouterTestSuite) TestAttributionDelta() {
+	res := s.getAPIRoute("/api/v1/attribution_delta")
+	assert.Equal(s.T(), http.StatusUnauthorized, res.StatusCode)
+}
+
 func (s *RouterTestSuite) TestHTTPServerRedirect() {
 	router := NewHTTPRouter()
 
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/CMSgov/bcda-app/bcda/client"
+	"github.com/CMSgov/bcda-app/bcda/database"
 	"github.com/CMSgov/bcda-app/bcda/health"
 	"github.com/CMSgov/bcda-app/bcda/utils"
 	"github.com/CMSgov/bcda-app/bcdaworker/queueing"
@@ -22,6 +23,7 @@ import (
 func init() {
 	createWorkerDirs()
 	client.SetLogger(log.BBWorker)
+	client.ConfigureCircuitBreakerStore(database.Connection)
 }
 
 func createWorkerDirs() {
@@ -136,10 +138,10 @@ func logHealth() {
 		logFields["db"] = "error"
 	}
 
-	if health.IsBlueButtonOK() {
+	if result, ok := health.IsBlueButtonOK(); ok {
 		logFields["bb"] = "ok"
 	} else {
-		logFields["bb"] = "error"
+		logFields["bb"] = result
 	}
 
 	entry.WithFields(logFields).Info()
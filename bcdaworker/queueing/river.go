@@ -3,6 +3,7 @@ package queueing
 import (
 	"context"
 	"database/sql"
+	goerrors "errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	bcdaaws "github.com/CMSgov/bcda-app/bcda/aws"
+	"github.com/CMSgov/bcda-app/bcda/client"
 	"github.com/CMSgov/bcda-app/bcda/constants"
 	"github.com/CMSgov/bcda-app/bcda/database"
 	"github.com/CMSgov/bcda-app/bcda/metrics"
@@ -191,6 +193,12 @@ func (w *JobWorker) Work(ctx context.Context, rjob *river.Job[models.JobEnqueueA
 	go checkIfCancelled(ctx, repo, cancel, jobID, 15)
 
 	if err := workerInstance.ProcessJob(ctx, rjob.ID, *exportJob, rjob.Args); err != nil {
+		// Snoozing does not count as an attempt, so an outage does not exhaust the job's retries
+		if goerrors.Is(err, client.ErrCircuitOpen) {
+			retryAfter := max(client.BlueButtonCircuitBreaker().RetryAfter(), time.Second)
+			logger.Warnf("Blue Button circuit breaker is open, snoozing job for %s", retryAfter)
+			return river.JobSnooze(retryAfter)
+		}
 		err := errors.Wrap(err, "failed to process job")
 		logger.Error(err)
 		return err
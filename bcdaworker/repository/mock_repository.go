@@ -8,6 +8,8 @@ import (
 	models "github.com/CMSgov/bcda-app/bcda/models"
 	mock "github.com/stretchr/testify/mock"
 
+	time "time"
+
 	uuid "github.com/pborman/uuid"
 )
 
@@ -82,6 +84,34 @@ func (_m *MockRepository) GetACOByUUID(ctx context.Context, _a1 uuid.UUID) (*mod
 	return r0, r1
 }
 
+// GetBlueButtonID provides a mock function with given fields: ctx, mbi, updatedAfter
+func (_m *MockRepository) GetBlueButtonID(ctx context.Context, mbi string, updatedAfter time.Time) (string, error) {
+	ret := _m.Called(ctx, mbi, updatedAfter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBlueButtonID")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (string, error)); ok {
+		return rf(ctx, mbi, updatedAfter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) string); ok {
+		r0 = rf(ctx, mbi, updatedAfter)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, mbi, updatedAfter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetCCLFBeneficiaryByID provides a mock function with given fields: ctx, id
 func (_m *MockRepository) GetCCLFBeneficiaryByID(ctx context.Context, id uint) (*models.CCLFBeneficiary, error) {
 	ret := _m.Called(ctx, id)
@@ -200,6 +230,36 @@ func (_m *MockRepository) GetJobKeyCount(ctx context.Context, jobID uint) (int,
 	return r0, r1
 }
 
+// GetPreviousMBIs provides a mock function with given fields: ctx, cmsID, mbi
+func (_m *MockRepository) GetPreviousMBIs(ctx context.Context, cmsID string, mbi string) ([]string, error) {
+	ret := _m.Called(ctx, cmsID, mbi)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPreviousMBIs")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]string, error)); ok {
+		return rf(ctx, cmsID, mbi)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []string); ok {
+		r0 = rf(ctx, cmsID, mbi)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, cmsID, mbi)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUniqueJobKeyCount provides a mock function with given fields: ctx, jobID
 func (_m *MockRepository) GetUniqueJobKeyCount(ctx context.Context, jobID uint) (int, error) {
 	ret := _m.Called(ctx, jobID)
@@ -228,6 +288,42 @@ func (_m *MockRepository) GetUniqueJobKeyCount(ctx context.Context, jobID uint)
 	return r0, r1
 }
 
+// SaveBlueButtonID provides a mock function with given fields: ctx, mbi, blueButtonID
+func (_m *MockRepository) SaveBlueButtonID(ctx context.Context, mbi string, blueButtonID string) error {
+	ret := _m.Called(ctx, mbi, blueButtonID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveBlueButtonID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, mbi, blueButtonID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveFailedQueueJob provides a mock function with given fields: ctx, failed
+func (_m *MockRepository) SaveFailedQueueJob(ctx context.Context, failed models.FailedQueueJob) error {
+	ret := _m.Called(ctx, failed)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveFailedQueueJob")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.FailedQueueJob) error); ok {
+		r0 = rf(ctx, failed)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateJobStatus provides a mock function with given fields: ctx, jobID, new
 func (_m *MockRepository) UpdateJobStatus(ctx context.Context, jobID uint, new models.JobStatus) error {
 	ret := _m.Called(ctx, jobID, new)
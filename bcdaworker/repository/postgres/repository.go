@@ -3,9 +3,13 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/CMSgov/bcda-app/bcda/constants"
 	"github.com/CMSgov/bcda-app/bcda/models"
 	"github.com/CMSgov/bcda-app/bcdaworker/repository"
 	"github.com/huandu/go-sqlbuilder"
@@ -83,6 +87,64 @@ func (r *Repository) GetCCLFBeneficiaryByID(ctx context.Context, id uint) (*mode
 	return &bene, nil
 }
 
+func (r *Repository) GetBlueButtonID(ctx context.Context, mbi string, updatedAfter time.Time) (string, error) {
+	sb := sqlFlavor.NewSelectBuilder().Select("blue_button_id").From("bfd_patient_ids")
+	sb.Where(sb.Equal("mbi", mbi), sb.GreaterThan("updated_at", updatedAfter))
+
+	query, args := sb.Build()
+	var bbID string
+	if err := r.QueryRowContext(ctx, query, args...).Scan(&bbID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", repository.ErrBlueButtonIDNotFound
+		}
+		return "", err
+	}
+	return bbID, nil
+}
+
+func (r *Repository) SaveBlueButtonID(ctx context.Context, mbi, blueButtonID string) error {
+	ib := sqlFlavor.NewInsertBuilder().InsertInto("bfd_patient_ids")
+	ib.Cols("mbi", "blue_button_id").Values(mbi, blueButtonID)
+	ib.SQL("ON CONFLICT (mbi) DO UPDATE SET blue_button_id = EXCLUDED.blue_button_id, updated_at = NOW()")
+
+	query, args := ib.Build()
+	_, err := r.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (r *Repository) GetPreviousMBIs(ctx context.Context, cmsID, mbi string) ([]string, error) {
+	// Only crosswalks delivered to the ACO by a successfully imported CCLF9 file are used
+	sb := sqlFlavor.NewSelectBuilder().Select("x.prev_num").From("cclf_beneficiary_xrefs x")
+	sb.Join("cclf_files f", "f.id = x.file_id")
+	sb.Where(sb.Equal("f.aco_cms_id", cmsID), sb.Equal("f.import_status", constants.ImportComplete),
+		sb.Equal("x.xref_indicator", "M"), sb.Equal("x.current_num", mbi), sb.NotEqual("x.prev_num", mbi))
+	sb.OrderBy("x.prvs_obslt_dt DESC NULLS LAST", "x.id DESC")
+
+	query, args := sb.Build()
+	rows, err := r.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// The same crosswalk may be delivered by multiple CCLF9 files
+	var mbis []string
+	found := make(map[string]struct{})
+	for rows.Next() {
+		var prevMBI string
+		if err := rows.Scan(&prevMBI); err != nil {
+			return nil, err
+		}
+		prevMBI = strings.TrimSpace(prevMBI)
+		if _, ok := found[prevMBI]; ok {
+			continue
+		}
+		found[prevMBI] = struct{}{}
+		mbis = append(mbis, prevMBI)
+	}
+	return mbis, rows.Err()
+}
+
 func (r *Repository) GetJobByID(ctx context.Context, jobID uint) (*models.Job, error) {
 	sb := sqlFlavor.NewSelectBuilder()
 	sb.Select("id", "aco_id", "request_url", "status", "transaction_time", "job_count", "created_at", "updated_at")
@@ -121,10 +183,27 @@ func (r *Repository) UpdateJobStatusCheckStatus(ctx context.Context, jobID uint,
 		map[string]interface{}{"status": new})
 }
 
+func (r *Repository) SaveFailedQueueJob(ctx context.Context, failed models.FailedQueueJob) error {
+	args, err := json.Marshal(failed.Args)
+	if err != nil {
+		return err
+	}
+
+	ib := sqlFlavor.NewInsertBuilder().InsertInto("failed_queue_jobs")
+	ib.Cols("job_id", "que_job_id", "resource_type", "args").Values(failed.JobID, failed.QueJobID, failed.Args.ResourceType, args)
+	ib.SQL("ON CONFLICT (job_id, que_job_id) DO UPDATE SET resource_type = EXCLUDED.resource_type, args = EXCLUDED.args")
+
+	query, queryArgs := ib.Build()
+	_, err = r.ExecContext(ctx, query, queryArgs...)
+	return err
+}
+
 func (r *Repository) CreateJobKey(ctx context.Context, jobKey models.JobKey) error {
 	ib := sqlFlavor.NewInsertBuilder().InsertInto("job_keys")
-	ib.Cols("job_id", "que_job_id", "file_name", "resource_type").
-		Values(jobKey.JobID, jobKey.QueJobID, jobKey.FileName, jobKey.ResourceType)
+	ib.Cols("job_id", "que_job_id", "file_name", "resource_type", "resource_count", "uncompressed_size", "compressed_size", "digest",
+		"beneficiary_count", "error_count").
+		Values(jobKey.JobID, jobKey.QueJobID, jobKey.FileName, jobKey.ResourceType, jobKey.ResourceCount, jobKey.UncompressedSize, jobKey.CompressedSize, jobKey.Digest,
+			jobKey.BeneficiaryCount, jobKey.ErrorCount)
 
 	query, args := ib.Build()
 	_, err := r.ExecContext(ctx, query, args...)
@@ -133,10 +212,12 @@ func (r *Repository) CreateJobKey(ctx context.Context, jobKey models.JobKey) err
 
 func (r *Repository) CreateJobKeys(ctx context.Context, jobKeys []models.JobKey) error {
 	ib := sqlFlavor.NewInsertBuilder().InsertInto("job_keys")
-	ib.Cols("job_id", "que_job_id", "file_name", "resource_type")
+	ib.Cols("job_id", "que_job_id", "file_name", "resource_type", "resource_count", "uncompressed_size", "compressed_size", "digest",
+		"beneficiary_count", "error_count")
 
 	for _, jobKey := range jobKeys {
-		ib.Values(jobKey.JobID, jobKey.QueJobID, jobKey.FileName, jobKey.ResourceType)
+		ib.Values(jobKey.JobID, jobKey.QueJobID, jobKey.FileName, jobKey.ResourceType, jobKey.ResourceCount, jobKey.UncompressedSize, jobKey.CompressedSize, jobKey.Digest,
+			jobKey.BeneficiaryCount, jobKey.ErrorCount)
 	}
 
 	query, args := ib.Build()
@@ -6,8 +6,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/CMSgov/bcda-app/bcda/constants"
 	"github.com/CMSgov/bcda-app/bcda/database"
 	"github.com/CMSgov/bcda-app/bcda/models"
+	modelspostgres "github.com/CMSgov/bcda-app/bcda/models/postgres"
 	"github.com/CMSgov/bcda-app/bcda/models/postgres/postgrestest"
 	"github.com/CMSgov/bcda-app/bcda/testUtils"
 	"github.com/CMSgov/bcda-app/bcdaworker/repository"
@@ -79,6 +81,84 @@ func (r *RepositoryTestSuite) TestCCLFBeneficiariesMethods() {
 	assert.EqualError(err, "sql: no rows in result set")
 }
 
+// TestBlueButtonIDMethods validates the CRUD operations associated with the bfd_patient_ids table
+func (r *RepositoryTestSuite) TestBlueButtonIDMethods() {
+	assert := r.Assert()
+	ctx := context.Background()
+
+	mbi := testUtils.RandomMBI(r.T())
+	defer func() {
+		_, err := r.db.Exec("DELETE FROM bfd_patient_ids WHERE mbi = $1", mbi)
+		assert.NoError(err)
+	}()
+
+	_, err := r.repository.GetBlueButtonID(ctx, mbi, time.Time{})
+	assert.ErrorIs(err, repository.ErrBlueButtonIDNotFound)
+
+	assert.NoError(r.repository.SaveBlueButtonID(ctx, mbi, "bbID1"))
+	bbID, err := r.repository.GetBlueButtonID(ctx, mbi, time.Now().Add(-time.Hour))
+	assert.NoError(err)
+	assert.Equal("bbID1", bbID)
+
+	// Saving again replaces the cached value
+	assert.NoError(r.repository.SaveBlueButtonID(ctx, mbi, "bbID2"))
+	bbID, err = r.repository.GetBlueButtonID(ctx, mbi, time.Now().Add(-time.Hour))
+	assert.NoError(err)
+	assert.Equal("bbID2", bbID)
+
+	// Entries resolved before the cutoff are treated as missing
+	_, err = r.repository.GetBlueButtonID(ctx, mbi, time.Now().Add(time.Hour))
+	assert.ErrorIs(err, repository.ErrBlueButtonIDNotFound)
+}
+
+// TestGetPreviousMBIs validates the reads associated with the cclf_beneficiary_xrefs table
+func (r *RepositoryTestSuite) TestGetPreviousMBIs() {
+	assert := r.Assert()
+	ctx := context.Background()
+
+	// Since we have a foreign key tie, we need the cclf file to exist before creating associated crosswalks
+	cclfFile := &models.CCLFFile{CCLFNum: 9, ACOCMSID: testUtils.RandomHexID()[0:4], Timestamp: time.Now(), PerformanceYear: 19, Name: uuid.New(),
+		ImportStatus: constants.ImportComplete}
+	postgrestest.CreateCCLFFile(r.T(), r.db, cclfFile)
+	defer postgrestest.DeleteCCLFFilesByCMSID(r.T(), r.db, cclfFile.ACOCMSID)
+
+	mbi, prev1, prev2 := testUtils.RandomMBI(r.T()), testUtils.RandomMBI(r.T()), testUtils.RandomMBI(r.T())
+	for _, xref := range []struct {
+		indicator, currentNum, prevNum string
+		obsoleteDate                   interface{}
+	}{
+		{"M", mbi, prev1, "2016-12-31"},
+		{"M", mbi, prev2, "2018-06-30"},
+		// Duplicated crosswalk, HICN crosswalk, and unrelated crosswalk are ignored
+		{"M", mbi, prev2, "2018-06-30"},
+		{"H", mbi, "203031401A", nil},
+		{"M", prev1, testUtils.RandomMBI(r.T()), "2010-01-01"},
+	} {
+		_, err := r.db.Exec(`INSERT INTO cclf_beneficiary_xrefs (file_id, xref_indicator, current_num, prev_num, prvs_obslt_dt)
+			VALUES ($1, $2, $3, $4, $5)`, cclfFile.ID, xref.indicator, xref.currentNum, xref.prevNum, xref.obsoleteDate)
+		assert.NoError(err)
+	}
+
+	mbis, err := r.repository.GetPreviousMBIs(ctx, cclfFile.ACOCMSID, mbi)
+	assert.NoError(err)
+	assert.Equal([]string{prev2, prev1}, mbis)
+
+	mbis, err = r.repository.GetPreviousMBIs(ctx, cclfFile.ACOCMSID, testUtils.RandomMBI(r.T()))
+	assert.NoError(err)
+	assert.Empty(mbis)
+
+	// Crosswalks delivered to other ACOs are ignored
+	mbis, err = r.repository.GetPreviousMBIs(ctx, testUtils.RandomHexID()[0:4], mbi)
+	assert.NoError(err)
+	assert.Empty(mbis)
+
+	// Crosswalks from files that have not been successfully imported are ignored
+	assert.NoError(modelspostgres.NewRepository(r.db).UpdateCCLFFileImportStatus(ctx, cclfFile.ID, constants.ImportFail))
+	mbis, err = r.repository.GetPreviousMBIs(ctx, cclfFile.ACOCMSID, mbi)
+	assert.NoError(err)
+	assert.Empty(mbis)
+}
+
 // TestJobsMethods validates the CRUD operations associated with the jobs table
 func (r *RepositoryTestSuite) TestJobsMethods() {
 	// Account for time precision in postgres
@@ -182,6 +262,40 @@ func (r *RepositoryTestSuite) TestJobKeyMethods() {
 	assert.EqualError(err, repository.ErrJobKeyNotFound.Error())
 }
 
+// TestFailedQueueJobMethods validates the operations associated with the failed_queue_jobs table
+func (r *RepositoryTestSuite) TestFailedQueueJobMethods() {
+	assert := r.Assert()
+	ctx := context.Background()
+
+	jobID, _ := safecast.ToUint(testUtils.CryptoRandInt31())
+	queJobID := testUtils.CryptoRandInt63()
+	defer func() {
+		_, err := r.db.Exec("DELETE FROM failed_queue_jobs WHERE job_id = $1", jobID)
+		assert.NoError(err)
+	}()
+
+	id, err := safecast.ToInt(jobID)
+	assert.NoError(err)
+	args := models.JobEnqueueArgs{ID: id, ResourceType: "Coverage", BeneficiaryIDs: []string{"1", "2"}, TransactionTime: time.Now().Round(time.Millisecond).UTC()}
+	assert.NoError(r.repository.SaveFailedQueueJob(ctx, models.FailedQueueJob{JobID: jobID, QueJobID: queJobID, Args: args}))
+
+	// Saving the same queue job again replaces its failed beneficiaries
+	args.BeneficiaryIDs = []string{"2"}
+	assert.NoError(r.repository.SaveFailedQueueJob(ctx, models.FailedQueueJob{JobID: jobID, QueJobID: queJobID, Args: args}))
+
+	failed, err := modelspostgres.NewRepository(r.db).GetFailedQueueJobs(ctx, jobID)
+	assert.NoError(err)
+	assert.Len(failed, 1)
+	assert.Equal(jobID, failed[0].JobID)
+	assert.Equal(queJobID, failed[0].QueJobID)
+	assert.Equal([]string{"2"}, failed[0].Args.BeneficiaryIDs)
+	assert.True(args.TransactionTime.Equal(failed[0].Args.TransactionTime))
+
+	failed, err = modelspostgres.NewRepository(r.db).GetFailedQueueJobs(ctx, 0)
+	assert.NoError(err)
+	assert.Empty(failed)
+}
+
 func assertJobsEqual(assert *assert.Assertions, expected, actual models.Job) {
 	expected.TransactionTime, actual.TransactionTime = expected.TransactionTime.UTC(), actual.TransactionTime.UTC()
 	assert.Equal(expected, actual)
@@ -4,6 +4,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/CMSgov/bcda-app/bcda/models"
 	"github.com/pborman/uuid"
@@ -22,7 +23,17 @@ type acoRepository interface {
 
 type cclfBeneficiaryRepository interface {
 	GetCCLFBeneficiaryByID(ctx context.Context, id uint) (*models.CCLFBeneficiary, error)
+
+	// GetBlueButtonID returns the cached BFD patient ID for the given MBI
+	// iff it was resolved after updatedAfter.
+	GetBlueButtonID(ctx context.Context, mbi string, updatedAfter time.Time) (string, error)
+	SaveBlueButtonID(ctx context.Context, mbi, blueButtonID string) error
+
+	// GetPreviousMBIs returns the MBIs that the given MBI directly replaced according to the
+	// CCLF9 crosswalks imported for the ACO, most recently retired first.
+	GetPreviousMBIs(ctx context.Context, cmsID, mbi string) ([]string, error)
 }
+
 type jobRepository interface {
 	GetJobByID(ctx context.Context, jobID uint) (*models.Job, error)
 
@@ -31,6 +42,10 @@ type jobRepository interface {
 	// UpdateJobStatusCheckStatus updates the particular job indicated by the jobID
 	// iff the Job's status field matches current.
 	UpdateJobStatusCheckStatus(ctx context.Context, jobID uint, current, new models.JobStatus) error
+
+	// SaveFailedQueueJob records the beneficiaries that a queue job failed to export.
+	// Saving the same queue job again replaces the existing record.
+	SaveFailedQueueJob(ctx context.Context, failed models.FailedQueueJob) error
 }
 
 type jobKeyRepository interface {
@@ -45,4 +60,6 @@ var (
 	ErrJobNotUpdated  = errors.New("job was not updated, no match found")
 	ErrJobNotFound    = errors.New("no job found for given id")
 	ErrJobKeyNotFound = errors.New("no job key found for given IDs")
+
+	ErrBlueButtonIDNotFound = errors.New("no blue button id found for given MBI")
 )
@@ -1,64 +1,115 @@
 package worker
 
 import (
-	"encoding/json"
-	"strings"
+	"context"
+	goerrors "errors"
+	"time"
 
 	"github.com/CMSgov/bcda-app/bcda/client"
 	models "github.com/CMSgov/bcda-app/bcda/models"
-	"github.com/pkg/errors"
+	"github.com/CMSgov/bcda-app/bcda/utils"
+	"github.com/CMSgov/bcda-app/bcdaworker/repository"
+	"github.com/CMSgov/bcda-app/log"
 )
 
-// This method will ensure that a valid BlueButton ID is returned.
-// If you use cclfBeneficiary.BlueButtonID you will not be guaranteed a valid value
-func getBlueButtonID(bb client.APIClient, mbi string, jobData models.JobEnqueueArgs) (blueButtonID string, err error) {
-	jsonData, err := bb.GetPatientByMbi(jobData, mbi)
-	if err != nil {
-		return "", err
+// maxPreviousMBIs bounds how many retired MBIs are tried for a beneficiary whose current MBI is unknown to Blue Button
+const maxPreviousMBIs = 10
+
+// GetCachedBlueButtonID returns the BlueButton ID for the MBI, preferring a value
+// resolved by a previous job. Entries are keyed by MBI so a beneficiary whose MBI
+// changes is looked up again, and entries older than BB_PATIENT_ID_CACHE_TTL_HOURS
+// are refreshed from Blue Button. Setting the TTL to 0 disables the cache.
+// An ID resolved through a previous MBI is cached under the current MBI.
+func GetCachedBlueButtonID(ctx context.Context, r repository.Repository, bb client.APIClient, mbi string,
+	jobData models.JobEnqueueArgs) (string, error) {
+	ttl := bbPatientIDCacheTTL()
+	if ttl <= 0 {
+		return getBlueButtonIDWithCrosswalk(ctx, r, bb, mbi, jobData)
+	}
+
+	logger := log.GetCtxLogger(ctx)
+	bbID, err := r.GetBlueButtonID(ctx, mbi, time.Now().Add(-ttl))
+	if err == nil {
+		return bbID, nil
+	}
+	if !goerrors.Is(err, repository.ErrBlueButtonIDNotFound) {
+		logger.Warnf("Failed to read cached blue button id, falling back to Blue Button: %s", err.Error())
 	}
 
-	var patient models.Patient
-	err = json.Unmarshal([]byte(jsonData), &patient)
+	bbID, err = getBlueButtonIDWithCrosswalk(ctx, r, bb, mbi, jobData)
 	if err != nil {
 		return "", err
 	}
 
-	if len(patient.Entry) == 0 {
-		err = errors.New("patient identifier not found at Blue Button for CCLF")
-		return "", err
+	if err := r.SaveBlueButtonID(ctx, mbi, bbID); err != nil {
+		logger.Warnf("Failed to cache blue button id: %s", err.Error())
+	}
+	return bbID, nil
+}
+
+// getBlueButtonIDWithCrosswalk looks up the BlueButton ID for the MBI. If Blue Button does not recognize the MBI,
+// the MBIs it replaced according to the ACO's CCLF9 crosswalk are tried in turn, most recently retired first.
+func getBlueButtonIDWithCrosswalk(ctx context.Context, r repository.Repository, bb client.APIClient, mbi string,
+	jobData models.JobEnqueueArgs) (string, error) {
+	bbID, err := client.GetBlueButtonID(bb, mbi, jobData)
+	if !goerrors.Is(err, client.ErrPatientNotFound) {
+		return bbID, err
 	}
 
-	var foundIdentifier = false
-	var foundBlueButtonID = false
-	blueButtonID = patient.Entry[0].Resource.ID
-	for _, identifier := range patient.Entry[0].Resource.Identifier {
-		if strings.Contains(identifier.System, "us-mbi") {
-			if identifier.Value == mbi {
-				foundIdentifier = true
+	logger := log.GetCtxLogger(ctx)
+	tried := map[string]struct{}{mbi: {}}
+	pending := []string{mbi}
+	for len(pending) > 0 && len(tried) <= maxPreviousMBIs {
+		current := pending[0]
+		pending = pending[1:]
+
+		prevMBIs, prevErr := r.GetPreviousMBIs(ctx, jobData.CMSID, current)
+		if prevErr != nil {
+			logger.Warnf("Failed to read previous MBIs from the CCLF9 crosswalk: %s", prevErr.Error())
+			return "", err
+		}
+
+		for _, prevMBI := range prevMBIs {
+			if _, found := tried[prevMBI]; found || len(tried) > maxPreviousMBIs {
+				continue
+			}
+			tried[prevMBI] = struct{}{}
+
+			prevBBID, prevErr := client.GetBlueButtonID(bb, prevMBI, jobData)
+			if prevErr == nil {
+				logger.Infof("Resolved blue button id using a previous MBI after %d lookups", len(tried))
+				return prevBBID, nil
 			}
-		} else if strings.Contains(identifier.System, "bene_id") && identifier.Value == blueButtonID {
-			foundBlueButtonID = true
-		} else if strings.EqualFold(identifier.System, "http://terminology.hl7.org/CodeSystem/v2-0203") {
-			// This hot-fix logic to handle the changes made in this PR:
-			// https://github.com/CMSgov/beneficiary-fhir-data/pull/474
-			// Specifically:
-			// https://github.com/CMSgov/beneficiary-fhir-data/pull/474/files#diff-97195cabdd2698fa9148e9ad32fb8fef8dd462a55dabb9eaf4a4b4300f691fddL112
-			// https://github.com/CMSgov/beneficiary-fhir-data/pull/474/files#diff-97195cabdd2698fa9148e9ad32fb8fef8dd462a55dabb9eaf4a4b4300f691fddR132
-			// https://github.com/CMSgov/beneficiary-fhir-data/pull/474/files#diff-97195cabdd2698fa9148e9ad32fb8fef8dd462a55dabb9eaf4a4b4300f691fddL191
-			if identifier.Value == mbi {
-				foundIdentifier = true
-				foundBlueButtonID = true
+			if !goerrors.Is(prevErr, client.ErrPatientNotFound) {
+				return "", prevErr
 			}
+			// An MBI may itself have replaced an older MBI
+			pending = append(pending, prevMBI)
 		}
 	}
-	if !foundIdentifier {
-		err = errors.New("Identifier not found")
-		return "", err
-	}
-	if !foundBlueButtonID {
-		err = errors.New("Blue Button identifier not found in the identifiers")
-		return "", err
+
+	return "", err
+}
+
+// WarmBlueButtonIDCache resolves the BlueButton ID for every MBI that does not
+// already have a current cache entry, so later jobs can skip the Patient lookup.
+func WarmBlueButtonIDCache(ctx context.Context, r repository.Repository, bb client.APIClient, cmsID string, mbis []string) (warmed, failed int) {
+	logger := log.GetCtxLogger(ctx)
+	for _, mbi := range mbis {
+		if ctx.Err() != nil {
+			failed += len(mbis) - warmed - failed
+			break
+		}
+		if _, err := GetCachedBlueButtonID(ctx, r, bb, mbi, models.JobEnqueueArgs{CMSID: cmsID}); err != nil {
+			logger.Warnf("Failed to resolve blue button id: %s", err.Error())
+			failed++
+			continue
+		}
+		warmed++
 	}
+	return warmed, failed
+}
 
-	return blueButtonID, nil
+func bbPatientIDCacheTTL() time.Duration {
+	return time.Duration(utils.GetEnvInt("BB_PATIENT_ID_CACHE_TTL_HOURS", 168)) * time.Hour
 }
@@ -0,0 +1,296 @@
+package worker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/pkg/errors"
+)
+
+// jsonKind is the type of a JSON value, as far as it can be inferred from the values seen so far
+type jsonKind int
+
+const (
+	kindUnknown jsonKind = iota // only null values (or empty arrays) have been seen
+	kindBoolean
+	kindInteger
+	kindDecimal
+	kindString
+	kindObject
+	// kindJSON is used when the values of an element do not share a type. They are written as compact JSON.
+	kindJSON
+)
+
+// jsonSchema describes the elements of the resources written to a file. It is inferred as the resources are
+// written, so that the NDJSON file only has to be read once when it is converted to Parquet.
+//
+// Objects become nested groups, arrays become repeated fields and primitives become typed columns. Every field is
+// optional since FHIR elements may be absent.
+type jsonSchema struct {
+	kind     jsonKind
+	repeated bool
+	fields   map[string]*jsonSchema
+}
+
+func newJSONSchema() *jsonSchema {
+	return &jsonSchema{kind: kindObject, fields: make(map[string]*jsonSchema)}
+}
+
+// observeResource merges the elements of a single NDJSON resource into the schema
+func (s *jsonSchema) observeResource(line []byte) error {
+	resource, err := decodeResource(line)
+	if err != nil {
+		return err
+	}
+	s.observe(resource, false)
+	return nil
+}
+
+// observe merges a value into the schema. Values nested directly inside an array are marked as inArray,
+// since Parquet cannot represent an array of arrays with repeated fields.
+func (s *jsonSchema) observe(value interface{}, inArray bool) {
+	switch v := value.(type) {
+	case nil:
+		return
+	case []interface{}:
+		if inArray || (s.kind != kindUnknown && !s.repeated) {
+			s.widen(kindJSON)
+			return
+		}
+		if s.kind == kindUnknown {
+			s.repeated = true
+		}
+		if !s.repeated {
+			return
+		}
+		for _, elem := range v {
+			s.observe(elem, true)
+		}
+		return
+	}
+
+	if s.repeated && !inArray {
+		// A single value where an array was seen before
+		s.repeated = false
+		s.widen(kindJSON)
+		return
+	}
+
+	switch v := value.(type) {
+	case bool:
+		s.widen(kindBoolean)
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			s.widen(kindDecimal)
+		} else if _, err := v.Int64(); err != nil {
+			s.widen(kindDecimal)
+		} else {
+			s.widen(kindInteger)
+		}
+	case string:
+		s.widen(kindString)
+	case map[string]interface{}:
+		s.widen(kindObject)
+		if s.kind != kindObject {
+			return
+		}
+		if s.fields == nil {
+			s.fields = make(map[string]*jsonSchema)
+		}
+		for name, elem := range v {
+			field, ok := s.fields[name]
+			if !ok {
+				field = &jsonSchema{}
+				s.fields[name] = field
+			}
+			field.observe(elem, false)
+		}
+	}
+}
+
+// widen changes the kind of the schema so that it can also hold values of the given kind
+func (s *jsonSchema) widen(kind jsonKind) {
+	switch {
+	case s.kind == kind || s.kind == kindJSON:
+	case s.kind == kindUnknown:
+		s.kind = kind
+	case (s.kind == kindInteger && kind == kindDecimal) || (s.kind == kindDecimal && kind == kindInteger):
+		s.kind = kindDecimal
+	default:
+		s.kind = kindJSON
+		s.fields = nil
+	}
+}
+
+// node returns the Parquet node for the schema
+func (s *jsonSchema) node() parquet.Node {
+	var node parquet.Node
+	switch s.kind {
+	case kindBoolean:
+		node = parquet.Leaf(parquet.BooleanType)
+	case kindInteger:
+		node = parquet.Int(64)
+	case kindDecimal:
+		node = parquet.Leaf(parquet.DoubleType)
+	case kindObject:
+		if len(s.fields) == 0 {
+			// Parquet groups must contain at least one field
+			node = parquet.String()
+			break
+		}
+		node = s.group()
+	default:
+		node = parquet.String()
+	}
+
+	if s.repeated {
+		return parquet.Repeated(node)
+	}
+	return parquet.Optional(node)
+}
+
+// group returns the Parquet group for the fields of an object
+func (s *jsonSchema) group() parquet.Group {
+	group := make(parquet.Group, len(s.fields))
+	for name, field := range s.fields {
+		group[name] = field.node()
+	}
+	return group
+}
+
+// value converts a decoded JSON value into the Go value expected by the Parquet node for the schema
+func (s *jsonSchema) value(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if s.repeated {
+		values, _ := value.([]interface{})
+		converted := make([]interface{}, 0, len(values))
+		for _, elem := range values {
+			if elem == nil {
+				continue
+			}
+			v, err := s.element(elem)
+			if err != nil {
+				return nil, err
+			}
+			converted = append(converted, v)
+		}
+		return converted, nil
+	}
+	return s.element(value)
+}
+
+// element converts a single (non-null) value, or an element of an array, for the schema
+func (s *jsonSchema) element(value interface{}) (interface{}, error) {
+	switch s.kind {
+	case kindBoolean:
+		return value, nil
+	case kindInteger:
+		return value.(json.Number).Int64()
+	case kindDecimal:
+		return value.(json.Number).Float64()
+	case kindObject:
+		if len(s.fields) == 0 {
+			return "{}", nil
+		}
+		object := value.(map[string]interface{})
+		converted := make(map[string]interface{}, len(object))
+		for name, elem := range object {
+			v, err := s.fields[name].value(elem)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Error converting element %s", name)
+			}
+			if v != nil {
+				converted[name] = v
+			}
+		}
+		return converted, nil
+	default:
+		if v, ok := value.(string); ok {
+			return v, nil
+		}
+		b, err := json.Marshal(value)
+		return string(b), err
+	}
+}
+
+// writeParquetFile converts the NDJSON file written by writeBBDataToFile into a Parquet file, using the schema
+// inferred while the resources were written.
+func writeParquetFile(ndjsonPath, parquetPath string, schema *jsonSchema) error {
+	if schema == nil || len(schema.fields) == 0 {
+		return errors.New("Error creating parquet schema: no resources were written")
+	}
+	parquetSchema := parquet.NewSchema("resource", schema.group())
+
+	f, err := os.Create(filepath.Clean(parquetPath))
+	if err != nil {
+		return errors.Wrap(err, "Error creating parquet file")
+	}
+	defer f.Close()
+
+	w := parquet.NewWriter(f, parquetSchema)
+	err = forEachResource(ndjsonPath, func(resource map[string]interface{}) error {
+		value, err := schema.element(resource)
+		if err != nil {
+			return err
+		}
+		_, err = w.WriteRows([]parquet.Row{parquetSchema.Deconstruct(nil, value)})
+		return err
+	})
+	if err != nil {
+		return errors.Wrap(err, "Error writing parquet file")
+	}
+
+	if err = w.Close(); err != nil {
+		return errors.Wrap(err, "Error closing parquet writer")
+	}
+	return f.Close()
+}
+
+// forEachResource calls fn for every resource in the NDJSON file
+func forEachResource(ndjsonPath string, fn func(resource map[string]interface{}) error) error {
+	f, err := os.Open(filepath.Clean(ndjsonPath))
+	if err != nil {
+		return errors.Wrap(err, "Error opening ndjson file")
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			resource, err := decodeResource(line)
+			if err != nil {
+				return err
+			}
+			if err := fn(resource); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "Error reading ndjson file")
+		}
+	}
+}
+
+// decodeResource decodes a single NDJSON resource. Numbers are kept as json.Number so that integers
+// can be told apart from decimals.
+func decodeResource(line []byte) (map[string]interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(line))
+	d.UseNumber()
+	var resource map[string]interface{}
+	if err := d.Decode(&resource); err != nil {
+		return nil, errors.Wrap(err, "Error unmarshaling resource")
+	}
+	return resource, nil
+}
@@ -0,0 +1,115 @@
+package worker
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestParquetFile writes the resources to an NDJSON file, inferring the schema as the worker does, then converts it to Parquet
+func writeTestParquetFile(t *testing.T, data string) (string, error) {
+	tempDir := t.TempDir()
+	sw, err := newShardWriter(tempDir, "test", shardPolicy{}, true)
+	assert.NoError(t, err)
+	_, err = sw.Write([]byte(data))
+	assert.NoError(t, err)
+	assert.NoError(t, sw.Close())
+
+	parquetPath := tempDir + "/test.parquet"
+	return parquetPath, writeParquetFile(tempDir+"/test.ndjson", parquetPath, sw.Shards()[0].schema)
+}
+
+func TestWriteParquetFile(t *testing.T) {
+	data := `{"resourceType":"Patient","id":"1","active":true,"multipleBirthInteger":2,"name":[{"family":"Doe","given":["Jane","J"]}],"meta":{"versionId":"1"},"deceasedBoolean":false}
+{"resourceType":"Patient","id":"2","gender":"female","birthDate":null,"multipleBirthInteger":2.5,"name":[],"deceasedBoolean":{"unexpected":true}}
+`
+	parquetPath, err := writeTestParquetFile(t, data)
+	assert.NoError(t, err)
+
+	type name struct {
+		Family *string  `parquet:"family"`
+		Given  []string `parquet:"given"`
+	}
+	type meta struct {
+		VersionID *string `parquet:"versionId"`
+	}
+	type patient struct {
+		ResourceType  *string  `parquet:"resourceType"`
+		ID            *string  `parquet:"id"`
+		Active        *bool    `parquet:"active"`
+		MultipleBirth *float64 `parquet:"multipleBirthInteger"`
+		Name          []name   `parquet:"name"`
+		Meta          *meta    `parquet:"meta"`
+		Gender        *string  `parquet:"gender"`
+		BirthDate     *string  `parquet:"birthDate"`
+		Deceased      *string  `parquet:"deceasedBoolean"`
+	}
+	rows, err := parquet.ReadFile[patient](parquetPath)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+
+	// Elements are typed, objects are nested groups and arrays are repeated
+	assert.Equal(t, "Patient", *rows[0].ResourceType)
+	assert.Equal(t, "1", *rows[0].ID)
+	assert.True(t, *rows[0].Active)
+	assert.Equal(t, 2.0, *rows[0].MultipleBirth)
+	assert.Len(t, rows[0].Name, 1)
+	assert.Equal(t, "Doe", *rows[0].Name[0].Family)
+	assert.Equal(t, []string{"Jane", "J"}, rows[0].Name[0].Given)
+	assert.Equal(t, "1", *rows[0].Meta.VersionID)
+	assert.Nil(t, rows[0].Gender)
+
+	// Elements whose values do not share a type are written as JSON
+	assert.Equal(t, "false", *rows[0].Deceased)
+	assert.Equal(t, `{"unexpected":true}`, *rows[1].Deceased)
+
+	assert.Equal(t, "2", *rows[1].ID)
+	assert.Nil(t, rows[1].Active)
+	assert.Equal(t, 2.5, *rows[1].MultipleBirth)
+	assert.Empty(t, rows[1].Name)
+	assert.Nil(t, rows[1].Meta)
+	assert.Equal(t, "female", *rows[1].Gender)
+	assert.Nil(t, rows[1].BirthDate)
+}
+
+func TestJSONSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []interface{}
+		expKind jsonKind
+		expRep  bool
+	}{
+		{"Null", []interface{}{nil}, kindUnknown, false},
+		{"IntegerAndDecimal", []interface{}{json.Number("1"), json.Number("1.5")}, kindDecimal, false},
+		{"Array", []interface{}{[]interface{}{"a"}, nil, []interface{}{}}, kindString, true},
+		{"EmptyArray", []interface{}{[]interface{}{}}, kindUnknown, true},
+		{"ArrayAndValue", []interface{}{[]interface{}{"a"}, "b"}, kindJSON, false},
+		{"NestedArray", []interface{}{[]interface{}{[]interface{}{"a"}}}, kindJSON, true},
+		{"ObjectAndString", []interface{}{map[string]interface{}{"a": "b"}, "c"}, kindJSON, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &jsonSchema{}
+			for _, v := range tt.values {
+				s.observe(v, false)
+			}
+			assert.Equal(t, tt.expKind, s.kind)
+			assert.Equal(t, tt.expRep, s.repeated)
+		})
+	}
+}
+
+func TestWriteParquetFileInvalidJSON(t *testing.T) {
+	tempDir := t.TempDir()
+	ndjsonPath := tempDir + "/test.ndjson"
+	assert.NoError(t, os.WriteFile(ndjsonPath, []byte("{not json}\n"), 0600))
+
+	schema := newJSONSchema()
+	assert.Error(t, schema.observeResource([]byte("{not json}\n")))
+	assert.NoError(t, schema.observeResource([]byte(`{"id":"1"}`)))
+	assert.Error(t, writeParquetFile(ndjsonPath, tempDir+"/test.parquet", schema))
+}
@@ -0,0 +1,171 @@
+package worker
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/CMSgov/bcda-app/bcda/utils"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+)
+
+// shardPolicy limits the size of each export file written for a queue job.
+// A limit of zero (or less) is not enforced.
+type shardPolicy struct {
+	// maxResources is the maximum number of resources written to a single file
+	maxResources int64
+	// maxBytes is the maximum size, in bytes, of a single uncompressed NDJSON file
+	maxBytes int64
+}
+
+// getShardPolicy returns the sharding policy configured by EXPORT_FILE_MAX_RESOURCES and EXPORT_FILE_MAX_BYTES.
+// By default, all of a queue job's resources are written to a single file.
+func getShardPolicy() shardPolicy {
+	return shardPolicy{
+		maxResources: int64(utils.GetEnvInt("EXPORT_FILE_MAX_RESOURCES", 0)),
+		maxBytes:     int64(utils.GetEnvInt("EXPORT_FILE_MAX_BYTES", 0)),
+	}
+}
+
+// full reports whether a resource of the given size can no longer be added to s without exceeding the policy.
+// A shard always accepts its first resource, so a resource larger than maxBytes is written to a file of its own.
+func (p shardPolicy) full(s shard, size int64) bool {
+	if s.resourceCount == 0 {
+		return false
+	}
+	if p.maxResources > 0 && s.resourceCount >= p.maxResources {
+		return true
+	}
+	return p.maxBytes > 0 && s.size+size > p.maxBytes
+}
+
+// shard is a single NDJSON file written by a shardWriter
+type shard struct {
+	fileUUID      string
+	resourceCount int64
+	size          int64
+	// schema is inferred from the resources written to the file when it will be converted to Parquet
+	schema *jsonSchema
+}
+
+// shardWriter writes NDJSON resources to a sequence of "<uuid>.ndjson" files in dir,
+// rolling over to a new file whenever the next resource would exceed the policy.
+type shardWriter struct {
+	dir    string
+	policy shardPolicy
+	shards []shard
+	// inferSchema records the schema of each file's resources as they are written
+	inferSchema bool
+
+	f *os.File
+	w *bufio.Writer
+	// pending holds a resource that has been partially received by Write
+	pending []byte
+}
+
+// newShardWriter creates the first file, named after fileUUID, in dir.
+// If inferSchema is set, the schema of each file's resources is recorded so that the file can be converted to Parquet.
+func newShardWriter(dir, fileUUID string, policy shardPolicy, inferSchema bool) (*shardWriter, error) {
+	sw := &shardWriter{dir: dir, policy: policy, inferSchema: inferSchema}
+	if err := sw.open(fileUUID); err != nil {
+		return nil, err
+	}
+	return sw, nil
+}
+
+func (sw *shardWriter) open(fileUUID string) error {
+	f, err := os.Create(filepath.Clean(fmt.Sprintf("%s/%s.ndjson", sw.dir, fileUUID)))
+	if err != nil {
+		return errors.Wrap(err, "Error creating ndjson file")
+	}
+	sw.f = f
+	sw.w = bufio.NewWriter(f)
+	sh := shard{fileUUID: fileUUID}
+	if sw.inferSchema {
+		sh.schema = newJSONSchema()
+	}
+	sw.shards = append(sw.shards, sh)
+	return nil
+}
+
+// WriteResource writes a single NDJSON line, which must include the trailing newline
+func (sw *shardWriter) WriteResource(line []byte) error {
+	size := int64(len(line))
+	if sw.policy.full(sw.shards[len(sw.shards)-1], size) {
+		if err := sw.closeFile(); err != nil {
+			return err
+		}
+		if err := sw.open(uuid.New()); err != nil {
+			return err
+		}
+	}
+
+	n, err := sw.w.Write(line)
+	current := &sw.shards[len(sw.shards)-1]
+	current.size += int64(n)
+	if err != nil {
+		return err
+	}
+	current.resourceCount++
+
+	if current.schema != nil {
+		return current.schema.observeResource(line)
+	}
+	return nil
+}
+
+// Write implements io.Writer. A resource is only written once its trailing newline has been
+// received, so that it is never split across files.
+func (sw *shardWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			sw.pending = append(sw.pending, p...)
+			break
+		}
+
+		line := p[:i+1]
+		if len(sw.pending) > 0 {
+			line = append(sw.pending, line...)
+		}
+		if err := sw.WriteResource(line); err != nil {
+			return 0, err
+		}
+		sw.pending = sw.pending[:0]
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// Close writes any incomplete trailing resource, then flushes and closes the file currently being written
+func (sw *shardWriter) Close() error {
+	if len(sw.pending) > 0 {
+		if err := sw.WriteResource(sw.pending); err != nil {
+			return err
+		}
+		sw.pending = nil
+	}
+	return sw.closeFile()
+}
+
+func (sw *shardWriter) closeFile() error {
+	if sw.f == nil {
+		return nil
+	}
+	f := sw.f
+	sw.f = nil
+	if err := sw.w.Flush(); err != nil {
+		f.Close()
+		return errors.Wrap(err, "Error in writing the buffered data to the writer")
+	}
+	return f.Close()
+}
+
+// Shards returns the files written so far, in the order they were written
+func (sw *shardWriter) Shards() []shard {
+	return append([]shard(nil), sw.shards...)
+}
@@ -0,0 +1,109 @@
+package worker
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/CMSgov/bcda-app/conf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetShardPolicy(t *testing.T) {
+	origResources, origBytes := conf.GetEnv("EXPORT_FILE_MAX_RESOURCES"), conf.GetEnv("EXPORT_FILE_MAX_BYTES")
+	defer func() {
+		conf.SetEnv(t, "EXPORT_FILE_MAX_RESOURCES", origResources)
+		conf.SetEnv(t, "EXPORT_FILE_MAX_BYTES", origBytes)
+	}()
+
+	conf.UnsetEnv(t, "EXPORT_FILE_MAX_RESOURCES")
+	conf.UnsetEnv(t, "EXPORT_FILE_MAX_BYTES")
+	assert.Equal(t, shardPolicy{}, getShardPolicy())
+
+	conf.SetEnv(t, "EXPORT_FILE_MAX_RESOURCES", "1000")
+	conf.SetEnv(t, "EXPORT_FILE_MAX_BYTES", "5242880")
+	assert.Equal(t, shardPolicy{maxResources: 1000, maxBytes: 5242880}, getShardPolicy())
+}
+
+func TestShardWriter(t *testing.T) {
+	// Each resource is 10 bytes, including the trailing newline
+	resources := []string{"resource1\n", "resource2\n", "resource3\n", "resource4\n", "resource5\n"}
+
+	tests := []struct {
+		name     string
+		policy   shardPolicy
+		expected [][]string
+	}{
+		{"NoLimits", shardPolicy{}, [][]string{resources}},
+		{"MaxResources", shardPolicy{maxResources: 2}, [][]string{resources[0:2], resources[2:4], resources[4:]}},
+		{"MaxBytes", shardPolicy{maxBytes: 25}, [][]string{resources[0:2], resources[2:4], resources[4:]}},
+		{"MaxBytesExact", shardPolicy{maxBytes: 30}, [][]string{resources[0:3], resources[3:]}},
+		{"ResourceLargerThanMaxBytes", shardPolicy{maxBytes: 5}, [][]string{resources[0:1], resources[1:2], resources[2:3], resources[3:4], resources[4:]}},
+		{"BothLimits", shardPolicy{maxResources: 4, maxBytes: 35}, [][]string{resources[0:3], resources[3:]}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			sw, err := newShardWriter(dir, "first", tt.policy, false)
+			assert.NoError(t, err)
+
+			// Split the resources across writes so that resources are received in pieces
+			data := strings.Join(resources, "")
+			for len(data) > 0 {
+				n := min(7, len(data))
+				written, err := sw.Write([]byte(data[:n]))
+				assert.NoError(t, err)
+				assert.Equal(t, n, written)
+				data = data[n:]
+			}
+			assert.NoError(t, sw.Close())
+
+			shards := sw.Shards()
+			assert.Len(t, shards, len(tt.expected))
+			assert.Equal(t, "first", shards[0].fileUUID)
+			for i, sh := range shards {
+				expected := strings.Join(tt.expected[i], "")
+				assert.EqualValues(t, len(tt.expected[i]), sh.resourceCount)
+				assert.EqualValues(t, len(expected), sh.size)
+
+				b, err := os.ReadFile(fmt.Sprintf("%s/%s.ndjson", dir, sh.fileUUID))
+				assert.NoError(t, err)
+				assert.Equal(t, expected, string(b))
+			}
+		})
+	}
+}
+
+func TestShardWriterIncompleteResource(t *testing.T) {
+	dir := t.TempDir()
+	sw, err := newShardWriter(dir, "first", shardPolicy{maxResources: 1}, false)
+	assert.NoError(t, err)
+
+	_, err = sw.Write([]byte("resource1\nresou"))
+	assert.NoError(t, err)
+	_, err = sw.Write([]byte("rce2"))
+	assert.NoError(t, err)
+	assert.NoError(t, sw.Close())
+	// Closing is idempotent
+	assert.NoError(t, sw.Close())
+
+	shards := sw.Shards()
+	assert.Len(t, shards, 2)
+	b, err := os.ReadFile(fmt.Sprintf("%s/%s.ndjson", dir, shards[1].fileUUID))
+	assert.NoError(t, err)
+	assert.Equal(t, "resource2", string(b))
+}
+
+func TestShardWriterEmpty(t *testing.T) {
+	dir := t.TempDir()
+	sw, err := newShardWriter(dir, "first", shardPolicy{maxResources: 1}, false)
+	assert.NoError(t, err)
+	assert.NoError(t, sw.Close())
+
+	shards := sw.Shards()
+	assert.Equal(t, []shard{{fileUUID: "first"}}, shards)
+	_, err = os.Stat(fmt.Sprintf("%s/first.ndjson", dir))
+	assert.NoError(t, err)
+}
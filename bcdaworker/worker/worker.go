@@ -4,7 +4,9 @@ import (
 	"bufio"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
 	goerrors "errors"
 	"fmt"
@@ -12,12 +14,15 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"sync"
 
 	"github.com/CMSgov/bcda-app/bcda/cclf/metrics"
 	"github.com/CMSgov/bcda-app/bcda/client"
+	"github.com/CMSgov/bcda-app/bcda/client/fhir"
 	"github.com/CMSgov/bcda-app/bcda/constants"
 	"github.com/CMSgov/bcda-app/bcda/models"
 	fhirmodels "github.com/CMSgov/bcda-app/bcda/models/fhir"
+	"github.com/CMSgov/bcda-app/bcda/resourcetypes"
 	"github.com/CMSgov/bcda-app/bcda/responseutils"
 	"github.com/CMSgov/bcda-app/bcda/utils"
 	"github.com/CMSgov/bcda-app/bcdaworker/repository"
@@ -28,10 +33,40 @@ import (
 	"github.com/sirupsen/logrus"
 
 	fhircodes "github.com/google/fhir/go/proto/google/fhir/proto/stu3/codes_go_proto"
+	fhirdatatypes "github.com/google/fhir/go/proto/google/fhir/proto/stu3/datatypes_go_proto"
+	fhirmodelsv1 "github.com/google/fhir/go/proto/google/fhir/proto/stu3/resources_go_proto"
 	"github.com/pborman/uuid"
 	"github.com/pkg/errors"
 )
 
+// Code systems and extensions describing the issues recorded in error files, so that failures can be
+// aggregated and reprocessed without parsing the diagnostics
+const (
+	// FailureReasonSystem identifies the code system of the failure reasons recorded in error files
+	FailureReasonSystem = "https://bcda.cms.gov/fhir/CodeSystem/failure-reason"
+	// ErrorCategorySystem identifies the code system of the error categories recorded in error files
+	ErrorCategorySystem = "https://bcda.cms.gov/fhir/CodeSystem/error-category"
+
+	BeneficiaryExtensionURL  = "https://bcda.cms.gov/fhir/StructureDefinition/beneficiary"
+	ResourceTypeExtensionURL = "https://bcda.cms.gov/fhir/StructureDefinition/resource-type"
+	StatusCodeExtensionURL   = "https://bcda.cms.gov/fhir/StructureDefinition/bfd-status-code"
+	RetryCountExtensionURL   = "https://bcda.cms.gov/fhir/StructureDefinition/bfd-retry-count"
+
+	mbiSystem = "http://hl7.org/fhir/sid/us-mbi"
+)
+
+// Error categories recorded in error files
+const (
+	// ErrorCategoryBeneficiary indicates that the beneficiary could not be found or matched to a BFD patient
+	ErrorCategoryBeneficiary = "beneficiary"
+	// ErrorCategoryRequest indicates that the beneficiary's resources could not be retrieved from BFD
+	ErrorCategoryRequest = "bfd-request"
+	// ErrorCategoryProcessing indicates that a resource retrieved from BFD could not be written
+	ErrorCategoryProcessing = "processing"
+	// ErrorCategoryCancelled indicates that the job was cancelled before all beneficiaries were processed
+	ErrorCategoryCancelled = "cancelled"
+)
+
 type Worker interface {
 	ValidateJob(ctx context.Context, queJobID int64, jobArgs models.JobEnqueueArgs) (*models.Job, error)
 	ProcessJob(ctx context.Context, queJobID int64, job models.Job, jobArgs models.JobEnqueueArgs) error
@@ -145,6 +180,11 @@ func (w *worker) ProcessJob(ctx context.Context, queJobID int64, job models.Job,
 
 	jobKeys, err := writeBBDataToFile(ctx, w.r, bb, *aco.CMSID, queJobID, jobArgs, tempJobPath)
 
+	// Leave the job in progress so that the queue job can be retried once Blue Button is available
+	if goerrors.Is(err, client.ErrCircuitOpen) {
+		return errors.Wrap(err, "ProcessJob: Blue Button is unavailable")
+	}
+
 	// This is only run AFTER completion of all the collection
 	if err != nil {
 		logger.Error(errors.Wrap(err, "ProcessJob: Error occurred when writing BFD Data to file"))
@@ -164,12 +204,20 @@ func (w *worker) ProcessJob(ctx context.Context, queJobID int64, job models.Job,
 		}
 	}
 	//move the files over
-	err = compressFiles(ctx, tempJobPath, stagingPath)
+	digests, err := compressFiles(ctx, tempJobPath, stagingPath)
 	if err != nil {
 		logger.Error(err)
 		return err
 	}
 
+	if err = setFileSizes(jobKeys, tempJobPath, stagingPath); err != nil {
+		logger.Error(err)
+		return err
+	}
+	for i := range jobKeys {
+		jobKeys[i].Digest = digests[jobKeys[i].FileName]
+	}
+
 	err = createJobKeys(ctx, w.r, jobKeys, job.ID)
 	if err != nil {
 		logger.Error(err)
@@ -179,19 +227,22 @@ func (w *worker) ProcessJob(ctx context.Context, queJobID int64, job models.Job,
 	return nil
 }
 
-func compressFiles(ctx context.Context, tempDir string, stagingDir string) error {
+// compressFiles gzips the files in tempDir into stagingDir. The SHA-256 digest (hex encoded) of
+// each compressed file is returned, keyed by file name.
+func compressFiles(ctx context.Context, tempDir string, stagingDir string) (map[string]string, error) {
 	logger := log.GetCtxLogger(ctx)
 	// Open the input file
 	files, err := os.ReadDir(tempDir)
 	if err != nil {
 		err = errors.Wrap(err, "Error reading from the staging directory for files for Job")
-		return err
+		return nil, err
 	}
 	gzipLevel, err := strconv.Atoi(os.Getenv("COMPRESSION_LEVEL"))
 	if err != nil || gzipLevel < 1 || gzipLevel > 9 { //levels 1-9 supported by BCDA.
 		gzipLevel = gzip.DefaultCompression
 		logger.Warnf("COMPRESSION_LEVEL not set to appropriate value; using default.")
 	}
+	digests := make(map[string]string, len(files))
 	for _, f := range files {
 		oldPath := fmt.Sprintf("%s/%s", tempDir, f.Name())
 		newPath := fmt.Sprintf("%s/%s", stagingDir, f.Name())
@@ -208,27 +259,55 @@ func compressFiles(ctx context.Context, tempDir string, stagingDir string) error
 				return err
 			}
 			defer CloseOrLogError(logger, outputFile)
-			gzipWriter, err := gzip.NewWriterLevel(outputFile, gzipLevel)
+
+			// Hash the compressed bytes as they are written so the digest matches the stored file
+			hash := sha256.New()
+			gzipWriter, err := gzip.NewWriterLevel(io.MultiWriter(outputFile, hash), gzipLevel)
 			if err != nil {
 				return err
 			}
-			defer gzipWriter.Close()
 
 			// Copy the data from the input file to the gzip writer
 			if _, err := io.Copy(gzipWriter, inputFile); err != nil {
+				gzipWriter.Close()
+				return err
+			}
+			if err := gzipWriter.Close(); err != nil {
 				return err
 			}
+			digests[f.Name()] = hex.EncodeToString(hash.Sum(nil))
 			return nil
 		}()
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 	}
-	return nil
+	return digests, nil
 
 }
 
+// setFileSizes populates the uncompressed and compressed sizes of the files referenced by the job keys
+func setFileSizes(jobKeys []models.JobKey, tempDir, stagingDir string) error {
+	for i := range jobKeys {
+		if jobKeys[i].FileName == models.BlankFileName {
+			continue
+		}
+
+		uncompressed, err := os.Stat(fmt.Sprintf("%s/%s", tempDir, jobKeys[i].FileName))
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Error retrieving uncompressed size of %s", jobKeys[i].FileName))
+		}
+		compressed, err := os.Stat(fmt.Sprintf("%s/%s", stagingDir, jobKeys[i].FileName))
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Error retrieving compressed size of %s", jobKeys[i].FileName))
+		}
+		jobKeys[i].UncompressedSize = uncompressed.Size()
+		jobKeys[i].CompressedSize = compressed.Size()
+	}
+	return nil
+}
+
 func CloseOrLogError(logger logrus.FieldLogger, f *os.File) {
 	if f == nil {
 		return
@@ -240,8 +319,11 @@ func CloseOrLogError(logger logrus.FieldLogger, f *os.File) {
 
 // writeBBDataToFile sends requests to BlueButton and writes the results to ndjson files.
 // A list of JobKeys are returned, containing the names of files that were created.
-// Filesnames can be "blank.ndjson", "<uuid>.ndjson", or "<uuid>-error.ndjson".
-func writeBBDataToFile(ctx context.Context, r repository.Repository, bb client.APIClient,
+// Filesnames can be "blank.ndjson", "<uuid>.ndjson", "<uuid>.parquet", or "<uuid>-error.ndjson".
+// The resources are split across multiple files according to the shard policy (see getShardPolicy),
+// with a JobKey for each file. Every JobKey shares the queue job ID, so the keys are created together
+// once the queue job has been processed.
+func writeBBDataToFile(ctx context.Context, r repository.Repository, bb client.StreamingAPIClient,
 	cmsID string, queJobID int64, jobArgs models.JobEnqueueArgs, tmpDir string) (jobKeys []models.JobKey, err error) {
 
 	id, err := safecast.ToUint(jobArgs.ID)
@@ -254,100 +336,72 @@ func writeBBDataToFile(ctx context.Context, r repository.Repository, bb client.A
 	close := metrics.NewChild(ctx, "writeBBDataToFile")
 	defer close()
 
-	var bundleFunc func(bene models.CCLFBeneficiary) (*fhirmodels.Bundle, error)
-	// NOTE: Currently all Coverage/EOB/Patient requests are for adjudicated data and
-	// Claim/ClaimResponse are partially-adjudicated, future work may require checking what
-	// kind of backing data to pull from if there is overlap (one or more FHIR resource
-	// used for representing both adjudicated and partially-adjudicated data)
-	switch jobArgs.ResourceType {
-	case "Coverage":
-		bundleFunc = func(bene models.CCLFBeneficiary) (*fhirmodels.Bundle, error) {
-			return bb.GetCoverage(jobArgs, bene.BlueButtonID)
-		}
-	case "ExplanationOfBenefit":
-		bundleFunc = func(bene models.CCLFBeneficiary) (*fhirmodels.Bundle, error) {
-			cw := client.ClaimsWindow{
-				LowerBound: jobArgs.ClaimsWindow.LowerBound,
-				UpperBound: jobArgs.ClaimsWindow.UpperBound}
-			return bb.GetExplanationOfBenefit(jobArgs, bene.BlueButtonID, cw)
-		}
-	case "Patient":
-		bundleFunc = func(bene models.CCLFBeneficiary) (*fhirmodels.Bundle, error) {
-			return bb.GetPatient(jobArgs, bene.BlueButtonID)
-		}
-		//NOTE: The assumption is Claim/ClaimResponse is always partially-adjudicated, future work may require checking what
-		//kind of backing data to pull from
-	case "Claim":
-		bundleFunc = func(bene models.CCLFBeneficiary) (*fhirmodels.Bundle, error) {
-			cw := client.ClaimsWindow{
-				LowerBound: jobArgs.ClaimsWindow.LowerBound,
-				UpperBound: jobArgs.ClaimsWindow.UpperBound}
-			return bb.GetClaim(jobArgs, bene.MBI, cw)
-		}
-	case "ClaimResponse":
-		bundleFunc = func(bene models.CCLFBeneficiary) (*fhirmodels.Bundle, error) {
-			cw := client.ClaimsWindow{
-				LowerBound: jobArgs.ClaimsWindow.LowerBound,
-				UpperBound: jobArgs.ClaimsWindow.UpperBound}
-			return bb.GetClaimResponse(jobArgs, bene.MBI, cw)
-		}
-	default:
-		return jobKeys, fmt.Errorf("unsupported resource type requested: %s", jobArgs.ResourceType)
+	pageFunc, err := NewPageFunc(bb, jobArgs)
+	if err != nil {
+		return jobKeys, err
 	}
 
 	fileUUID := uuid.New()
-	f, err := os.Create(fmt.Sprintf("%s/%s.ndjson", tmpDir, fileUUID))
+	sw, err := newShardWriter(tmpDir, fileUUID, getShardPolicy(), jobArgs.OutputFormat == constants.ParquetOutputFormat)
 	if err != nil {
-		err = errors.Wrap(err, "Error creating ndjson file")
 		return jobKeys, err
 	}
+	defer func() {
+		if err := sw.Close(); err != nil {
+			logger.Warnf("Error closing file: %v", err)
+		}
+	}()
 
-	defer utils.CloseFileAndLogError(f)
-
-	w := bufio.NewWriter(f)
-	defer w.Flush()
 	errorCount := 0
 	totalBeneIDs := float64(len(jobArgs.BeneficiaryIDs))
 	failThreshold := getFailureThreshold()
 	failed := false
 
-	for _, beneID := range jobArgs.BeneficiaryIDs {
+	var failedBeneIDs []string
+	writePage := func(w *bufio.Writer, beneID string, page *fhirmodels.Bundle) int64 {
+		return fhirBundleToResourceNDJSON(ctx, w, page, jobArgs, beneID, cmsID, fileUUID, tmpDir)
+	}
+
+	// Spill files are kept out of tmpDir, since every file in tmpDir is published by compressFiles
+	spillDir, err := os.MkdirTemp("", fmt.Sprintf("bcda-spill-%d-*", jobArgs.ID))
+	if err != nil {
+		return jobKeys, errors.Wrap(err, "Error creating the spill directory")
+	}
+	defer func() {
+		if err := os.RemoveAll(spillDir); err != nil {
+			logger.Warnf("Error removing spill directory: %v", err)
+		}
+	}()
+
+	// Beneficiary data is fetched concurrently but written in the order of the beneficiary IDs,
+	// so each beneficiary's resources remain contiguous and the output is deterministic.
+	results, stop := fetchBeneficiaryData(ctx, r, bb, pageFunc, writePage, jobArgs, spillDir)
+	defer stop()
+
+	for result := range results {
+		res := <-result
+
 		// if the parent job was cancelled, stop processing beneIDs and fail the job
 		if ctx.Err() == context.Canceled {
+			res.discard()
 			failed = true
 			break
 		}
 
-		fileErrMsg, code, err := func() (string, fhircodes.IssueTypeCode_Value, error) {
-			id, err := strconv.ParseUint(beneID, 10, 64)
-			if err != nil {
-				return fmt.Sprintf("Error failed to convert %s to uint", beneID), fhircodes.IssueTypeCode_EXCEPTION, err
-			}
-
-			// NOTE: with adjudicated data sets, we first need to lookup the Patient ID
-			// before gathering EOB/Coverage results; however with partially-adjudicated data
-			// that is not yet possible because their are no Patient FHIR resources. This
-			// boolean indicates whether or not we need to skip that lookup step
-			fetchBBId := jobArgs.DataType != constants.PartiallyAdjudicated
-			bene, err := getBeneficiary(ctx, r, uint(id), bb, fetchBBId, jobArgs)
-			if err != nil {
-				//MBI is appended inside file, not printed out to system logs
-				return fmt.Sprintf("Error retrieving BlueButton ID for cclfBeneficiary MBI %s", bene.MBI), fhircodes.IssueTypeCode_NOT_FOUND, err
-			}
-
-			b, err := bundleFunc(bene)
-			if err != nil {
-				//MBI is appended inside file, not printed out to system logs
-				return fmt.Sprintf("Error retrieving %s for beneficiary MBI %s in ACO %s", jobArgs.ResourceType, bene.MBI, jobArgs.ACOID), fhircodes.IssueTypeCode_NOT_FOUND, err
-			}
-			fhirBundleToResourceNDJSON(ctx, w, b, jobArgs.ResourceType, beneID, cmsID, fileUUID, tmpDir)
-			return "", 0, nil
-		}()
-
-		if err != nil {
-			logger.Error(err)
+		// Blue Button is unavailable, so the remaining beneficiaries would fail as well.
+		// The queue job is retried once the circuit breaker allows requests again.
+		if goerrors.Is(res.err, client.ErrCircuitOpen) {
+			return jobKeys, res.err
+		}
+		if res.err != nil {
+			logger.Error(res.err)
 			errorCount++
-			appendErrorToFile(ctx, fileUUID, code, responseutils.BbErr, fileErrMsg, tmpDir)
+			failedBeneIDs = append(failedBeneIDs, res.beneID)
+			appendErrorToFile(ctx, fileUUID, res.code, responseutils.BbErr, res.fileErrMsg, res.details, tmpDir)
+		} else {
+			if err = res.writeTo(sw); err != nil {
+				return jobKeys, errors.Wrap(err, fmt.Sprintf("Error writing data for cclfBeneficiaryId %s to the ndjson file", res.beneID))
+			}
 		}
 
 		failPct := (float64(errorCount) / totalBeneIDs) * 100
@@ -357,35 +411,225 @@ func writeBBDataToFile(ctx context.Context, r repository.Repository, bb client.A
 		}
 	}
 
-	if err = w.Flush(); err != nil {
-		return jobKeys, errors.Wrap(err, "Error in writing the buffered data to the writer")
+	if err = sw.Close(); err != nil {
+		return jobKeys, err
 	}
 
 	if failed {
 		if ctx.Err() == context.Canceled {
-			appendErrorToFile(ctx, fileUUID, fhircodes.IssueTypeCode_PROCESSING, responseutils.BbErr, "Parent job was cancelled", tmpDir)
+			appendErrorToFile(ctx, fileUUID, fhircodes.IssueTypeCode_PROCESSING, responseutils.BbErr, "Parent job was cancelled",
+				errorDetails{resourceType: jobArgs.ResourceType, category: ErrorCategoryCancelled}, tmpDir)
 			return jobKeys, errors.New("Parent job was cancelled")
 		}
 		return jobKeys, errors.New(fmt.Sprintf("Number of failed requests has exceeded threshold of %f ", failThreshold))
 	}
 
-	fstat, err := f.Stat()
-	if err != nil {
-		err = errors.Wrap(err, fmt.Sprintf("Error in obtaining FileInfo structure describing the file for ndjson fileUUID %s jobId %d for cmsID %s", fileUUID, jobArgs.ID, cmsID))
-		return jobKeys, err
-	}
+	// Progress counts are recorded on the first job key only so that they can be summed across a job's keys
+	jobKeys[0].BeneficiaryCount = int64(len(jobArgs.BeneficiaryIDs))
+	jobKeys[0].ErrorCount = int64(errorCount)
 
-	if fstat.Size() != 0 {
-		pr := &jobKeys[0]
-		(*pr).FileName = fileUUID + ".ndjson"
+	for i, sh := range sw.Shards() {
+		// Only the first file can be empty, in which case the blank job key is kept
+		if sh.size == 0 {
+			continue
+		}
+
+		fileName := sh.fileUUID + ".ndjson"
+		if jobArgs.OutputFormat == constants.ParquetOutputFormat {
+			ndjsonPath := fmt.Sprintf("%s/%s.ndjson", tmpDir, sh.fileUUID)
+			if err = writeParquetFile(ndjsonPath, fmt.Sprintf("%s/%s.parquet", tmpDir, sh.fileUUID), sh.schema); err != nil {
+				return jobKeys, errors.Wrap(err, fmt.Sprintf("Error converting fileUUID %s to parquet for jobId %d", sh.fileUUID, jobArgs.ID))
+			}
+			if err = os.Remove(ndjsonPath); err != nil {
+				return jobKeys, errors.Wrap(err, fmt.Sprintf("Error removing ndjson fileUUID %s for jobId %d", sh.fileUUID, jobArgs.ID))
+			}
+			fileName = sh.fileUUID + ".parquet"
+		}
+
+		if i == 0 {
+			jobKeys[0].FileName = fileName
+			jobKeys[0].ResourceCount = sh.resourceCount
+			continue
+		}
+		jobKeys = append(jobKeys, models.JobKey{JobID: id, QueJobID: &queJobID, FileName: fileName, ResourceType: jobArgs.ResourceType, ResourceCount: sh.resourceCount})
 	}
 
 	if errorCount > 0 {
 		jobKeys = append(jobKeys, models.JobKey{JobID: id, QueJobID: &queJobID, FileName: fileUUID + "-error.ndjson", ResourceType: jobArgs.ResourceType})
+
+		// Record the failed beneficiaries so that a retry job can export them once the job has completed
+		failedArgs := jobArgs
+		failedArgs.BeneficiaryIDs = failedBeneIDs
+		if err = r.SaveFailedQueueJob(ctx, models.FailedQueueJob{JobID: id, QueJobID: queJobID, Args: failedArgs}); err != nil {
+			return jobKeys, errors.Wrap(err, fmt.Sprintf("Error recording the failed beneficiaries for jobId %d", jobArgs.ID))
+		}
 	}
 	return jobKeys, nil
 }
 
+// beneficiaryResult contains the data retrieved for a single beneficiary, or the error (and the message
+// to write to the error file) encountered while retrieving it
+type beneficiaryResult struct {
+	beneID string
+	// spill holds the beneficiary's resources as NDJSON until they can be written to the job's file in order
+	spill      *os.File
+	count      int64
+	fileErrMsg string
+	code       fhircodes.IssueTypeCode_Value
+	details    errorDetails
+	err        error
+}
+
+// writeTo copies the beneficiary's resources to w and removes the spill file
+func (res beneficiaryResult) writeTo(w io.Writer) error {
+	defer res.discard()
+
+	if _, err := res.spill.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := io.Copy(w, res.spill)
+	return err
+}
+
+// discard removes the spill file, if any
+func (res beneficiaryResult) discard() {
+	if res.spill == nil {
+		return
+	}
+	utils.CloseFileAndLogError(res.spill)
+	if err := os.Remove(res.spill.Name()); err != nil {
+		logrus.StandardLogger().Error(err)
+	}
+}
+
+// fetchBeneficiaryData retrieves the data for each of the job's beneficiaries using a bounded pool of goroutines
+// (EXPORT_BENE_CONCURRENCY). A channel receiving each beneficiary's result is sent in the order of the beneficiary IDs.
+// Each page is written by writePage to a spill file in spillDir as it arrives, so memory use does not grow with the
+// number of resources a beneficiary has.
+// Once ctx is cancelled, no further beneficiaries are fetched and the last result holds the cancellation error.
+// The returned stop function prevents any further beneficiaries from being fetched and waits for in-flight fetches to complete.
+func fetchBeneficiaryData(ctx context.Context, r repository.Repository, bb client.APIClient,
+	pageFunc func(bene models.CCLFBeneficiary, handle client.PageHandler) error,
+	writePage func(w *bufio.Writer, beneID string, page *fhirmodels.Bundle) int64,
+	jobArgs models.JobEnqueueArgs, spillDir string) (<-chan chan beneficiaryResult, func()) {
+
+	concurrency := utils.GetEnvInt("EXPORT_BENE_CONCURRENCY", 4)
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// The buffer bounds the number of beneficiaries fetched ahead of the one being written
+	results := make(chan chan beneficiaryResult, concurrency-1)
+	stop := make(chan struct{})
+
+	go func() {
+		var wg sync.WaitGroup
+		defer func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		for _, beneID := range jobArgs.BeneficiaryIDs {
+			result := make(chan beneficiaryResult, 1)
+			select {
+			case results <- result:
+			case <-stop:
+				return
+			}
+
+			// Beneficiaries are not fetched once the parent job has been cancelled
+			if ctx.Err() == context.Canceled {
+				result <- beneficiaryResult{beneID: beneID, err: ctx.Err()}
+				return
+			}
+
+			wg.Add(1)
+			go func(beneID string) {
+				defer wg.Done()
+				result <- fetchBeneficiary(ctx, r, bb, pageFunc, writePage, jobArgs, spillDir, beneID)
+			}(beneID)
+		}
+	}()
+
+	var once sync.Once
+	return results, func() {
+		once.Do(func() {
+			close(stop)
+			// Results are buffered, so draining the channel only waits for the in-flight fetches to complete
+			for result := range results {
+				(<-result).discard()
+			}
+		})
+	}
+}
+
+// fetchBeneficiary retrieves the job's resource type for a single beneficiary and writes it to a spill file
+func fetchBeneficiary(ctx context.Context, r repository.Repository, bb client.APIClient,
+	pageFunc func(bene models.CCLFBeneficiary, handle client.PageHandler) error,
+	writePage func(w *bufio.Writer, beneID string, page *fhirmodels.Bundle) int64,
+	jobArgs models.JobEnqueueArgs, spillDir string, beneID string) beneficiaryResult {
+
+	id, err := strconv.ParseUint(beneID, 10, 64)
+	if err != nil {
+		return beneficiaryResult{beneID: beneID, fileErrMsg: fmt.Sprintf("Error failed to convert %s to uint", beneID), code: fhircodes.IssueTypeCode_EXCEPTION,
+			details: errorDetails{resourceType: jobArgs.ResourceType, category: ErrorCategoryBeneficiary}, err: err}
+	}
+
+	// NOTE: most resources are requested by BFD patient ID, so we first need to lookup
+	// the Patient ID before gathering their results; however resources such as the
+	// partially-adjudicated Claim/ClaimResponse are requested by MBI because there are no
+	// Patient FHIR resources for them. This boolean indicates whether or not we need to skip that lookup step
+	resource, _ := resourcetypes.GetResource(jobArgs.ResourceType)
+	fetchBBId := !resource.RequiresMBI
+	bene, err := getBeneficiary(ctx, r, uint(id), bb, fetchBBId, jobArgs)
+	if err != nil {
+		//MBI is appended inside file, not printed out to system logs
+		return beneficiaryResult{beneID: beneID, fileErrMsg: fmt.Sprintf("Error retrieving BlueButton ID for cclfBeneficiary MBI %s", bene.MBI), code: fhircodes.IssueTypeCode_NOT_FOUND,
+			details: newErrorDetails(ErrorCategoryBeneficiary, bene.MBI, jobArgs.ResourceType, err), err: err}
+	}
+
+	spill, err := os.CreateTemp(spillDir, fmt.Sprintf("%s-*.spill", beneID))
+	if err != nil {
+		return beneficiaryResult{beneID: beneID, fileErrMsg: fmt.Sprintf("Error writing %s for beneficiary MBI %s in ACO %s", jobArgs.ResourceType, bene.MBI, jobArgs.ACOID), code: fhircodes.IssueTypeCode_EXCEPTION,
+			details: newErrorDetails(ErrorCategoryProcessing, bene.MBI, jobArgs.ResourceType, err), err: err}
+	}
+	res := beneficiaryResult{beneID: beneID, spill: spill}
+
+	w := bufio.NewWriter(spill)
+	err = pageFunc(bene, func(page *fhirmodels.Bundle) error {
+		res.count += writePage(w, beneID, page)
+		return nil
+	})
+	if err != nil {
+		// Resources from the pages received before the failure are not exported
+		res.discard()
+		//MBI is appended inside file, not printed out to system logs
+		return beneficiaryResult{beneID: beneID, fileErrMsg: fmt.Sprintf("Error retrieving %s for beneficiary MBI %s in ACO %s", jobArgs.ResourceType, bene.MBI, jobArgs.ACOID), code: fhircodes.IssueTypeCode_NOT_FOUND,
+			details: newErrorDetails(ErrorCategoryRequest, bene.MBI, jobArgs.ResourceType, err), err: err}
+	}
+
+	if err = w.Flush(); err != nil {
+		res.discard()
+		return beneficiaryResult{beneID: beneID, fileErrMsg: fmt.Sprintf("Error writing %s for beneficiary MBI %s in ACO %s", jobArgs.ResourceType, bene.MBI, jobArgs.ACOID), code: fhircodes.IssueTypeCode_EXCEPTION,
+			details: newErrorDetails(ErrorCategoryProcessing, bene.MBI, jobArgs.ResourceType, err), err: err}
+	}
+
+	return res
+}
+
+// NewPageFunc returns a function that streams the job's resource type for a beneficiary from the BlueButton API,
+// passing each page of the bundle to handle as it is received.
+func NewPageFunc(bb client.StreamingAPIClient, jobArgs models.JobEnqueueArgs) (func(bene models.CCLFBeneficiary, handle client.PageHandler) error, error) {
+	resource, ok := resourcetypes.GetResource(jobArgs.ResourceType)
+	if !ok {
+		return nil, fmt.Errorf("unsupported resource type requested: %s", jobArgs.ResourceType)
+	}
+
+	return func(bene models.CCLFBeneficiary, handle client.PageHandler) error {
+		return resource.FetchFiltered(bb, jobArgs, bene, handle)
+	}, nil
+}
+
 // getBeneficiary returns the beneficiary. The bb ID value is retrieved and set in the model.
 func getBeneficiary(ctx context.Context, r repository.Repository, beneID uint, bb client.APIClient, fetchBBId bool, jobData models.JobEnqueueArgs) (models.CCLFBeneficiary, error) {
 	bene, err := r.GetCCLFBeneficiaryByID(ctx, beneID)
@@ -397,7 +641,7 @@ func getBeneficiary(ctx context.Context, r repository.Repository, beneID uint, b
 	cclfBeneficiary := *bene
 
 	if fetchBBId {
-		bbID, err := getBlueButtonID(bb, cclfBeneficiary.MBI, jobData)
+		bbID, err := GetCachedBlueButtonID(ctx, r, bb, cclfBeneficiary.MBI, jobData)
 
 		if err != nil {
 			err = errors.Wrap(err, fmt.Sprintf("failed to get blueButtonId for cclfBeneficiaryId %d", beneID))
@@ -423,14 +667,101 @@ func getFailureThreshold() float64 {
 	return float64(exportFailPct)
 }
 
+// errorDetails describes an issue recorded in an error file
+type errorDetails struct {
+	mbi          string
+	resourceType string
+	category     string
+	// reason classifies why the request to Blue Button failed
+	reason string
+	// statusCode is the HTTP status code returned by Blue Button, if any
+	statusCode int32
+	// retryCount is the number of times the failed request to Blue Button was retried
+	retryCount int32
+}
+
+// newErrorDetails describes an error encountered while exporting the beneficiary's resources
+func newErrorDetails(category, mbi, resourceType string, err error) errorDetails {
+	details := errorDetails{
+		mbi:          mbi,
+		resourceType: resourceType,
+		category:     category,
+		reason:       fhir.FailureReason(err),
+	}
+	details.retryCount, _ = safecast.ToInt32(client.RetryCount(err))
+
+	var respErr *fhir.ResponseError
+	if goerrors.As(err, &respErr) {
+		details.statusCode, _ = safecast.ToInt32(respErr.StatusCode)
+	}
+
+	return details
+}
+
+// addTo records the details on the issue. The category and failure reason are added as codings of the
+// issue details and the remaining details are added as extensions.
+func (d errorDetails) addTo(issue *fhirmodelsv1.OperationOutcome_Issue) {
+	var coding []*fhirdatatypes.Coding
+	// The classified reason lets consumers tell failures worth retrying (e.g. rate-limited) from permanent ones (e.g. not-found)
+	if d.reason != "" {
+		coding = append(coding, &fhirdatatypes.Coding{
+			System: &fhirdatatypes.Uri{Value: FailureReasonSystem},
+			Code:   &fhirdatatypes.Code{Value: d.reason},
+		})
+	}
+	if d.category != "" {
+		coding = append(coding, &fhirdatatypes.Coding{
+			System: &fhirdatatypes.Uri{Value: ErrorCategorySystem},
+			Code:   &fhirdatatypes.Code{Value: d.category},
+		})
+	}
+	if len(coding) > 0 {
+		issue.Details = &fhirdatatypes.CodeableConcept{Coding: coding}
+	}
+
+	extension := func(url string, value *fhirdatatypes.Extension_ValueX) {
+		issue.Extension = append(issue.Extension, &fhirdatatypes.Extension{Url: &fhirdatatypes.Uri{Value: url}, Value: value})
+	}
+	if d.mbi != "" {
+		extension(BeneficiaryExtensionURL, &fhirdatatypes.Extension_ValueX{Choice: &fhirdatatypes.Extension_ValueX_Identifier{
+			Identifier: &fhirdatatypes.Identifier{
+				System: &fhirdatatypes.Uri{Value: mbiSystem},
+				Value:  &fhirdatatypes.String{Value: d.mbi},
+			},
+		}})
+	}
+	if d.resourceType != "" {
+		extension(ResourceTypeExtensionURL, &fhirdatatypes.Extension_ValueX{Choice: &fhirdatatypes.Extension_ValueX_Code{
+			Code: &fhirdatatypes.Code{Value: d.resourceType},
+		}})
+	}
+	if d.statusCode != 0 {
+		extension(StatusCodeExtensionURL, &fhirdatatypes.Extension_ValueX{Choice: &fhirdatatypes.Extension_ValueX_Integer{
+			Integer: &fhirdatatypes.Integer{Value: d.statusCode},
+		}})
+	}
+	if d.reason != "" || d.retryCount > 0 {
+		extension(RetryCountExtensionURL, &fhirdatatypes.Extension_ValueX{Choice: &fhirdatatypes.Extension_ValueX_Integer{
+			Integer: &fhirdatatypes.Integer{Value: d.retryCount},
+		}})
+	}
+}
+
+// errorFileMu serializes appends to error files, which may be written while beneficiary data is fetched concurrently
+var errorFileMu sync.Mutex
+
 func appendErrorToFile(ctx context.Context, fileUUID string,
 	code fhircodes.IssueTypeCode_Value,
-	detailsCode, detailsDisplay string, tempDir string) {
+	detailsCode, detailsDisplay string, details errorDetails, tempDir string) {
 	close := metrics.NewChild(ctx, "appendErrorToFile")
 	defer close()
 
 	logger := log.GetCtxLogger(ctx)
 	oo := responseutils.CreateOpOutcome(fhircodes.IssueSeverityCode_ERROR, code, detailsCode, detailsDisplay)
+	details.addTo(oo.Issue[0])
+
+	errorFileMu.Lock()
+	defer errorFileMu.Unlock()
 
 	fileName := fmt.Sprintf("%s/%s-error.ndjson", tempDir, fileUUID)
 	/* #nosec -- opening file defined by variable */
@@ -455,22 +786,32 @@ func appendErrorToFile(ctx context.Context, fileUUID string,
 	}
 }
 
-func fhirBundleToResourceNDJSON(ctx context.Context, w *bufio.Writer, b *fhirmodels.Bundle, jsonType, beneficiaryID, acoID, fileUUID string, tmpDir string) {
+// fhirBundleToResourceNDJSON writes the resources contained in the bundle to w and returns the number of resources written.
+func fhirBundleToResourceNDJSON(ctx context.Context, w *bufio.Writer, b *fhirmodels.Bundle, jobArgs models.JobEnqueueArgs, beneficiaryID, acoID, fileUUID string, tmpDir string) (count int64) {
 	close := metrics.NewChild(ctx, "fhirBundleToResourceNDJSON")
 	defer close()
 	defer w.Flush()
 	logger := log.GetCtxLogger(ctx)
+	jsonType := jobArgs.ResourceType
 	for _, entry := range b.Entries {
 		if entry["resource"] == nil {
 			continue
 		}
 
-		entryJSON, err := json.Marshal(entry["resource"])
+		resource := entry["resource"]
+		if len(jobArgs.Elements) > 0 {
+			if r, ok := resource.(map[string]interface{}); ok {
+				resource = resourcetypes.SubsetResource(r, jobArgs.ResourceType, jobArgs.Elements, jobArgs.BBBasePath)
+			}
+		}
+
+		entryJSON, err := json.Marshal(resource)
 		// This is unlikely to happen because we just unmarshalled this data a few lines above.
 		if err != nil {
 			logger.Error(err)
 			appendErrorToFile(ctx, fileUUID, fhircodes.IssueTypeCode_EXCEPTION,
-				responseutils.InternalErr, fmt.Sprintf("Error marshaling %s to JSON for beneficiary %s in ACO %s", jsonType, beneficiaryID, acoID), tmpDir)
+				responseutils.InternalErr, fmt.Sprintf("Error marshaling %s to JSON for beneficiary %s in ACO %s", jsonType, beneficiaryID, acoID),
+				errorDetails{resourceType: jsonType, category: ErrorCategoryProcessing}, tmpDir)
 			continue
 		}
 
@@ -478,9 +819,13 @@ func fhirBundleToResourceNDJSON(ctx context.Context, w *bufio.Writer, b *fhirmod
 		if err != nil {
 			logger.Error(err)
 			appendErrorToFile(ctx, fileUUID, fhircodes.IssueTypeCode_EXCEPTION,
-				responseutils.InternalErr, fmt.Sprintf("Error writing %s to file for beneficiary %s in ACO %s", jsonType, beneficiaryID, acoID), tmpDir)
+				responseutils.InternalErr, fmt.Sprintf("Error writing %s to file for beneficiary %s in ACO %s", jsonType, beneficiaryID, acoID),
+				errorDetails{resourceType: jsonType, category: ErrorCategoryProcessing}, tmpDir)
+			continue
 		}
+		count++
 	}
+	return count
 }
 
 func CheckJobCompleteAndCleanup(ctx context.Context, r repository.Repository, jobID uint) (jobCompleted bool, err error) {
@@ -500,7 +845,8 @@ func CheckJobCompleteAndCleanup(ctx context.Context, r repository.Repository, jo
 		return true, nil
 	}
 
-	completedCount, err := r.GetJobKeyCount(ctx, jobID)
+	// A queue job can create several job keys (one per file), so count the queue jobs that have completed
+	completedCount, err := r.GetUniqueJobKeyCount(ctx, jobID)
 	if err != nil {
 		err = errors.Wrap(err, fmt.Sprintf("Failed get job key count (Job %d)", jobID))
 		return false, err
@@ -5,15 +5,20 @@ import (
 	"bytes"
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/fs"
 	"math"
 	"math/big"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -27,9 +32,12 @@ import (
 	"github.com/stretchr/testify/suite"
 
 	"github.com/CMSgov/bcda-app/bcda/client"
+	"github.com/CMSgov/bcda-app/bcda/client/fhir"
 	"github.com/CMSgov/bcda-app/bcda/constants"
 	"github.com/CMSgov/bcda-app/bcda/database"
 	"github.com/CMSgov/bcda-app/bcda/models"
+	fhirmodels "github.com/CMSgov/bcda-app/bcda/models/fhir"
+	modelspostgres "github.com/CMSgov/bcda-app/bcda/models/postgres"
 	"github.com/CMSgov/bcda-app/bcda/models/postgres/postgrestest"
 	"github.com/CMSgov/bcda-app/bcda/testUtils"
 	"github.com/CMSgov/bcda-app/bcdaworker/repository"
@@ -87,6 +95,8 @@ func (s *WorkerTestSuite) SetupSuite() {
 	conf.SetEnv(s.T(), "BB_CLIENT_CERT_FILE", "../../shared_files/decrypted/bfd-dev-test-cert.pem")
 	conf.SetEnv(s.T(), "BB_CLIENT_KEY_FILE", "../../shared_files/decrypted/bfd-dev-test-key.pem")
 	conf.SetEnv(s.T(), "BB_CLIENT_CA_FILE", "../../shared_files/localhost.crt")
+	// Most tests expect Blue Button to be queried for every beneficiary
+	conf.SetEnv(s.T(), "BB_PATIENT_ID_CACHE_TTL_HOURS", "0")
 
 	// Set up the logger since we're using the real client
 	client.SetLogger(log.BBWorker)
@@ -193,7 +203,7 @@ func (s *WorkerTestSuite) TestGetBlueButtonID_NonHappyPaths() {
 
 	for _, tt := range tests {
 		mockCall := bbc.On("GetPatientByMbi", cclfBeneficiary.MBI).Return(tt.patientJSON, nil)
-		bbID, err := getBlueButtonID(bbc, beneficiaryID, jobArgs)
+		bbID, err := client.GetBlueButtonID(bbc, beneficiaryID, jobArgs)
 		if tt.err != nil {
 			assert.Error(s.T(), err)
 			assert.Equal(s.T(), fmt.Sprint(tt.err), fmt.Sprint(err))
@@ -204,6 +214,171 @@ func (s *WorkerTestSuite) TestGetBlueButtonID_NonHappyPaths() {
 	}
 }
 
+func (s *WorkerTestSuite) TestGetCachedBlueButtonID() {
+	defer conf.SetEnv(s.T(), "BB_PATIENT_ID_CACHE_TTL_HOURS", "0")
+	conf.SetEnv(s.T(), "BB_PATIENT_ID_CACHE_TTL_HOURS", "24")
+
+	mbi := "abcdef12000"
+	patientJSON := `{"entry":[{"resource":{"id":"abcdef12000","identifier":[{"system":"http://terminology.hl7.org/CodeSystem/v2-0203","value":"abcdef12000"}]}}]}`
+	jobArgs := models.JobEnqueueArgs{ID: s.jobID}
+
+	tests := []struct {
+		name      string
+		cachedID  string
+		cacheErr  error
+		saveErr   error
+		lookup    bool
+		cacheable bool
+	}{
+		{"Cache hit", "cached-id", nil, nil, false, false},
+		{"Cache miss", "", repository.ErrBlueButtonIDNotFound, nil, true, true},
+		{"Cache read error", "", errors.New("read error"), nil, true, true},
+		{"Cache write error", "", repository.ErrBlueButtonIDNotFound, errors.New("write error"), true, true},
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			r := &repository.MockRepository{}
+			r.On("GetBlueButtonID", testUtils.CtxMatcher, mbi, mock.MatchedBy(func(updatedAfter time.Time) bool {
+				return time.Since(updatedAfter) > 23*time.Hour && time.Since(updatedAfter) < 25*time.Hour
+			})).Return(tt.cachedID, tt.cacheErr)
+			if tt.cacheable {
+				r.On("SaveBlueButtonID", testUtils.CtxMatcher, mbi, mbi).Return(tt.saveErr)
+			}
+			bbc := &client.MockBlueButtonClient{}
+			if tt.lookup {
+				bbc.On("GetPatientByMbi", mbi).Return(patientJSON, nil)
+			}
+
+			bbID, err := GetCachedBlueButtonID(s.logctx, r, bbc, mbi, jobArgs)
+			assert.NoError(t, err)
+			if tt.lookup {
+				assert.Equal(t, mbi, bbID)
+			} else {
+				assert.Equal(t, tt.cachedID, bbID)
+			}
+			r.AssertExpectations(t)
+			bbc.AssertExpectations(t)
+		})
+	}
+
+	// A disabled cache always goes to Blue Button
+	conf.SetEnv(s.T(), "BB_PATIENT_ID_CACHE_TTL_HOURS", "0")
+	r := &repository.MockRepository{}
+	bbc := &client.MockBlueButtonClient{}
+	bbc.On("GetPatientByMbi", mbi).Return(patientJSON, nil)
+	bbID, err := GetCachedBlueButtonID(s.logctx, r, bbc, mbi, jobArgs)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), mbi, bbID)
+	r.AssertNotCalled(s.T(), "GetBlueButtonID")
+}
+
+func (s *WorkerTestSuite) TestGetCachedBlueButtonID_PreviousMBIs() {
+	defer conf.SetEnv(s.T(), "BB_PATIENT_ID_CACHE_TTL_HOURS", "0")
+	conf.SetEnv(s.T(), "BB_PATIENT_ID_CACHE_TTL_HOURS", "24")
+
+	const notFoundJSON = `{"entry":[]}`
+	patientJSON := func(mbi string) string {
+		return fmt.Sprintf(`{"entry":[{"resource":{"id":"bb-%s","identifier":[{"system":"http://terminology.hl7.org/CodeSystem/v2-0203","value":"%s"}]}}]}`, mbi, mbi)
+	}
+	jobArgs := models.JobEnqueueArgs{ID: s.jobID, CMSID: "A0001"}
+
+	tests := []struct {
+		name        string
+		previous    map[string][]string
+		previousErr error
+		patients    map[string]string
+		lookupErr   error
+		expectedID  string
+		expectedErr error
+	}{
+		{
+			name:     "Resolved by a previous MBI",
+			previous: map[string][]string{"MBI0": {"MBI1", "MBI2"}},
+			patients: map[string]string{"MBI1": notFoundJSON, "MBI2": patientJSON("MBI2")}, expectedID: "bb-MBI2",
+		},
+		{
+			name:     "Resolved by a chained previous MBI",
+			previous: map[string][]string{"MBI0": {"MBI1"}, "MBI1": {"MBI0", "MBI2"}},
+			patients: map[string]string{"MBI1": notFoundJSON, "MBI2": patientJSON("MBI2")}, expectedID: "bb-MBI2",
+		},
+		{
+			name:     "No previous MBIs",
+			previous: map[string][]string{"MBI0": nil}, expectedErr: client.ErrPatientNotFound,
+		},
+		{
+			name:     "Previous MBIs not found",
+			previous: map[string][]string{"MBI0": {"MBI1"}, "MBI1": nil},
+			patients: map[string]string{"MBI1": notFoundJSON}, expectedErr: client.ErrPatientNotFound,
+		},
+		{
+			name:        "Crosswalk read error",
+			previousErr: errors.New("read error"), expectedErr: client.ErrPatientNotFound,
+		},
+		{
+			name:      "Previous MBI lookup error",
+			previous:  map[string][]string{"MBI0": {"MBI1"}},
+			lookupErr: errors.New("lookup error"), expectedErr: errors.New("lookup error"),
+		},
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			r := &repository.MockRepository{}
+			r.On("GetBlueButtonID", testUtils.CtxMatcher, "MBI0", mock.Anything).Return("", repository.ErrBlueButtonIDNotFound)
+			if tt.previousErr != nil {
+				r.On("GetPreviousMBIs", testUtils.CtxMatcher, "A0001", "MBI0").Return(nil, tt.previousErr)
+			}
+			for mbi, previous := range tt.previous {
+				r.On("GetPreviousMBIs", testUtils.CtxMatcher, "A0001", mbi).Return(previous, nil)
+			}
+			if tt.expectedErr == nil {
+				// IDs resolved by a previous MBI are cached under the current MBI
+				r.On("SaveBlueButtonID", testUtils.CtxMatcher, "MBI0", tt.expectedID).Return(nil)
+			}
+
+			bbc := &client.MockBlueButtonClient{}
+			bbc.On("GetPatientByMbi", "MBI0").Return(notFoundJSON, nil)
+			for mbi, patient := range tt.patients {
+				bbc.On("GetPatientByMbi", mbi).Return(patient, nil)
+			}
+			if tt.lookupErr != nil {
+				bbc.On("GetPatientByMbi", "MBI1").Return("", tt.lookupErr)
+			}
+
+			bbID, err := GetCachedBlueButtonID(s.logctx, r, bbc, "MBI0", jobArgs)
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+				assert.Empty(t, bbID)
+				r.AssertNotCalled(t, "SaveBlueButtonID", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedID, bbID)
+			r.AssertExpectations(t)
+			bbc.AssertExpectations(t)
+		})
+	}
+}
+
+func (s *WorkerTestSuite) TestWarmBlueButtonIDCache() {
+	defer conf.SetEnv(s.T(), "BB_PATIENT_ID_CACHE_TTL_HOURS", "0")
+	conf.SetEnv(s.T(), "BB_PATIENT_ID_CACHE_TTL_HOURS", "24")
+
+	r := &repository.MockRepository{}
+	r.On("GetBlueButtonID", testUtils.CtxMatcher, "MBI1", mock.Anything).Return("cached-id", nil)
+	r.On("GetBlueButtonID", testUtils.CtxMatcher, mock.Anything, mock.Anything).Return("", repository.ErrBlueButtonIDNotFound)
+	r.On("SaveBlueButtonID", testUtils.CtxMatcher, "MBI2", "MBI2").Return(nil)
+	bbc := &client.MockBlueButtonClient{}
+	bbc.On("GetPatientByMbi", "MBI2").Return(`{"entry":[{"resource":{"id":"MBI2","identifier":[{"system":"http://terminology.hl7.org/CodeSystem/v2-0203","value":"MBI2"}]}}]}`, nil)
+	bbc.On("GetPatientByMbi", "MBI3").Return("", errors.New("No beneficiary found for MBI"))
+
+	warmed, failed := WarmBlueButtonIDCache(s.logctx, r, bbc, "A0001", []string{"MBI1", "MBI2", "MBI3"})
+	assert.Equal(s.T(), 2, warmed)
+	assert.Equal(s.T(), 1, failed)
+	r.AssertExpectations(s.T())
+}
+
 func (s *WorkerTestSuite) TestWriteResourcesToFile() {
 	tests := []struct {
 		resource      string
@@ -217,6 +392,7 @@ func (s *WorkerTestSuite) TestWriteResourcesToFile() {
 		{"Patient", 1, 1, 1, nil},
 		{"Claim", 1, 1, 1, nil},
 		{"ClaimResponse", 1, 1, 1, nil},
+		{"Observation", 1, 1, 2, nil},
 		{"UnsupportedResource", 1, 0, 0, errors.Errorf("unsupported resouce")},
 	}
 
@@ -236,6 +412,61 @@ func (s *WorkerTestSuite) TestWriteResourcesToFile() {
 	}
 }
 
+func (s *WorkerTestSuite) TestWriteResourcesToFileSharded() {
+	origMaxResources := conf.GetEnv("EXPORT_FILE_MAX_RESOURCES")
+	defer conf.SetEnv(s.T(), "EXPORT_FILE_MAX_RESOURCES", origMaxResources)
+	conf.SetEnv(s.T(), "EXPORT_FILE_MAX_RESOURCES", "10")
+
+	tests := []struct {
+		outputFormat string
+		extension    string
+	}{
+		{"", ".ndjson"},
+		{constants.ParquetOutputFormat, ".parquet"},
+	}
+
+	for _, tt := range tests {
+		ctx, jobArgs, bbc := SetupWriteResourceToFile(s, "ExplanationOfBenefit")
+		jobArgs.OutputFormat = tt.outputFormat
+		bbc.On("GetExplanationOfBenefit", jobArgs, mock.Anything, mock.Anything).Return(bbc.GetBundleData("ExplanationOfBenefit", "a1000050699"))
+
+		queJobID := cryptoRandInt63()
+		jobKeys, err := writeBBDataToFile(ctx, s.r, bbc, *s.testACO.CMSID, queJobID, jobArgs, s.tempDir)
+		assert.NoError(s.T(), err)
+
+		// 33 resources are split into files of at most 10 resources
+		assert.Len(s.T(), jobKeys, 4)
+		files, err := os.ReadDir(s.tempDir)
+		assert.NoError(s.T(), err)
+		assert.Len(s.T(), files, 4)
+
+		fileNames := make(map[string]struct{})
+		for i, jobKey := range jobKeys {
+			assert.Equal(s.T(), queJobID, *jobKey.QueJobID)
+			assert.Equal(s.T(), "ExplanationOfBenefit", jobKey.ResourceType)
+			assert.True(s.T(), strings.HasSuffix(jobKey.FileName, tt.extension), jobKey.FileName)
+			fileNames[jobKey.FileName] = struct{}{}
+
+			expectedCount := int64(10)
+			if i == len(jobKeys)-1 {
+				expectedCount = 3
+			}
+			assert.Equal(s.T(), expectedCount, jobKey.ResourceCount)
+		}
+		assert.Len(s.T(), fileNames, 4)
+
+		// Progress counts are only recorded on the first job key
+		assert.Equal(s.T(), int64(1), jobKeys[0].BeneficiaryCount)
+		for _, jobKey := range jobKeys[1:] {
+			assert.Zero(s.T(), jobKey.BeneficiaryCount)
+		}
+
+		for _, f := range files {
+			assert.NoError(s.T(), os.Remove(fmt.Sprintf("%s/%s", s.tempDir, f.Name())))
+		}
+	}
+}
+
 func SetupWriteResourceToFile(s *WorkerTestSuite, resource string) (context.Context, models.JobEnqueueArgs, *client.MockBlueButtonClient) {
 	bbc := client.MockBlueButtonClient{}
 	since, transactionTime := time.Now().Add(-24*time.Hour).Format(time.RFC3339Nano), time.Now()
@@ -267,6 +498,9 @@ func SetupWriteResourceToFile(s *WorkerTestSuite, resource string) (context.Cont
 	case "ClaimResponse":
 		bbc.On("GetPatientByMbi", cclfBeneficiary.MBI).Return(bbc.GetData("Patient", beneID))
 		bbc.On("GetClaimResponse", jobArgs, beneID, claimsWindowMatcher(claimsWindow.LowerBound, claimsWindow.UpperBound)).Return(bbc.GetBundleData("ClaimResponse", beneID))
+	case "Observation":
+		bbc.On("GetPatientByMbi", cclfBeneficiary.MBI).Return(bbc.GetData("Patient", beneID))
+		bbc.On("GetObservation", jobArgs, beneID, claimsWindowMatcher(claimsWindow.LowerBound, claimsWindow.UpperBound)).Return(bbc.GetBundleData("Observation", beneID))
 
 	}
 	return ctx, jobArgs, &bbc
@@ -347,23 +581,38 @@ func (s *WorkerTestSuite) TestWriteEOBDataToFileWithErrorsBelowFailureThreshold(
 	bbc.On("GetExplanationOfBenefit", jobArgs, "abcdef10000", claimsWindowMatcher()).Return(nil, errors.New("error"))
 	bbc.On("GetExplanationOfBenefit", jobArgs, "abcdef11000", claimsWindowMatcher()).Return(nil, errors.New("error"))
 	bbc.On("GetExplanationOfBenefit", jobArgs, "abcdef12000", claimsWindowMatcher()).Return(bbc.GetBundleData("ExplanationOfBenefit", "abcdef12000"))
-	jobKeys, err := writeBBDataToFile(s.logctx, s.r, &bbc, *s.testACO.CMSID, cryptoRandInt63(), jobArgs, s.tempDir)
+	queJobID := cryptoRandInt63()
+	jobKeys, err := writeBBDataToFile(s.logctx, s.r, &bbc, *s.testACO.CMSID, queJobID, jobArgs, s.tempDir)
 	assert.NotEqual(s.T(), "blank.ndjson", jobKeys[0].FileName)
 	assert.Contains(s.T(), jobKeys[1].FileName, "error.ndjson")
 	assert.Len(s.T(), jobKeys, 2)
 	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(3), jobKeys[0].BeneficiaryCount)
+	assert.Equal(s.T(), int64(2), jobKeys[0].ErrorCount)
+	assert.Zero(s.T(), jobKeys[1].BeneficiaryCount)
 	errorFilePath := fmt.Sprintf("%s/%s", s.tempDir, jobKeys[1].FileName)
 	fData, err := os.ReadFile(errorFilePath)
 	assert.NoError(s.T(), err)
 
-	ooResp := fmt.Sprintf(`{"resourceType":"OperationOutcome","issue":[{"severity":"error","code":"not-found","diagnostics":"Error retrieving ExplanationOfBenefit for beneficiary MBI abcdef10000 in ACO %s"}]}
-	{"resourceType":"OperationOutcome","issue":[{"severity":"error","code":"not-found","diagnostics":"Error retrieving ExplanationOfBenefit for beneficiary MBI abcdef11000 in ACO %s"}]}`, s.testACO.UUID, s.testACO.UUID)
+	ooResp := fmt.Sprintf(`{"resourceType":"OperationOutcome","issue":[{"severity":"error","code":"not-found","details":{"coding":[{"system":"https://bcda.cms.gov/fhir/CodeSystem/error-category","code":"bfd-request"}]},"extension":[{"url":"https://bcda.cms.gov/fhir/StructureDefinition/beneficiary","valueIdentifier":{"system":"http://hl7.org/fhir/sid/us-mbi","value":"abcdef10000"}},{"url":"https://bcda.cms.gov/fhir/StructureDefinition/resource-type","valueCode":"ExplanationOfBenefit"}],"diagnostics":"Error retrieving ExplanationOfBenefit for beneficiary MBI abcdef10000 in ACO %s"}]}
+	{"resourceType":"OperationOutcome","issue":[{"severity":"error","code":"not-found","details":{"coding":[{"system":"https://bcda.cms.gov/fhir/CodeSystem/error-category","code":"bfd-request"}]},"extension":[{"url":"https://bcda.cms.gov/fhir/StructureDefinition/beneficiary","valueIdentifier":{"system":"http://hl7.org/fhir/sid/us-mbi","value":"abcdef11000"}},{"url":"https://bcda.cms.gov/fhir/StructureDefinition/resource-type","valueCode":"ExplanationOfBenefit"}],"diagnostics":"Error retrieving ExplanationOfBenefit for beneficiary MBI abcdef11000 in ACO %s"}]}`, s.testACO.UUID, s.testACO.UUID)
 
 	// Since our error file ends with a new line character, we need
 	// to remove it in order so split OperationOutcome responses by newline character
 	fData = fData[:len(fData)-1]
 	assertEqualErrorFiles(s.T(), ooResp, string(fData))
 
+	// The failed beneficiaries are recorded with the original job arguments
+	jobID, err := safecast.ToUint(s.jobID)
+	assert.NoError(s.T(), err)
+	failed, err := modelspostgres.NewRepository(s.db).GetFailedQueueJobs(context.Background(), jobID)
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), failed, 1)
+	assert.Equal(s.T(), queJobID, failed[0].QueJobID)
+	assert.Equal(s.T(), cclfBeneficiaryIDs[0:2], failed[0].Args.BeneficiaryIDs)
+	assert.Equal(s.T(), jobArgs.ResourceType, failed[0].Args.ResourceType)
+	assert.True(s.T(), jobArgs.TransactionTime.Equal(failed[0].Args.TransactionTime))
+
 	bbc.AssertExpectations(s.T())
 }
 
@@ -371,6 +620,9 @@ func (s *WorkerTestSuite) TestWriteEOBDataToFileWithErrorsAboveFailureThreshold(
 	origFailPct := conf.GetEnv("EXPORT_FAIL_PCT")
 	defer conf.SetEnv(s.T(), "EXPORT_FAIL_PCT", origFailPct)
 	conf.SetEnv(s.T(), "EXPORT_FAIL_PCT", "60")
+	// Fetch beneficiaries sequentially so that the third beneficiary is never requested
+	defer conf.UnsetEnv(s.T(), "EXPORT_BENE_CONCURRENCY")
+	conf.SetEnv(s.T(), "EXPORT_BENE_CONCURRENCY", "1")
 	transactionTime := time.Now()
 
 	var cclfBeneficiaryIDs []string
@@ -408,8 +660,8 @@ func (s *WorkerTestSuite) TestWriteEOBDataToFileWithErrorsAboveFailureThreshold(
 	errorFilePath := fmt.Sprintf("%s/%s", s.tempDir, files[0].Name())
 	fData, err := os.ReadFile(errorFilePath)
 	assert.NoError(s.T(), err)
-	ooResp := fmt.Sprintf(`{"resourceType":"OperationOutcome","issue":[{"severity":"error","code":"not-found","diagnostics":"Error retrieving ExplanationOfBenefit for beneficiary MBI a1000089833 in ACO %s"}]}
-	{"resourceType":"OperationOutcome","issue":[{"severity":"error","code":"not-found","diagnostics":"Error retrieving ExplanationOfBenefit for beneficiary MBI a1000065301 in ACO %s"}]}`, s.testACO.UUID, s.testACO.UUID)
+	ooResp := fmt.Sprintf(`{"resourceType":"OperationOutcome","issue":[{"severity":"error","code":"not-found","details":{"coding":[{"system":"https://bcda.cms.gov/fhir/CodeSystem/error-category","code":"bfd-request"}]},"extension":[{"url":"https://bcda.cms.gov/fhir/StructureDefinition/beneficiary","valueIdentifier":{"system":"http://hl7.org/fhir/sid/us-mbi","value":"a1000089833"}},{"url":"https://bcda.cms.gov/fhir/StructureDefinition/resource-type","valueCode":"ExplanationOfBenefit"}],"diagnostics":"Error retrieving ExplanationOfBenefit for beneficiary MBI a1000089833 in ACO %s"}]}
+	{"resourceType":"OperationOutcome","issue":[{"severity":"error","code":"not-found","details":{"coding":[{"system":"https://bcda.cms.gov/fhir/CodeSystem/error-category","code":"bfd-request"}]},"extension":[{"url":"https://bcda.cms.gov/fhir/StructureDefinition/beneficiary","valueIdentifier":{"system":"http://hl7.org/fhir/sid/us-mbi","value":"a1000065301"}},{"url":"https://bcda.cms.gov/fhir/StructureDefinition/resource-type","valueCode":"ExplanationOfBenefit"}],"diagnostics":"Error retrieving ExplanationOfBenefit for beneficiary MBI a1000065301 in ACO %s"}]}`, s.testACO.UUID, s.testACO.UUID)
 
 	// Since our error file ends with a new line character, we need
 	// to remove it in order so split OperationOutcome responses by newline character
@@ -422,6 +674,212 @@ func (s *WorkerTestSuite) TestWriteEOBDataToFileWithErrorsAboveFailureThreshold(
 	bbc.AssertNotCalled(s.T(), "GetExplanationOfBenefit", jobArgs, beneficiaryIDs[2], claimsWindowMatcher())
 }
 
+func (s *WorkerTestSuite) TestWriteEOBDataToFileWithCircuitOpen() {
+	defer conf.UnsetEnv(s.T(), "EXPORT_BENE_CONCURRENCY")
+	conf.SetEnv(s.T(), "EXPORT_BENE_CONCURRENCY", "1")
+
+	var cclfBeneficiaryIDs []string
+	beneficiaryIDs := []string{"a1000089833", "a1000065301"}
+	for _, beneficiaryID := range beneficiaryIDs {
+		cclfBeneficiary := models.CCLFBeneficiary{FileID: s.cclfFile.ID, MBI: beneficiaryID, BlueButtonID: beneficiaryID}
+		postgrestest.CreateCCLFBeneficiary(s.T(), s.db, &cclfBeneficiary)
+		cclfBeneficiaryIDs = append(cclfBeneficiaryIDs, strconv.FormatUint(uint64(cclfBeneficiary.ID), 10))
+	}
+
+	jobArgs := models.JobEnqueueArgs{ID: s.jobID, ResourceType: "ExplanationOfBenefit", BeneficiaryIDs: cclfBeneficiaryIDs, TransactionTime: time.Now(), ACOID: s.testACO.UUID.String()}
+	bbc := client.MockBlueButtonClient{}
+	bbc.On("GetExplanationOfBenefit", jobArgs, beneficiaryIDs[0], claimsWindowMatcher()).Return(nil, client.ErrCircuitOpen)
+	bbc.MBI = &beneficiaryIDs[0]
+	bbc.On("GetPatientByMbi", beneficiaryIDs[0]).Return(bbc.GetData("Patient", beneficiaryIDs[0]))
+
+	err := createDir(s.tempDir)
+	assert.NoError(s.T(), err)
+	_, err = writeBBDataToFile(s.logctx, s.r, &bbc, *s.testACO.CMSID, cryptoRandInt63(), jobArgs, s.tempDir)
+	assert.ErrorIs(s.T(), err, client.ErrCircuitOpen)
+
+	// The beneficiary is not reported as an error since the queue job is retried
+	files, err := os.ReadDir(s.tempDir)
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), files, 1)
+	bbc.AssertNotCalled(s.T(), "GetExplanationOfBenefit", jobArgs, beneficiaryIDs[1], claimsWindowMatcher())
+}
+
+func (s *WorkerTestSuite) TestFetchBeneficiaryDataOrder() {
+	defer conf.UnsetEnv(s.T(), "EXPORT_BENE_CONCURRENCY")
+	conf.SetEnv(s.T(), "EXPORT_BENE_CONCURRENCY", "3")
+
+	r := &repository.MockRepository{}
+	var beneIDs []string
+	for i := 1; i <= 10; i++ {
+		id, _ := safecast.ToUint(i)
+		r.On("GetCCLFBeneficiaryByID", testUtils.CtxMatcher, id).Return(&models.CCLFBeneficiary{ID: id, MBI: fmt.Sprintf("MBI%d", i)}, nil)
+		beneIDs = append(beneIDs, strconv.Itoa(i))
+	}
+	beneIDs = append(beneIDs, "invalid")
+
+	var (
+		mu       sync.Mutex
+		inFlight int
+		maxSeen  int
+	)
+	pageFunc := func(bene models.CCLFBeneficiary, handle client.PageHandler) error {
+		mu.Lock()
+		inFlight++
+		maxSeen = max(maxSeen, inFlight)
+		mu.Unlock()
+
+		// Later beneficiaries respond first to verify that results are still returned in order
+		time.Sleep(time.Duration(20-bene.ID) * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		switch bene.ID {
+		case 5:
+			return errors.New("error")
+		case 7:
+			return &client.RequestError{Attempts: 3, Err: &fhir.ResponseError{StatusCode: http.StatusTooManyRequests}}
+		}
+
+		// Each beneficiary's resources are returned across two pages
+		for _, id := range []string{bene.MBI, bene.MBI + "-2"} {
+			if err := handle(&fhirmodels.Bundle{Entries: []fhirmodels.BundleEntry{{"resource": map[string]interface{}{"id": id}}}}); err != nil {
+				return err
+			}
+			// A failure after the first page discards the beneficiary's resources
+			if bene.ID == 9 {
+				return errors.New("page error")
+			}
+		}
+		return nil
+	}
+	writePage := func(w *bufio.Writer, beneID string, page *fhirmodels.Bundle) int64 {
+		for _, entry := range page.Entries {
+			fmt.Fprintln(w, entry["resource"].(map[string]interface{})["id"])
+		}
+		return int64(len(page.Entries))
+	}
+
+	spillDir := s.T().TempDir()
+	jobArgs := models.JobEnqueueArgs{ResourceType: "Claim", DataType: constants.PartiallyAdjudicated, BeneficiaryIDs: beneIDs}
+	results, stop := fetchBeneficiaryData(context.Background(), r, &client.MockBlueButtonClient{}, pageFunc, writePage, jobArgs, spillDir)
+	defer stop()
+
+	var order []string
+	for result := range results {
+		res := <-result
+		order = append(order, res.beneID)
+		switch res.beneID {
+		case "5":
+			assert.EqualError(s.T(), res.err, "error")
+			assert.Equal(s.T(), "Error retrieving Claim for beneficiary MBI MBI5 in ACO ", res.fileErrMsg)
+			assert.Empty(s.T(), res.details.reason)
+			assert.Equal(s.T(), errorDetails{mbi: "MBI5", resourceType: "Claim", category: ErrorCategoryRequest}, res.details)
+		case "7":
+			assert.Error(s.T(), res.err)
+			assert.Equal(s.T(), fhir.ReasonRateLimited, res.details.reason)
+			assert.EqualValues(s.T(), http.StatusTooManyRequests, res.details.statusCode)
+			assert.EqualValues(s.T(), 2, res.details.retryCount)
+		case "9":
+			assert.EqualError(s.T(), res.err, "page error")
+			assert.Nil(s.T(), res.spill)
+		case "invalid":
+			assert.Error(s.T(), res.err)
+			assert.Equal(s.T(), fhircodes.IssueTypeCode_EXCEPTION, res.code)
+		default:
+			assert.NoError(s.T(), res.err)
+			assert.Equal(s.T(), int64(2), res.count)
+			var buf bytes.Buffer
+			assert.NoError(s.T(), res.writeTo(&buf))
+			assert.Equal(s.T(), fmt.Sprintf("MBI%[1]s\nMBI%[1]s-2\n", res.beneID), buf.String())
+		}
+	}
+
+	// Spill files are removed once they have been written or the beneficiary failed
+	spills, err := os.ReadDir(spillDir)
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), spills)
+	assert.Equal(s.T(), beneIDs, order)
+	assert.LessOrEqual(s.T(), maxSeen, 3)
+	assert.Greater(s.T(), maxSeen, 1)
+}
+
+func (s *WorkerTestSuite) TestFetchBeneficiaryDataStop() {
+	defer conf.UnsetEnv(s.T(), "EXPORT_BENE_CONCURRENCY")
+	conf.SetEnv(s.T(), "EXPORT_BENE_CONCURRENCY", "2")
+
+	r := &repository.MockRepository{}
+	var beneIDs []string
+	for i := 1; i <= 10; i++ {
+		id, _ := safecast.ToUint(i)
+		r.On("GetCCLFBeneficiaryByID", testUtils.CtxMatcher, id).Return(&models.CCLFBeneficiary{ID: id}, nil)
+		beneIDs = append(beneIDs, strconv.Itoa(i))
+	}
+
+	var fetched atomic.Int32
+	pageFunc := func(bene models.CCLFBeneficiary, handle client.PageHandler) error {
+		fetched.Add(1)
+		return handle(&fhirmodels.Bundle{})
+	}
+	writePage := func(w *bufio.Writer, beneID string, page *fhirmodels.Bundle) int64 {
+		return 0
+	}
+
+	spillDir := s.T().TempDir()
+	jobArgs := models.JobEnqueueArgs{ResourceType: "Claim", DataType: constants.PartiallyAdjudicated, BeneficiaryIDs: beneIDs}
+	results, stop := fetchBeneficiaryData(context.Background(), r, &client.MockBlueButtonClient{}, pageFunc, writePage, jobArgs, spillDir)
+	(<-<-results).discard()
+	stop()
+
+	// Only the beneficiaries fetched ahead of the first result may have been requested
+	assert.LessOrEqual(s.T(), fetched.Load(), int32(3))
+
+	// The spill files of the results that were never written are removed
+	spills, err := os.ReadDir(spillDir)
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), spills)
+}
+
+func (s *WorkerTestSuite) TestFetchBeneficiaryDataCancelled() {
+	r := &repository.MockRepository{}
+	var beneIDs []string
+	for i := 1; i <= 10; i++ {
+		id, _ := safecast.ToUint(i)
+		r.On("GetCCLFBeneficiaryByID", testUtils.CtxMatcher, id).Return(&models.CCLFBeneficiary{ID: id}, nil)
+		beneIDs = append(beneIDs, strconv.Itoa(i))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var fetched atomic.Int32
+	pageFunc := func(bene models.CCLFBeneficiary, handle client.PageHandler) error {
+		// The parent job is cancelled while the first beneficiary is being fetched
+		if fetched.Add(1) == 1 {
+			cancel()
+		}
+		return handle(&fhirmodels.Bundle{})
+	}
+	writePage := func(w *bufio.Writer, beneID string, page *fhirmodels.Bundle) int64 {
+		return 0
+	}
+
+	jobArgs := models.JobEnqueueArgs{ResourceType: "Claim", DataType: constants.PartiallyAdjudicated, BeneficiaryIDs: beneIDs}
+	results, stop := fetchBeneficiaryData(ctx, r, &client.MockBlueButtonClient{}, pageFunc, writePage, jobArgs, s.T().TempDir())
+	defer stop()
+
+	var last beneficiaryResult
+	var count int
+	for result := range results {
+		last = <-result
+		last.discard()
+		count++
+	}
+
+	// Only the beneficiaries started before the cancellation (at most EXPORT_BENE_CONCURRENCY) are fetched
+	assert.Less(s.T(), count, len(beneIDs))
+	assert.LessOrEqual(s.T(), fetched.Load(), int32(4))
+	assert.ErrorIs(s.T(), last.err, context.Canceled)
+}
+
 func (s *WorkerTestSuite) TestWriteEOBDataToFile_BlueButtonIDNotFound() {
 	origFailPct := conf.GetEnv("EXPORT_FAIL_PCT")
 	defer conf.SetEnv(s.T(), "EXPORT_FAIL_PCT", origFailPct)
@@ -503,24 +961,75 @@ func (s *WorkerTestSuite) TestGetFailureThreshold() {
 }
 
 func (s *WorkerTestSuite) TestAppendErrorToFile() {
+	details := errorDetails{
+		mbi:          "1SA0A00AA00",
+		resourceType: "ExplanationOfBenefit",
+		category:     ErrorCategoryRequest,
+		reason:       fhir.ReasonRateLimited,
+		statusCode:   http.StatusTooManyRequests,
+		retryCount:   2,
+	}
 	appendErrorToFile(s.logctx, s.testACO.UUID.String(),
 		fhircodes.IssueTypeCode_CODE_INVALID,
-		"", "", s.tempDir)
+		"", "", details, s.tempDir)
+	appendErrorToFile(s.logctx, s.testACO.UUID.String(),
+		fhircodes.IssueTypeCode_PROCESSING,
+		"", "", errorDetails{resourceType: "ExplanationOfBenefit", category: ErrorCategoryCancelled}, s.tempDir)
 
 	filePath := fmt.Sprintf("%s/%s-error.ndjson", s.tempDir, s.testACO.UUID)
 	fData, err := os.ReadFile(filePath)
 	assert.NoError(s.T(), err)
 
+	type coding struct {
+		System string `json:"system"`
+		Code   string `json:"code"`
+	}
 	type oo struct {
 		ResourceType string `json:"resourceType"`
 		Issues       []struct {
-			Severity string `json:"severity"`
+			Severity  string `json:"severity"`
+			Extension []struct {
+				URL             string `json:"url"`
+				ValueIdentifier *struct {
+					System string `json:"system"`
+					Value  string `json:"value"`
+				} `json:"valueIdentifier"`
+				ValueCode    string `json:"valueCode"`
+				ValueInteger *int   `json:"valueInteger"`
+			} `json:"extension"`
+			Details struct {
+				Coding []coding `json:"coding"`
+			} `json:"details"`
 		} `json:"issue"`
 	}
+
+	lines := strings.Split(strings.TrimSpace(string(fData)), "\n")
+	assert.Len(s.T(), lines, 2)
+
 	var obj oo
-	assert.NoError(s.T(), json.Unmarshal(fData, &obj))
+	assert.NoError(s.T(), json.Unmarshal([]byte(lines[0]), &obj))
 	assert.Equal(s.T(), "OperationOutcome", obj.ResourceType)
 	assert.Equal(s.T(), "error", obj.Issues[0].Severity)
+	assert.Equal(s.T(), []coding{{FailureReasonSystem, fhir.ReasonRateLimited}, {ErrorCategorySystem, ErrorCategoryRequest}}, obj.Issues[0].Details.Coding)
+
+	extensions := obj.Issues[0].Extension
+	assert.Len(s.T(), extensions, 4)
+	assert.Equal(s.T(), BeneficiaryExtensionURL, extensions[0].URL)
+	assert.Equal(s.T(), "http://hl7.org/fhir/sid/us-mbi", extensions[0].ValueIdentifier.System)
+	assert.Equal(s.T(), "1SA0A00AA00", extensions[0].ValueIdentifier.Value)
+	assert.Equal(s.T(), ResourceTypeExtensionURL, extensions[1].URL)
+	assert.Equal(s.T(), "ExplanationOfBenefit", extensions[1].ValueCode)
+	assert.Equal(s.T(), StatusCodeExtensionURL, extensions[2].URL)
+	assert.Equal(s.T(), http.StatusTooManyRequests, *extensions[2].ValueInteger)
+	assert.Equal(s.T(), RetryCountExtensionURL, extensions[3].URL)
+	assert.Equal(s.T(), 2, *extensions[3].ValueInteger)
+
+	// Details that do not apply are omitted
+	obj = oo{}
+	assert.NoError(s.T(), json.Unmarshal([]byte(lines[1]), &obj))
+	assert.Equal(s.T(), []coding{{ErrorCategorySystem, ErrorCategoryCancelled}}, obj.Issues[0].Details.Coding)
+	assert.Len(s.T(), obj.Issues[0].Extension, 1)
+	assert.Equal(s.T(), ResourceTypeExtensionURL, obj.Issues[0].Extension[0].URL)
 
 	os.Remove(filePath)
 }
@@ -645,24 +1154,24 @@ func (s *WorkerTestSuite) TestCompressFilesGzipLevel() {
 	}
 
 	os.Setenv("COMPRESSION_LEVEL", "potato")
-	err = compressFiles(s.logctx, tempDir1, tempDir2)
+	_, err = compressFiles(s.logctx, tempDir1, tempDir2)
 	assert.NoError(s.T(), err)
 
 	os.Setenv("COMPRESSION_LEVEL", "1")
-	err = compressFiles(s.logctx, tempDir1, tempDir2)
+	_, err = compressFiles(s.logctx, tempDir1, tempDir2)
 	assert.NoError(s.T(), err)
 
 	os.Setenv("COMPRESSION_LEVEL", "11")
-	err = compressFiles(s.logctx, tempDir1, tempDir2)
+	_, err = compressFiles(s.logctx, tempDir1, tempDir2)
 	assert.NoError(s.T(), err)
 
 }
 
 func (s *WorkerTestSuite) TestCompressFiles() {
 	//negative cases.
-	err := compressFiles(s.logctx, "/", "fake_dir")
+	_, err := compressFiles(s.logctx, "/", "fake_dir")
 	assert.Error(s.T(), err)
-	err = compressFiles(s.logctx, "/proc/fakedir", "fake_dir")
+	_, err = compressFiles(s.logctx, "/proc/fakedir", "fake_dir")
 	assert.Error(s.T(), err)
 
 	//positive case, create two temporary directories + a file, and move a file between them.
@@ -678,19 +1187,45 @@ func (s *WorkerTestSuite) TestCompressFiles() {
 	if err != nil {
 		s.FailNow(err.Error())
 	}
-	err = compressFiles(s.logctx, tempDir1, tempDir2)
+	digests, err := compressFiles(s.logctx, tempDir1, tempDir2)
 	assert.NoError(s.T(), err)
 	files, _ := os.ReadDir(tempDir2)
 	assert.Len(s.T(), files, 1)
+
+	// digest should match the compressed file
+	compressed, err := os.ReadFile(fmt.Sprintf("%s/%s", tempDir2, files[0].Name()))
+	assert.NoError(s.T(), err)
+	expectedDigest := sha256.Sum256(compressed)
+	assert.Equal(s.T(), map[string]string{files[0].Name(): hex.EncodeToString(expectedDigest[:])}, digests)
 	files, _ = os.ReadDir(tempDir1)
 	assert.Len(s.T(), files, 1)
 
 	//One more negative case, when the destination is not able to be moved.
-	err = compressFiles(s.logctx, tempDir2, "/proc/fakedir")
+	_, err = compressFiles(s.logctx, tempDir2, "/proc/fakedir")
 	assert.Error(s.T(), err)
 
 }
 
+func (s *WorkerTestSuite) TestSetFileSizes() {
+	tempDir := s.T().TempDir()
+	stagingDir := s.T().TempDir()
+
+	assert.NoError(s.T(), os.WriteFile(fmt.Sprintf("%s/%s", tempDir, "data.ndjson"), make([]byte, 100), 0600))
+	_, err := compressFiles(s.logctx, tempDir, stagingDir)
+	assert.NoError(s.T(), err)
+
+	jobKeys := []models.JobKey{{FileName: "data.ndjson"}, {FileName: models.BlankFileName}}
+	assert.NoError(s.T(), setFileSizes(jobKeys, tempDir, stagingDir))
+	assert.Equal(s.T(), int64(100), jobKeys[0].UncompressedSize)
+	compressed, err := os.Stat(fmt.Sprintf("%s/%s", stagingDir, "data.ndjson"))
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), compressed.Size(), jobKeys[0].CompressedSize)
+	assert.Zero(s.T(), jobKeys[1].UncompressedSize)
+
+	// Missing file
+	assert.Error(s.T(), setFileSizes([]models.JobKey{{FileName: "missing.ndjson"}}, tempDir, stagingDir))
+}
+
 func (s *WorkerTestSuite) TestProcessJob_NoBBClient() {
 	j := models.Job{
 		ACOID:      uuid.Parse(constants.TestACOID),
@@ -867,7 +1402,7 @@ func (s *WorkerTestSuite) TestCheckJobCompleteAndCleanup() {
 
 			// A job previously marked as a terminal status (Completed, Cancelled, or Failed) will bypass all of these calls
 			if !isTerminalStatus(tt.status) {
-				repository.On("GetJobKeyCount", testUtils.CtxMatcher, jobID).Return(tt.jobKeys, nil)
+				repository.On("GetUniqueJobKeyCount", testUtils.CtxMatcher, jobID).Return(tt.jobKeys, nil)
 				if tt.completed {
 					repository.On("UpdateJobStatus", testUtils.CtxMatcher, j.ID, models.JobStatusCompleted).
 						Return(nil)
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE public.job_keys DROP COLUMN IF EXISTS resource_count;
+ALTER TABLE public.job_keys DROP COLUMN IF EXISTS uncompressed_size;
+ALTER TABLE public.job_keys DROP COLUMN IF EXISTS compressed_size;
+
+COMMIT;
@@ -0,0 +1,9 @@
+-- Add resource count and file sizes to job_keys table
+
+BEGIN;
+
+ALTER TABLE public.job_keys ADD COLUMN resource_count bigint DEFAULT null;
+ALTER TABLE public.job_keys ADD COLUMN uncompressed_size bigint DEFAULT null;
+ALTER TABLE public.job_keys ADD COLUMN compressed_size bigint DEFAULT null;
+
+COMMIT;
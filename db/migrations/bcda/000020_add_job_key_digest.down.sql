@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE public.job_keys DROP COLUMN IF EXISTS digest;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- Add SHA-256 digest of the compressed file to job_keys table
+
+BEGIN;
+
+ALTER TABLE public.job_keys ADD COLUMN digest varchar(64) DEFAULT null;
+
+COMMIT;
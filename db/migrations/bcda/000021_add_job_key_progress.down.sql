@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE public.job_keys DROP COLUMN IF EXISTS beneficiary_count;
+ALTER TABLE public.job_keys DROP COLUMN IF EXISTS error_count;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- Add beneficiary and error counts to job_keys table
+
+BEGIN;
+
+ALTER TABLE public.job_keys ADD COLUMN beneficiary_count bigint DEFAULT null;
+ALTER TABLE public.job_keys ADD COLUMN error_count bigint DEFAULT null;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.circuit_breakers;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,12 @@
+-- Shares the state of circuit breakers across the api and worker processes
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.circuit_breakers (
+    name text PRIMARY KEY,
+    state text NOT NULL,
+    opened_at timestamp with time zone,
+    updated_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+COMMIT;
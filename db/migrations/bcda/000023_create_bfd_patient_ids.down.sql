@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.bfd_patient_ids;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- Caches the BFD patient ID resolved for each MBI so queue jobs can skip the Patient lookup
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.bfd_patient_ids (
+    mbi text PRIMARY KEY,
+    blue_button_id text NOT NULL,
+    updated_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+COMMIT;
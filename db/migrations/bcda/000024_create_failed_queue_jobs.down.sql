@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.failed_queue_jobs;
+
+COMMIT;
\ No newline at end of file
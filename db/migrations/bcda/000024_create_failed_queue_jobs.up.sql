@@ -0,0 +1,14 @@
+-- Records the beneficiaries that each queue job failed to export so that they can be exported again by a retry job
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.failed_queue_jobs (
+    job_id integer NOT NULL,
+    que_job_id bigint NOT NULL,
+    resource_type text NOT NULL,
+    args jsonb NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (job_id, que_job_id)
+);
+
+COMMIT;
@@ -0,0 +1,16 @@
+-- Records the attribution records that were not imported because their MBI is invalid
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.cclf_quarantined_beneficiaries (
+    id serial PRIMARY KEY,
+    file_id integer NOT NULL REFERENCES public.cclf_files(id) ON DELETE CASCADE,
+    record_number integer NOT NULL,
+    mbi text NOT NULL,
+    reason text NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cclf_quarantined_beneficiaries_file_id ON public.cclf_quarantined_beneficiaries USING btree (file_id);
+
+COMMIT;
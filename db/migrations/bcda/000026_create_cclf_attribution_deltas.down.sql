@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.cclf_attribution_delta_beneficiaries;
+DROP TABLE IF EXISTS public.cclf_attribution_deltas;
+
+COMMIT;
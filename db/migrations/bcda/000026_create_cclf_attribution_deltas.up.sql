@@ -0,0 +1,24 @@
+-- Records how the beneficiaries attributed to an ACO changed between consecutive CCLF8 files
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.cclf_attribution_deltas (
+    id serial PRIMARY KEY,
+    file_id integer NOT NULL UNIQUE REFERENCES public.cclf_files(id) ON DELETE CASCADE,
+    previous_file_id integer REFERENCES public.cclf_files(id) ON DELETE SET NULL,
+    added_count integer NOT NULL,
+    removed_count integer NOT NULL,
+    retained_count integer NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS public.cclf_attribution_delta_beneficiaries (
+    id serial PRIMARY KEY,
+    delta_id integer NOT NULL REFERENCES public.cclf_attribution_deltas(id) ON DELETE CASCADE,
+    mbi character(11) NOT NULL,
+    change text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_cclf_attribution_delta_beneficiaries_delta_id ON public.cclf_attribution_delta_beneficiaries USING btree (delta_id);
+
+COMMIT;
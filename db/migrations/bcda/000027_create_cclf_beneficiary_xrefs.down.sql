@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.cclf_beneficiary_xrefs;
+
+COMMIT;
@@ -0,0 +1,19 @@
+-- Stores the HICN/MBI crosswalk records delivered in CCLF9 files
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.cclf_beneficiary_xrefs (
+    id serial PRIMARY KEY,
+    file_id integer NOT NULL REFERENCES public.cclf_files(id) ON DELETE CASCADE,
+    xref_indicator character(1) NOT NULL,
+    current_num character(11) NOT NULL,
+    prev_num character(11) NOT NULL,
+    prvs_efct_dt date,
+    prvs_obslt_dt date,
+    created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cclf_beneficiary_xrefs_file_id ON public.cclf_beneficiary_xrefs USING btree (file_id);
+CREATE INDEX IF NOT EXISTS idx_cclf_beneficiary_xrefs_current_num ON public.cclf_beneficiary_xrefs USING btree (current_num);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE public.jobs DROP COLUMN IF EXISTS request_method;
+
+COMMIT;
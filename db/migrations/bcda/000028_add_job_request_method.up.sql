@@ -0,0 +1,7 @@
+-- Record the HTTP method used to request the export so that POST $export jobs are reported accurately
+
+BEGIN;
+
+ALTER TABLE public.jobs ADD COLUMN IF NOT EXISTS request_method text NOT NULL DEFAULT 'GET';
+
+COMMIT;
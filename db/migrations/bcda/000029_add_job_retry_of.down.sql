@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_jobs_retry_of_active;
+
+ALTER TABLE public.jobs DROP COLUMN IF EXISTS retry_of;
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- Record the job whose failed beneficiaries are exported by a retry job. Only one retry of a job
+-- may be Pending or In Progress at a time.
+
+BEGIN;
+
+ALTER TABLE public.jobs ADD COLUMN IF NOT EXISTS retry_of integer REFERENCES public.jobs(id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_retry_of_active ON public.jobs USING btree (retry_of)
+    WHERE status IN ('Pending', 'In Progress');
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS idx_cclf_beneficiary_xrefs_current_num ON public.cclf_beneficiary_xrefs USING btree (current_num);
+
+DROP INDEX IF EXISTS idx_cclf_beneficiary_xrefs_indicator_current_num;
+
+COMMIT;
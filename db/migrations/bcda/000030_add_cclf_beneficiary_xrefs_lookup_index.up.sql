@@ -0,0 +1,9 @@
+-- Previous MBIs are looked up by crosswalk indicator and current MBI, which replaces the need for the current_num index
+
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS idx_cclf_beneficiary_xrefs_indicator_current_num ON public.cclf_beneficiary_xrefs USING btree (xref_indicator, current_num);
+
+DROP INDEX IF EXISTS idx_cclf_beneficiary_xrefs_current_num;
+
+COMMIT;
@@ -356,9 +356,205 @@ func (s *MigrationTestSuite) TestBCDAMigration() {
 				assertColumnExists(t, false, db, "jobs", "completed_job_count")
 			},
 		},
+		{
+			"Adding resource_count and file sizes to job_keys table",
+			func(t *testing.T) {
+				assertColumnExists(t, false, db, "job_keys", "resource_count")
+				assertColumnExists(t, false, db, "job_keys", "uncompressed_size")
+				assertColumnExists(t, false, db, "job_keys", "compressed_size")
+				migrator.runMigration(t, 19)
+				assertColumnExists(t, true, db, "job_keys", "resource_count")
+				assertColumnExists(t, true, db, "job_keys", "uncompressed_size")
+				assertColumnExists(t, true, db, "job_keys", "compressed_size")
+			},
+		},
+		{
+			"Adding digest to job_keys table",
+			func(t *testing.T) {
+				assertColumnExists(t, false, db, "job_keys", "digest")
+				migrator.runMigration(t, 20)
+				assertColumnExists(t, true, db, "job_keys", "digest")
+			},
+		},
+		{
+			"Adding beneficiary_count and error_count to job_keys table",
+			func(t *testing.T) {
+				assertColumnExists(t, false, db, "job_keys", "beneficiary_count")
+				assertColumnExists(t, false, db, "job_keys", "error_count")
+				migrator.runMigration(t, 21)
+				assertColumnExists(t, true, db, "job_keys", "beneficiary_count")
+				assertColumnExists(t, true, db, "job_keys", "error_count")
+			},
+		},
+		{
+			"Creating circuit_breakers table",
+			func(t *testing.T) {
+				assertTableExists(t, false, db, "circuit_breakers")
+				migrator.runMigration(t, 22)
+				assertTableExists(t, true, db, "circuit_breakers")
+			},
+		},
+		{
+			"Creating bfd_patient_ids table",
+			func(t *testing.T) {
+				assertTableExists(t, false, db, "bfd_patient_ids")
+				migrator.runMigration(t, 23)
+				assertTableExists(t, true, db, "bfd_patient_ids")
+			},
+		},
+		{
+			"Creating failed_queue_jobs table",
+			func(t *testing.T) {
+				assertTableExists(t, false, db, "failed_queue_jobs")
+				migrator.runMigration(t, 24)
+				assertTableExists(t, true, db, "failed_queue_jobs")
+			},
+		},
+		{
+			"Creating cclf_quarantined_beneficiaries table",
+			func(t *testing.T) {
+				assertTableExists(t, false, db, "cclf_quarantined_beneficiaries")
+				migrator.runMigration(t, 25)
+				assertTableExists(t, true, db, "cclf_quarantined_beneficiaries")
+			},
+		},
+		{
+			"Creating cclf_attribution_deltas and cclf_attribution_delta_beneficiaries tables",
+			func(t *testing.T) {
+				assertTableExists(t, false, db, "cclf_attribution_deltas")
+				assertTableExists(t, false, db, "cclf_attribution_delta_beneficiaries")
+				migrator.runMigration(t, 26)
+				assertTableExists(t, true, db, "cclf_attribution_deltas")
+				assertTableExists(t, true, db, "cclf_attribution_delta_beneficiaries")
+			},
+		},
+		{
+			"Creating cclf_beneficiary_xrefs table",
+			func(t *testing.T) {
+				assertTableExists(t, false, db, "cclf_beneficiary_xrefs")
+				migrator.runMigration(t, 27)
+				assertTableExists(t, true, db, "cclf_beneficiary_xrefs")
+			},
+		},
+		{
+			"Adding request_method to jobs table",
+			func(t *testing.T) {
+				assertColumnExists(t, false, db, "jobs", "request_method")
+				migrator.runMigration(t, 28)
+				assertColumnExists(t, true, db, "jobs", "request_method")
+			},
+		},
+		{
+			"Adding retry_of to jobs table",
+			func(t *testing.T) {
+				assertColumnExists(t, false, db, "jobs", "retry_of")
+				migrator.runMigration(t, 29)
+				assertColumnExists(t, true, db, "jobs", "retry_of")
+				assertIndexExists(t, true, db, "jobs", "idx_jobs_retry_of_active")
+			},
+		},
+		{
+			"Adding (xref_indicator, current_num) index to cclf_beneficiary_xrefs table",
+			func(t *testing.T) {
+				assertIndexExists(t, true, db, "cclf_beneficiary_xrefs", "idx_cclf_beneficiary_xrefs_current_num")
+				migrator.runMigration(t, 30)
+				assertIndexExists(t, true, db, "cclf_beneficiary_xrefs", "idx_cclf_beneficiary_xrefs_indicator_current_num")
+				assertIndexExists(t, false, db, "cclf_beneficiary_xrefs", "idx_cclf_beneficiary_xrefs_current_num")
+			},
+		},
 		// **********************************************************
 		// * down migrations tests begin here with test number - 1  *
 		// **********************************************************
+		{
+			"Removing (xref_indicator, current_num) index from cclf_beneficiary_xrefs table",
+			func(t *testing.T) {
+				migrator.runMigration(t, 29)
+				assertIndexExists(t, false, db, "cclf_beneficiary_xrefs", "idx_cclf_beneficiary_xrefs_indicator_current_num")
+				assertIndexExists(t, true, db, "cclf_beneficiary_xrefs", "idx_cclf_beneficiary_xrefs_current_num")
+			},
+		},
+		{
+			"Removing retry_of from jobs table",
+			func(t *testing.T) {
+				migrator.runMigration(t, 28)
+				assertIndexExists(t, false, db, "jobs", "idx_jobs_retry_of_active")
+				assertColumnExists(t, false, db, "jobs", "retry_of")
+			},
+		},
+		{
+			"Removing request_method from jobs table",
+			func(t *testing.T) {
+				migrator.runMigration(t, 27)
+				assertColumnExists(t, false, db, "jobs", "request_method")
+			},
+		},
+		{
+			"Dropping cclf_beneficiary_xrefs table",
+			func(t *testing.T) {
+				migrator.runMigration(t, 26)
+				assertTableExists(t, false, db, "cclf_beneficiary_xrefs")
+			},
+		},
+		{
+			"Dropping cclf_attribution_deltas and cclf_attribution_delta_beneficiaries tables",
+			func(t *testing.T) {
+				migrator.runMigration(t, 25)
+				assertTableExists(t, false, db, "cclf_attribution_delta_beneficiaries")
+				assertTableExists(t, false, db, "cclf_attribution_deltas")
+			},
+		},
+		{
+			"Dropping cclf_quarantined_beneficiaries table",
+			func(t *testing.T) {
+				migrator.runMigration(t, 24)
+				assertTableExists(t, false, db, "cclf_quarantined_beneficiaries")
+			},
+		},
+		{
+			"Dropping failed_queue_jobs table",
+			func(t *testing.T) {
+				migrator.runMigration(t, 23)
+				assertTableExists(t, false, db, "failed_queue_jobs")
+			},
+		},
+		{
+			"Dropping bfd_patient_ids table",
+			func(t *testing.T) {
+				migrator.runMigration(t, 22)
+				assertTableExists(t, false, db, "bfd_patient_ids")
+			},
+		},
+		{
+			"Dropping circuit_breakers table",
+			func(t *testing.T) {
+				migrator.runMigration(t, 21)
+				assertTableExists(t, false, db, "circuit_breakers")
+			},
+		},
+		{
+			"Removing beneficiary_count and error_count from job_keys table",
+			func(t *testing.T) {
+				migrator.runMigration(t, 20)
+				assertColumnExists(t, false, db, "job_keys", "beneficiary_count")
+				assertColumnExists(t, false, db, "job_keys", "error_count")
+			},
+		},
+		{
+			"Removing digest from job_keys table",
+			func(t *testing.T) {
+				migrator.runMigration(t, 19)
+				assertColumnExists(t, false, db, "job_keys", "digest")
+			},
+		},
+		{
+			"Removing resource_count and file sizes from job_keys table",
+			func(t *testing.T) {
+				migrator.runMigration(t, 18)
+				assertColumnExists(t, false, db, "job_keys", "resource_count")
+				assertColumnExists(t, false, db, "job_keys", "uncompressed_size")
+				assertColumnExists(t, false, db, "job_keys", "compressed_size")
+			},
+		},
 		{
 			"Removing completed_job_count from jobs table",
 			func(t *testing.T) {
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -154,6 +155,31 @@ func (handler *S3FileHandler) OpenFileBytes(filePath string) ([]byte, error) {
 	return byte_arr, err
 }
 
+// OpenFileStream returns the contents of the file as a stream rather than downloading the whole file into memory.
+// The caller is responsible for closing the stream.
+func (handler *S3FileHandler) OpenFileStream(filePath string) (io.ReadCloser, error) {
+	handler.Infof("Opening file %s\n", filePath)
+	bucket, file := ParseS3Uri(filePath)
+
+	sess, err := handler.createSession()
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := s3.New(sess).GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(file),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if output.ContentLength != nil {
+		handler.Logger.WithField("file_size_bytes", *output.ContentLength).Infof("file opened: size=%d\n", *output.ContentLength)
+	}
+	return output.Body, nil
+}
+
 func (handler *S3FileHandler) CleanupOptOutFiles(suppresslist []*OptOutFilenameMetadata) error {
 	errCount := 0
 	for _, suppressionFile := range suppresslist {
@@ -0,0 +1,99 @@
+package optout
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pborman/uuid"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/CMSgov/bcda-app/conf"
+)
+
+type S3FileHandlerTestSuite struct {
+	suite.Suite
+	handler *S3FileHandler
+	bucket  string
+}
+
+func TestS3FileHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(S3FileHandlerTestSuite))
+}
+
+func (s *S3FileHandlerTestSuite) SetupTest() {
+	s.handler = &S3FileHandler{
+		Logger:   log.StandardLogger(),
+		Endpoint: conf.GetEnv("BFD_S3_ENDPOINT"),
+	}
+	s.bucket = uuid.NewUUID().String()
+
+	svc := s.s3Client()
+	_, err := svc.CreateBucket(&s3.CreateBucketInput{Bucket: aws.String(s.bucket)})
+	if err != nil {
+		s.FailNow("Failed to create bucket", err.Error())
+	}
+}
+
+func (s *S3FileHandlerTestSuite) TearDownTest() {
+	svc := s.s3Client()
+	objects, err := svc.ListObjectsV2(&s3.ListObjectsV2Input{Bucket: aws.String(s.bucket)})
+	if err != nil {
+		s.FailNow("Failed to list objects", err.Error())
+	}
+	for _, obj := range objects.Contents {
+		_, err = svc.DeleteObject(&s3.DeleteObjectInput{Bucket: aws.String(s.bucket), Key: obj.Key})
+		assert.NoError(s.T(), err)
+	}
+	_, err = svc.DeleteBucket(&s3.DeleteBucketInput{Bucket: aws.String(s.bucket)})
+	assert.NoError(s.T(), err)
+}
+
+func (s *S3FileHandlerTestSuite) s3Client() *s3.S3 {
+	sess, err := s.handler.createSession()
+	if err != nil {
+		s.FailNow("Failed to create new session for S3", err.Error())
+	}
+	return s3.New(sess)
+}
+
+func (s *S3FileHandlerTestSuite) putObject(key, body string) {
+	_, err := s.s3Client().PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+		Body:   strings.NewReader(body),
+	})
+	if err != nil {
+		s.FailNow("Failed to upload file", err.Error())
+	}
+}
+
+func (s *S3FileHandlerTestSuite) TestOpenFileStream() {
+	assert := assert.New(s.T())
+	body := "MBI,ACO\n1SA0A00AA00,A0001\n1SA0A00AA01,A0001\n"
+	s.putObject("attribution/file.csv", body)
+
+	stream, err := s.handler.OpenFileStream(fmt.Sprintf("s3://%s/attribution/file.csv", s.bucket))
+	s.Require().NoError(err)
+	defer stream.Close()
+
+	data, err := io.ReadAll(stream)
+	assert.NoError(err)
+	assert.Equal(body, string(data))
+
+	// The stream returns the same contents as the downloaded file
+	downloaded, err := s.handler.OpenFileBytes(fmt.Sprintf("s3://%s/attribution/file.csv", s.bucket))
+	assert.NoError(err)
+	assert.Equal(downloaded, data)
+}
+
+func (s *S3FileHandlerTestSuite) TestOpenFileStream_FileNotFound() {
+	stream, err := s.handler.OpenFileStream(fmt.Sprintf("s3://%s/attribution/missing.csv", s.bucket))
+	assert.Error(s.T(), err)
+	assert.Nil(s.T(), stream)
+}